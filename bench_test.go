@@ -0,0 +1,79 @@
+package urlcodec
+
+import (
+	"net/url"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// benchmarkValues returns URL values with n keys spread over nested
+// objects and slices, e.g. "users[12].address.city".
+func benchmarkValues(n int) url.Values {
+	values := make(url.Values, n)
+	fields := []string{"name", "email", "address.city", "address.zip"}
+	for i := 0; len(values) < n; i++ {
+		for _, field := range fields {
+			key := "users[" + strconv.Itoa(i%maxSliceSize) + "].group" +
+				strconv.Itoa(i/maxSliceSize) + "." + field
+			values.Set(key, "value"+strconv.Itoa(i))
+			if len(values) == n {
+				break
+			}
+		}
+	}
+	return values
+}
+
+// BenchmarkDecode_10kKeys measures decoding a query with 10,000 keys.
+func BenchmarkDecode_10kKeys(b *testing.B) {
+	values := benchmarkValues(10000)
+	encoder := NewURLEncoder()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := encoder.Decode(values); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeString_10kKeys measures parsing and decoding a raw query
+// with 10,000 keys.
+func BenchmarkDecodeString_10kKeys(b *testing.B) {
+	query := benchmarkValues(10000).Encode()
+	encoder := NewURLEncoder()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := encoder.DecodeString(query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncode_10kKeys measures encoding data with 10,000 keys.
+func BenchmarkEncode_10kKeys(b *testing.B) {
+	data, err := NewURLEncoder().Decode(benchmarkValues(10000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	encoder := NewURLEncoder()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := encoder.Encode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncodeParallel_10kKeys measures encoding a top-level map with
+// 10,000 entries on several goroutines.
+func BenchmarkEncodeParallel_10kKeys(b *testing.B) {
+	data := parallelData(10000)
+	encoder := NewURLEncoder(WithParallelism(runtime.GOMAXPROCS(0)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := encoder.Encode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package urlcodec
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// EncodeToString encodes data like Encode and returns it as a query string
+// whose keys are sorted with numerically-aware ordering, e.g. "list[2]"
+// before "list[10]". Values of a key keep their order. The output is stable
+// for equal data, which makes it suitable for caching and signatures. Map
+// entries are encoded in key order as with WithSortedMapKeys, so that keys
+// written by several entries also keep a stable value order.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Query string
+//   - error: Error
+func (e URLEncoder) EncodeToString(data any) (string, error) {
+	e.sortMapKeys = true
+	e.raw = &rawValues{}
+	values, err := e.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	return e.query.encodeSorted(values, e.raw), nil
+}
+
+// Canonicalize returns URL values as a canonical query string for
+// signatures and cache keys. Keys are sorted like in EncodeToString and the
+// values of a key keep their order. Keys and values are percent-encoded as
+// in RFC 3986: only unreserved characters (letters, digits, "-", ".", "_"
+// and "~") are written as is, other bytes are written as "%XX" with
+// uppercase hex digits and spaces as "%20".
+//
+// Parameters:
+//   - values: URL values
+//
+// Returns:
+//   - string: Canonical query string
+func Canonicalize(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, compareKeys)
+	var b strings.Builder
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			writeEscaped(&b, key)
+			b.WriteByte('=')
+			writeEscaped(&b, value)
+		}
+	}
+	return b.String()
+}
+
+// writeEscaped writes s percent-encoded as in RFC 3986, escaping all bytes
+// except unreserved characters.
+func writeEscaped(b *strings.Builder, s string) {
+	writeEscapedKeep(b, s, "")
+}
+
+// writeEscapedKeep writes s percent-encoded like writeEscaped, except that
+// the bytes in keep are written as they are.
+func writeEscapedKeep(b *strings.Builder, s string, keep string) {
+	const hexDigits = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) || strings.IndexByte(keep, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0f])
+	}
+}
+
+// isUnreserved reports whether c is an RFC 3986 unreserved character.
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || isDigit(c) ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// compareKeys compares two keys so that runs of digits compare by their
+// numeric value and all other bytes compare lexically, e.g. "a[2]" sorts
+// before "a[10]".
+func compareKeys(a string, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			endA, endB := digitRunEnd(a, i), digitRunEnd(b, j)
+			if c := compareDigits(a[i:endA], b[j:endB]); c != 0 {
+				return c
+			}
+			i, j = endA, endB
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// digitRunEnd returns the index after the run of digits starting at i.
+func digitRunEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// compareDigits compares two runs of digits by their numeric value. Runs
+// with equal values but different leading zeros compare lexically.
+func compareDigits(a string, b string) int {
+	if a[0] != '0' && b[0] != '0' {
+		// Runs without leading zeros compare by length and then lexically.
+		if len(a) != len(b) {
+			return cmp.Compare(len(a), len(b))
+		}
+		return strings.Compare(a, b)
+	}
+	trimmedA := strings.TrimLeft(a, "0")
+	trimmedB := strings.TrimLeft(b, "0")
+	if len(trimmedA) != len(trimmedB) {
+		if len(trimmedA) < len(trimmedB) {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(trimmedA, trimmedB); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
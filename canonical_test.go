@@ -0,0 +1,80 @@
+package urlcodec
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+)
+
+// TestEncodeToString verifies that keys are sorted with numerically-aware
+// ordering and that the output is stable.
+func TestEncodeToString(t *testing.T) {
+	list := make([]int, 12)
+	for i := range list {
+		list[i] = i
+	}
+	data := map[string]any{
+		"b":    "x y",
+		"a":    map[string]any{"z": 1, "c": 2},
+		"list": list,
+	}
+	encoder := NewURLEncoder()
+	got, err := encoder.EncodeToString(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "a.c=2&a.z=1&b=x+y" +
+		"&list%5B0%5D=0&list%5B1%5D=1&list%5B2%5D=2&list%5B3%5D=3" +
+		"&list%5B4%5D=4&list%5B5%5D=5&list%5B6%5D=6&list%5B7%5D=7" +
+		"&list%5B8%5D=8&list%5B9%5D=9&list%5B10%5D=10&list%5B11%5D=11"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	for i := 0; i < 5; i++ {
+		again, err := encoder.EncodeToString(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if again != got {
+			t.Fatalf("expected stable output %q, got %q", got, again)
+		}
+	}
+}
+
+// TestCompareKeys verifies the numerically-aware key ordering.
+func TestCompareKeys(t *testing.T) {
+	keys := []string{"a[10]", "a[2].b", "a[2]", "b", "a[01]", "a[1]", "a"}
+	slices.SortFunc(keys, compareKeys)
+	expected := []string{"a", "a[01]", "a[1]", "a[2]", "a[2].b", "a[10]", "b"}
+	if !slices.Equal(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
+
+// TestCanonicalize verifies the ordering and escaping of canonical query
+// strings.
+func TestCanonicalize(t *testing.T) {
+	values := url.Values{
+		"b":        {"x y", "z"},
+		"a[10]":    {"~ok"},
+		"a[2]":     {"+&=/é"},
+		"key name": {""},
+	}
+	expected := "a%5B2%5D=%2B%26%3D%2F%C3%A9&a%5B10%5D=~ok" +
+		"&b=x%20y&b=z&key%20name="
+	if got := Canonicalize(values); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	parsed, err := url.ParseQuery("b=x+y&a%5b10%5d=%7Eok&b=z" +
+		"&key+name=&a[2]=%2b%26%3d%2f%c3%a9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := Canonicalize(parsed); got != expected {
+		t.Errorf("expected %q for parsed query, got %q", expected, got)
+	}
+	if got := Canonicalize(url.Values{}); got != "" {
+		t.Errorf("expected empty string, got %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package urlcodec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// defaultMaxInflated is the default maximum size in bytes of the query
+// string in a compressed value.
+const defaultMaxInflated = 1 << 20
+
+// EncodeCompressed encodes data like EncodeToString, compresses the query
+// string with gzip and returns it in unpadded base64url, so that large
+// payloads such as filters fit into a single parameter, e.g. "q=H4sI...".
+// Use DecodeCompressed with the same options to decode it.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Compressed value
+//   - error: Error
+func (e URLEncoder) EncodeCompressed(data any) (string, error) {
+	query, err := e.EncodeToString(data)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, query); err != nil {
+		return "", fmt.Errorf("cannot compress query: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("cannot compress query: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeCompressed decompresses a value written by EncodeCompressed and
+// decodes the query string in it like DecodeString. Query strings larger
+// than the limit set with WithMaxDecompressedSize, 1 MiB by default, are
+// rejected with an ErrMaxDecompressedSize without being decompressed
+// further, which guards against compression bombs.
+//
+// Parameters:
+//   - value: Compressed value
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error
+func (e URLEncoder) DecodeCompressed(value string) (map[string]any, error) {
+	compressed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed value: %w", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed value: %w", err)
+	}
+	limit := e.maxInflated
+	if limit <= 0 {
+		limit = defaultMaxInflated
+	}
+	query, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed value: %w", err)
+	}
+	if len(query) > limit {
+		return nil, fmt.Errorf("%w of %d", ErrMaxDecompressedSize, limit)
+	}
+	return e.DecodeString(string(query))
+}
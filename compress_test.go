@@ -0,0 +1,71 @@
+package urlcodec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCompressed verifies that compressed values round trip, are URL-safe
+// and are smaller than the query string for repetitive payloads.
+func TestCompressed(t *testing.T) {
+	var items []any
+	for range 50 {
+		items = append(items, map[string]any{
+			"field": "status", "op": "in", "value": "open closed",
+		})
+	}
+	data := map[string]any{"filter": map[string]any{"items": items}}
+	encoder := NewURLEncoder()
+	compressed, err := encoder.EncodeCompressed(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, err := encoder.EncodeToString(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(compressed) >= len(query)/4 {
+		t.Errorf("expected compressed value much shorter than %d, got %d",
+			len(query), len(compressed))
+	}
+	if strings.ContainsAny(compressed, "+/=") {
+		t.Errorf("expected URL-safe value, got %s", compressed)
+	}
+	decoded, err := encoder.DecodeCompressed(compressed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %v, got %v", data, decoded)
+	}
+}
+
+// TestCompressed_Invalid verifies that invalid values and values larger
+// than the decompressed size limit are rejected.
+func TestCompressed_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte("a=" + strings.Repeat("x", 1000)))
+	w.Close()
+	bomb := base64.RawURLEncoding.EncodeToString(buf.Bytes())
+
+	encoder := NewURLEncoder(WithMaxDecompressedSize(100))
+	if _, err := encoder.DecodeCompressed(bomb); !errors.Is(
+		err, ErrMaxDecompressedSize,
+	) {
+		t.Errorf("expected ErrMaxDecompressedSize, got %v", err)
+	}
+	if _, err := NewURLEncoder().DecodeCompressed(bomb); err != nil {
+		t.Errorf("unexpected error with default limit: %v", err)
+	}
+	for _, value := range []string{"!!", "aGVsbG8"} {
+		if _, err := encoder.DecodeCompressed(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package urlcodec
+
+import "strconv"
+
+// ConflictStrategy selects how Decode and DecodeInto resolve keys that
+// conflict, such as a key set twice or a key set both as a value and as an
+// object ("a=1&a.b=2"). Keys are decoded in sorted order, with slice indices
+// compared numerically, so "first" and "last" refer to that order. The
+// strategy does not apply with WithQSCompat, which follows qs.
+type ConflictStrategy int
+
+const (
+	// ErrorOnConflict returns an error for conflicting keys.
+	ErrorOnConflict ConflictStrategy = iota
+	// FirstWins keeps the value decoded first and drops conflicting keys.
+	FirstWins
+	// LastWins keeps the value decoded last, replacing earlier values.
+	LastWins
+	// DeepMerge keeps all values: values set twice are collected into a
+	// slice, a value set where an object is expected is kept under the
+	// empty key of the object, and a slice that meets an object becomes an
+	// object keyed by its indices.
+	DeepMerge
+)
+
+// mergeConflict resolves a value set at a key that already holds a value.
+func (e *URLEncoder) mergeConflict(existing any, value any) (any, error) {
+	switch e.conflicts {
+	case FirstWins:
+		return existing, nil
+	case LastWins:
+		return value, nil
+	case DeepMerge:
+		return deepMergeValue(existing, value), nil
+	}
+	return nil, ErrConflictingKey{}
+}
+
+// deepMergeValue merges a value into an existing value for DeepMerge.
+func deepMergeValue(existing any, value any) any {
+	switch ex := existing.(type) {
+	case nil:
+		return value
+	case map[string]any:
+		ex[""] = deepMergeValue(ex[""], value)
+		return ex
+	case *minSlice:
+		ex.set(ex.next(), value)
+		return ex
+	case []any:
+		return append(ex, value)
+	}
+	return []any{existing, value}
+}
+
+// resolveObject returns the object to descend into for a key whose
+// existing value is not an object. It returns a nil map if the key is
+// dropped, or err if conflicts are errors.
+func (e *URLEncoder) resolveObject(
+	existing any, err error,
+) (map[string]any, error) {
+	switch e.conflicts {
+	case FirstWins:
+		return nil, nil
+	case LastWins:
+		return make(map[string]any), nil
+	case DeepMerge:
+		return asObject(existing), nil
+	}
+	return nil, err
+}
+
+// objectForSlice returns the object that receives slice elements for a key
+// whose existing value is not a slice when conflicts are deep merged. It
+// returns false otherwise.
+func (e *URLEncoder) objectForSlice(
+	current map[string]any, sliceName string,
+) (map[string]any, bool) {
+	if e.conflicts != DeepMerge {
+		return nil, false
+	}
+	existing, ok := current[sliceName]
+	if !ok {
+		return nil, false
+	}
+	if _, isSlice := existing.(*minSlice); isSlice {
+		return nil, false
+	}
+	obj := asObject(existing)
+	current[sliceName] = obj
+	return obj, true
+}
+
+// asObject converts a value to an object: slices are keyed by their
+// indices and other values are kept under the empty key.
+func asObject(value any) map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return v
+	case *minSlice:
+		m := make(map[string]any, len(v.elements))
+		for index, elem := range v.elements {
+			m[strconv.Itoa(index)] = elem
+		}
+		return m
+	}
+	return map[string]any{"": value}
+}
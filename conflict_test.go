@@ -0,0 +1,90 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestWithConflictStrategy verifies how each strategy resolves conflicting
+// keys.
+func TestWithConflictStrategy(t *testing.T) {
+	values := url.Values{
+		"a":     {"1"},
+		"a.b":   {"2"},
+		"c[0]":  {"x"},
+		"c[]":   {"y"},
+		"d":     {"3"},
+		"d[1]":  {"4"},
+		"e.f":   {"5"},
+		"e[0]":  {"6"},
+		"plain": {"ok"},
+	}
+	tests := []struct {
+		name     string
+		strategy ConflictStrategy
+		expected map[string]any
+	}{
+		{
+			name:     "first wins",
+			strategy: FirstWins,
+			expected: map[string]any{
+				"a":     "1",
+				"c":     []any{"x"},
+				"d":     "3",
+				"e":     map[string]any{"f": "5"},
+				"plain": "ok",
+			},
+		},
+		{
+			name:     "last wins",
+			strategy: LastWins,
+			expected: map[string]any{
+				"a":     map[string]any{"b": "2"},
+				"c":     []any{"y"},
+				"d":     []any{"4"},
+				"e":     []any{"6"},
+				"plain": "ok",
+			},
+		},
+		{
+			name:     "deep merge",
+			strategy: DeepMerge,
+			expected: map[string]any{
+				"a":     map[string]any{"": "1", "b": "2"},
+				"c":     []any{[]any{"x", "y"}},
+				"d":     map[string]any{"": "3", "1": "4"},
+				"e":     map[string]any{"f": "5", "0": "6"},
+				"plain": "ok",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewURLEncoder(WithConflictStrategy(tt.strategy))
+			for i := 0; i < 5; i++ {
+				got, err := encoder.Decode(values)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.expected) {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+// TestErrorOnConflict verifies that conflicting keys are errors by default.
+func TestErrorOnConflict(t *testing.T) {
+	for _, values := range []url.Values{
+		{"a": {"1"}, "a.b": {"2"}},
+		{"a.b": {"1"}, "a": {"2"}},
+		{"c[0]": {"x"}, "c[]": {"y"}},
+		{"d": {"3"}, "d[1]": {"4"}},
+	} {
+		if _, err := NewURLEncoder().Decode(values); err == nil {
+			t.Errorf("expected error for %v, got nil", values)
+		}
+	}
+}
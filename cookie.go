@@ -0,0 +1,92 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxCookieSize is the size of a cookie value that browsers are required
+// to store by RFC 6265.
+const maxCookieSize = 4096
+
+// cookieSafe holds the cookie-octets of RFC 6265 other than unreserved
+// characters that are written as they are in cookie values. "&", "=", "%"
+// and "+" are cookie-octets too but are escaped as they delimit the pairs.
+const cookieSafe = "!#$'()*/:<>?@[]^`{|}"
+
+// EncodeCookieValue encodes data as a cookie value, e.g. "a.b=1&c[0]=x".
+// Keys are sorted like in EncodeToString and every byte that is not a
+// cookie-octet, such as spaces, quotes, commas, semicolons and
+// backslashes, is percent-encoded, so that the value can be set without
+// quoting.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Cookie value
+//   - error: Error if the value is longer than 4096 bytes
+func (e URLEncoder) EncodeCookieValue(data any) (string, error) {
+	e.sortMapKeys = true
+	values, err := e.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	value := joinPairs(values, cookieSafe)
+	if err := checkCookieSize(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// DecodeCookieValue decodes a cookie value written by EncodeCookieValue
+// like Decode. A value enclosed in double quotes is accepted. A "+" is kept
+// as it is rather than decoded as a space.
+//
+// Parameters:
+//   - s: Cookie value
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error if the value is too long or has invalid characters
+func (e URLEncoder) DecodeCookieValue(s string) (map[string]any, error) {
+	if err := checkCookieSize(s); err != nil {
+		return nil, err
+	}
+	raw := s
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		raw = raw[1 : len(raw)-1]
+	}
+	for i := 0; i < len(raw); i++ {
+		if !isCookieOctet(raw[i]) {
+			return nil, fmt.Errorf(
+				"invalid character %q in cookie value %q", raw[i], s,
+			)
+		}
+	}
+	values, err := splitPairs(raw, "&", url.PathUnescape)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cookie value %q: %w", s, err)
+	}
+	return e.Decode(values)
+}
+
+// checkCookieSize returns an error if a cookie value is longer than
+// maxCookieSize.
+func checkCookieSize(value string) error {
+	if len(value) > maxCookieSize {
+		return fmt.Errorf(
+			"%w of %d bytes with %d bytes",
+			ErrCookieTooLarge, maxCookieSize, len(value),
+		)
+	}
+	return nil
+}
+
+// isCookieOctet reports whether c is a cookie-octet of RFC 6265: a
+// printable US-ASCII character other than a double quote, comma,
+// semicolon or backslash.
+func isCookieOctet(c byte) bool {
+	return c > ' ' && c < 0x7f && strings.IndexByte("\",;\\", c) < 0
+}
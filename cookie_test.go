@@ -0,0 +1,72 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCookieValue verifies that cookie values round trip through an HTTP
+// cookie and escape characters that are not cookie-octets.
+func TestCookieValue(t *testing.T) {
+	data := map[string]any{
+		"cart": map[string]any{"note": `a "b", c; d\e`},
+		"ids":  []any{"1+1", "x=y&z"},
+	}
+	encoder := NewURLEncoder()
+	encoded, err := encoder.EncodeCookieValue(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "cart.note=a%20%22b%22%2C%20c%3B%20d%5Ce" +
+		"&ids[0]=1%2B1&ids[1]=x%3Dy%26z"
+	if encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	cookie := &http.Cookie{Name: "state", Value: encoded}
+	parsed, err := http.ParseCookie(cookie.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed[0].Value != encoded {
+		t.Errorf("expected cookie value %s, got %s", encoded, parsed[0].Value)
+	}
+	decoded, err := encoder.DecodeCookieValue(parsed[0].Value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %v, got %v", data, decoded)
+	}
+
+	decoded, err = encoder.DecodeCookieValue(`"a=1+1"`)
+	if err != nil || decoded["a"] != "1+1" {
+		t.Errorf("expected quoted value with a=1+1, got %v, %v", decoded, err)
+	}
+}
+
+// TestCookieValue_Invalid verifies that oversized values and characters
+// that are not cookie-octets are rejected.
+func TestCookieValue_Invalid(t *testing.T) {
+	encoder := NewURLEncoder()
+	large := map[string]any{"a": strings.Repeat("x", maxCookieSize)}
+	if _, err := encoder.EncodeCookieValue(large); !errors.Is(
+		err, ErrCookieTooLarge,
+	) {
+		t.Errorf("expected ErrCookieTooLarge, got %v", err)
+	}
+	long := "a=" + strings.Repeat("x", maxCookieSize)
+	if _, err := encoder.DecodeCookieValue(long); !errors.Is(
+		err, ErrCookieTooLarge,
+	) {
+		t.Errorf("expected ErrCookieTooLarge, got %v", err)
+	}
+	for _, value := range []string{"a=1;b=2", "a=x y", "a=%zz"} {
+		if _, err := encoder.DecodeCookieValue(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package urlcodec
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+// pageCursor is a cursor of a listing sorted by creation time.
+type pageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int       `json:"id"`
+	Offset    int       `json:"offset"`
+}
+
+// TestCursorCodec verifies that signed and unsigned cursors round trip and
+// that empty tokens leave the target unchanged.
+func TestCursorCodec(t *testing.T) {
+	cursor := pageCursor{
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		ID:        42,
+		Offset:    20,
+	}
+	for name, codec := range map[string]*CursorCodec{
+		"unsigned": NewCursorCodec(nil),
+		"signed":   NewCursorCodec([]byte("secret")),
+	} {
+		token, err := codec.Encode(cursor)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		var got pageCursor
+		if err := codec.Decode(token, &got); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID ||
+			got.Offset != cursor.Offset {
+			t.Errorf("%s: expected %+v, got %+v", name, cursor, got)
+		}
+	}
+
+	got := pageCursor{ID: 7}
+	if err := NewCursorCodec(nil).Decode("", &got); err != nil || got.ID != 7 {
+		t.Errorf("expected unchanged cursor, got %+v, %v", got, err)
+	}
+}
+
+// TestCursorCodec_Invalid verifies that forged, malformed and mistyped
+// cursors are rejected with ErrInvalidCursor.
+func TestCursorCodec_Invalid(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	forged, err := NewCursorCodec(nil).Encode(pageCursor{ID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mistyped := base64.RawURLEncoding.EncodeToString([]byte("id=x"))
+	tests := map[string]struct {
+		codec *CursorCodec
+		token string
+	}{
+		"forged":    {codec, forged},
+		"malformed": {codec, "%%"},
+		"mistyped":  {NewCursorCodec(nil), mistyped},
+	}
+	for name, tt := range tests {
+		var got pageCursor
+		if err := tt.codec.Decode(tt.token, &got); !errors.Is(
+			err, ErrInvalidCursor,
+		) {
+			t.Errorf("%s: expected ErrInvalidCursor, got %v", name, err)
+		}
+	}
+	var got pageCursor
+	if err := codec.Decode(forged, &got); !errors.Is(
+		err, ErrInvalidSignature,
+	) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
@@ -0,0 +1,479 @@
+package urlcodec
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DecodeInto decodes URL values into the value pointed to by target. It
+// supports the same recursive URL syntax as Decode and converts the decoded
+// strings to the types of the target's fields:
+// someKey=value
+// someStruct.field=value
+// someSlice[0]=value
+// someMap.key=value
+//
+// Struct fields are matched by their tags ("json" unless configured with
+// WithTagName) and embedded fields are inlined. Keys that do not match any
+// field are ignored. Fields with the "required" tag option must be present
+// and not empty; all missing fields are reported together as an
+// ErrMissingFields.
+//
+// Parameters:
+//   - values: URL values
+//   - target: Non-nil pointer to the value to populate
+//
+// Returns:
+//   - error: Error
+func (e URLEncoder) DecodeInto(values url.Values, target any) error {
+	return e.decodeInto(values, nil, target)
+}
+
+// decodeInto decodes URL values and uploaded files into the value pointed
+// to by target.
+func (e *URLEncoder) decodeInto(
+	values url.Values, files map[string][]*multipart.FileHeader, target any,
+) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	data, err := e.decodeURL(values, files)
+	if err != nil {
+		return err
+	}
+	e.missing = &[]string{}
+	err = e.assignValue(rv.Elem(), data, "")
+	if len(*e.missing) > 0 {
+		return errors.Join(ErrMissingFields{Keys: *e.missing}, err)
+	}
+	return err
+}
+
+// assignValue assigns decoded data to a value.
+func (e *URLEncoder) assignValue(
+	dst reflect.Value, src any, path string,
+) error {
+	// Pointers are skipped so that the hook sees the element type.
+	if dst.Kind() != reflect.Ptr {
+		var done bool
+		var err error
+		if src, done, err = e.applyDecodeHook(dst, src, path); done {
+			return err
+		}
+	}
+	if src == nil {
+		// Null values clear the value.
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() != reflect.Interface {
+		// Typed values from type hints are assigned like their strings.
+		switch v := src.(type) {
+		case bool, int:
+			src = fmt.Sprint(v)
+		case float64:
+			src = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	}
+	if ok, err := e.assignKnownType(dst, src, path); ok {
+		return err
+	}
+	if ok, err := assignFile(dst, src, path); ok {
+		return err
+	}
+	if ok, err := e.assignUnmarshaler(dst, src, path); ok {
+		return err
+	}
+	switch dst.Kind() {
+	case reflect.Ptr:
+		return e.assignPointer(dst, src, path)
+	case reflect.Interface:
+		return e.assignInterface(dst, src, path)
+	case reflect.Struct:
+		return e.assignStruct(dst, src, path)
+	case reflect.Map:
+		return e.assignMap(dst, src, path)
+	case reflect.Slice, reflect.Array:
+		if isBytesType(dst.Type()) {
+			return e.assignBytes(dst, src, path, e.bytesFormat)
+		}
+		if dst.Kind() == reflect.Array {
+			return e.assignArray(dst, src, path)
+		}
+		return e.assignSlice(dst, src, path)
+	default:
+		return e.assignScalar(dst, src, path)
+	}
+}
+
+// applyDecodeHook applies the decode hook to a decoded string. It returns
+// the string to assign, or true if the hook assigned the value itself or
+// failed.
+func (e *URLEncoder) applyDecodeHook(
+	dst reflect.Value, src any, path string,
+) (any, bool, error) {
+	s, isString := src.(string)
+	if !isString || e.decodeHook == nil {
+		return src, false, nil
+	}
+	converted, err := e.decodeHook(s, dst.Type())
+	if err != nil {
+		return nil, true, fmt.Errorf("cannot decode %q: %w", path, err)
+	}
+	switch v := converted.(type) {
+	case nil:
+		return src, false, nil
+	case string:
+		return v, false, nil
+	}
+	cv := reflect.ValueOf(converted)
+	if !cv.Type().AssignableTo(dst.Type()) {
+		return nil, true, fmt.Errorf(
+			"decode hook returned %T for %s at %q",
+			converted, dst.Type(), path,
+		)
+	}
+	dst.Set(cv)
+	return nil, true, nil
+}
+
+// assignPointer allocates a pointer if needed and assigns to its element.
+func (e *URLEncoder) assignPointer(
+	dst reflect.Value, src any, path string,
+) error {
+	if dst.IsNil() {
+		dst.Set(reflect.New(dst.Type().Elem()))
+	}
+	return e.assignValue(dst.Elem(), src, path)
+}
+
+// assignInterface assigns decoded data as is to an interface.
+func (e *URLEncoder) assignInterface(
+	dst reflect.Value, src any, path string,
+) error {
+	sv := reflect.ValueOf(src)
+	if !sv.Type().AssignableTo(dst.Type()) {
+		return fmt.Errorf(
+			"cannot assign %T to %s at %q", src, dst.Type(), path,
+		)
+	}
+	dst.Set(sv)
+	return nil
+}
+
+// assignStruct assigns a decoded map to a struct.
+func (e *URLEncoder) assignStruct(
+	dst reflect.Value, src any, path string,
+) error {
+	m, ok := src.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected object at %q, got %T", path, src)
+	}
+	errs := errorCollector{collect: e.collectErrors}
+	if e.strictKeys {
+		err := e.checkUnknownKeys(dst.Type(), m, path)
+		if errs.add(err) {
+			return err
+		}
+	}
+	errs.add(e.assignFields(dst, m, path))
+	return errs.err()
+}
+
+// assignFields assigns the values of a decoded map to the fields of a
+// struct.
+func (e *URLEncoder) assignFields(
+	dst reflect.Value, m map[string]any, path string,
+) error {
+	errs := errorCollector{collect: e.collectErrors}
+	for _, plan := range e.structFields(dst.Type()) {
+		err := e.assignStructField(dst, m, path, plan)
+		if errs.add(err) {
+			return err
+		}
+	}
+	return errs.err()
+}
+
+// assignStructField assigns a value from a decoded map to a struct field.
+func (e *URLEncoder) assignStructField(
+	dst reflect.Value, m map[string]any, path string, plan fieldPlan,
+) error {
+	field := dst.Field(plan.index)
+	fieldType, spec, ok := plan.field, plan.spec, plan.ok
+	if spec.skip {
+		return nil
+	}
+
+	if fieldType.Anonymous {
+		if field.Kind() == reflect.Ptr {
+			if !field.CanSet() {
+				return nil
+			}
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Struct {
+			return e.assignFields(field, m, path)
+		}
+	}
+	if !fieldType.IsExported() {
+		return nil
+	}
+
+	if !ok {
+		return fmt.Errorf(
+			"cannot decode field %q because it has no %s tag",
+			joinPath(path, fieldType.Name), e.tagName,
+		)
+	}
+	fieldPath := joinPath(path, spec.name)
+	raw, ok := m[spec.name]
+	if spec.required && (!ok || raw == "") {
+		if e.missing == nil {
+			return ErrMissingFields{Keys: []string{fieldPath}}
+		}
+		// Missing fields are reported together once decoding is done.
+		*e.missing = append(*e.missing, fieldPath)
+		return nil
+	}
+	if !ok && spec.defaultValue != nil {
+		raw, ok = e.defaultValue(field.Type(), *spec.defaultValue), true
+	}
+	if !ok {
+		return nil
+	}
+	if isBytesType(field.Type()) {
+		// The decode hook and unmarshalers take precedence over the bytes
+		// format of the tag, like they do in assignValue.
+		raw, done, err := e.applyDecodeHook(field, raw, fieldPath)
+		if done {
+			return err
+		}
+		if ok, err := e.assignUnmarshaler(field, raw, fieldPath); ok {
+			return err
+		}
+		return e.assignBytes(field, raw, fieldPath, spec.bytesFormat)
+	}
+	return e.withTimeEpoch(spec.timeEpoch).assignValue(field, raw, fieldPath)
+}
+
+// checkUnknownKeys returns an error listing the keys of a decoded map that
+// do not match any field of a struct type.
+func (e *URLEncoder) checkUnknownKeys(
+	t reflect.Type, m map[string]any, path string,
+) error {
+	known := make(map[string]bool)
+	e.collectFieldNames(t, known)
+	var unknown []string
+	for key := range m {
+		if !known[key] {
+			unknown = append(unknown, strconv.Quote(joinPath(path, key)))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+}
+
+// collectFieldNames adds the names of the fields of a struct type,
+// including the fields of embedded structs, to names.
+func (e *URLEncoder) collectFieldNames(
+	t reflect.Type, names map[string]bool,
+) {
+	for _, plan := range e.structFields(t) {
+		sf, spec, ok := plan.field, plan.spec, plan.ok
+		if spec.skip {
+			continue
+		}
+		if sf.Anonymous {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				e.collectFieldNames(ft, names)
+				continue
+			}
+		}
+		if ok && sf.IsExported() {
+			names[spec.name] = true
+		}
+	}
+}
+
+// defaultValue returns the decoded form of a default tag value. Defaults of
+// slices and arrays hold their elements separated by "|".
+func (e *URLEncoder) defaultValue(t reflect.Type, value string) any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) &&
+		!isBytesType(t) {
+		return stringsToAny(strings.Split(value, "|"))
+	}
+	return value
+}
+
+// assignMap assigns a decoded map to a map with string keys.
+func (e *URLEncoder) assignMap(dst reflect.Value, src any, path string) error {
+	if !canDecodeMapKey(dst.Type().Key()) {
+		return fmt.Errorf(
+			"map keys must be strings or integers or implement "+
+				"encoding.TextUnmarshaler: %w",
+			ErrUnsupportedType{Type: dst.Type(), Path: path},
+		)
+	}
+	m, ok := src.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected object at %q, got %T", path, src)
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+	}
+	elemType := dst.Type().Elem()
+	for key, raw := range m {
+		k, err := decodeMapKey(dst.Type().Key(), key)
+		if err != nil {
+			return fmt.Errorf("invalid key at %q: %w", path, err)
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := e.assignValue(elem, raw, joinPath(path, key)); err != nil {
+			return err
+		}
+		dst.SetMapIndex(k, elem)
+	}
+	return nil
+}
+
+// assignSlice assigns a decoded slice to a slice.
+func (e *URLEncoder) assignSlice(
+	dst reflect.Value, src any, path string,
+) error {
+	items, ok := src.([]any)
+	if s, isString := src.(string); isString {
+		items, ok = e.scalarToSlice(s)
+	}
+	if !ok {
+		return fmt.Errorf("expected slice at %q, got %T", path, src)
+	}
+	slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+	errs := errorCollector{collect: e.collectErrors}
+	for i, raw := range items {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		err := e.assignValue(slice.Index(i), raw, elemPath)
+		if errs.add(err) {
+			return err
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	dst.Set(slice)
+	return nil
+}
+
+// scalarToSlice converts a decoded string to slice elements if the slice
+// style allows slices to be written as a single value. It returns false
+// otherwise.
+func (e *URLEncoder) scalarToSlice(s string) ([]any, bool) {
+	if sep, ok := e.sliceDelimiter(); ok {
+		return stringsToAny(splitDelimited(s, sep)), true
+	}
+	if e.collectRepeatedKeys() {
+		// A repeated key with a single value decodes to a scalar.
+		return []any{s}, true
+	}
+	return nil, false
+}
+
+// assignArray assigns a decoded slice to a fixed-size array. Elements
+// beyond the decoded slice are left unchanged.
+func (e *URLEncoder) assignArray(
+	dst reflect.Value, src any, path string,
+) error {
+	items, ok := src.([]any)
+	if s, isString := src.(string); isString {
+		items, ok = e.scalarToSlice(s)
+	}
+	if !ok {
+		return fmt.Errorf("expected slice at %q, got %T", path, src)
+	}
+	if len(items) > dst.Len() {
+		return fmt.Errorf(
+			"too many elements at %q: got %d, array length is %d",
+			path, len(items), dst.Len(),
+		)
+	}
+	errs := errorCollector{collect: e.collectErrors}
+	for i, raw := range items {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		err := e.assignValue(dst.Index(i), raw, elemPath)
+		if errs.add(err) {
+			return err
+		}
+	}
+	return errs.err()
+}
+
+// assignScalar parses a decoded string into a scalar value.
+func (e *URLEncoder) assignScalar(
+	dst reflect.Value, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer at %q: %q", path, s)
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer at %q: %q", path, s)
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float at %q: %q", path, s)
+		}
+		dst.SetFloat(f)
+	case reflect.Bool:
+		b, ok := e.parseBool(s)
+		if !ok {
+			return fmt.Errorf("invalid bool at %q: %q", path, s)
+		}
+		dst.SetBool(b)
+	default:
+		return ErrUnsupportedType{Type: dst.Type(), Path: path}
+	}
+	return nil
+}
+
+// joinPath joins a parent path and a key with a dot.
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
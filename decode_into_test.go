@@ -0,0 +1,375 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestDecodeInto_Struct verifies that values are converted to the types of
+// nested struct fields.
+func TestDecodeInto_Struct(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type Base struct {
+		ID uint64 `json:"id"`
+	}
+	type User struct {
+		Base
+		Name    string            `json:"name"`
+		Age     int               `json:"age"`
+		Score   float64           `json:"score"`
+		Active  bool              `json:"active"`
+		Address *Address          `json:"address"`
+		Tags    []string          `json:"tags"`
+		Meta    map[string]string `json:"meta"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("id", "7")
+	values.Set("name", "Ada")
+	values.Set("age", "36")
+	values.Set("score", "9.5")
+	values.Set("active", "true")
+	values.Set("address.street", "Main St")
+	values.Set("tags[0]", "x")
+	values.Set("meta.k", "v")
+	values.Set("unknown", "ignored")
+
+	var user User
+	if err := encoder.DecodeInto(values, &user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != 7 || user.Name != "Ada" || user.Age != 36 {
+		t.Errorf("unexpected scalar fields: %+v", user)
+	}
+	if user.Score != 9.5 || !user.Active {
+		t.Errorf("unexpected score/active: %+v", user)
+	}
+	if user.Address == nil || user.Address.Street != "Main St" {
+		t.Errorf("expected address.street=Main St, got %+v", user.Address)
+	}
+	if len(user.Tags) != 1 || user.Tags[0] != "x" {
+		t.Errorf("expected tags=[x], got %v", user.Tags)
+	}
+	if user.Meta["k"] != "v" {
+		t.Errorf("expected meta.k=v, got %v", user.Meta)
+	}
+}
+
+// TestDecodeInto_RoundTrip verifies that an encoded struct decodes back into
+// an equal struct.
+func TestDecodeInto_RoundTrip(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	}
+	type Order struct {
+		Item  Item    `json:"item"`
+		Price float32 `json:"price"`
+	}
+	encoder := NewURLEncoder()
+	original := Order{Item: Item{SKU: "a", Qty: 2}, Price: 1.25}
+	values, err := encoder.Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error during encode: %v", err)
+	}
+	var decoded Order
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error during decode: %v", err)
+	}
+	if decoded != original {
+		t.Errorf("expected %+v, got %+v", original, decoded)
+	}
+}
+
+// TestDecodeInto_InvalidValue verifies that a value that cannot be converted
+// to the field type returns an error.
+func TestDecodeInto_InvalidValue(t *testing.T) {
+	type Target struct {
+		Age int `json:"age"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("age", "old")
+	var target Target
+	if err := encoder.DecodeInto(values, &target); err == nil {
+		t.Fatal("expected error for invalid integer, got nil")
+	}
+}
+
+// TestDecodeInto_NonPointer verifies that a non-pointer target is rejected.
+func TestDecodeInto_NonPointer(t *testing.T) {
+	type Target struct {
+		Name string `json:"name"`
+	}
+	encoder := NewURLEncoder()
+	if err := encoder.DecodeInto(url.Values{}, Target{}); err == nil {
+		t.Fatal("expected error for non-pointer target, got nil")
+	}
+}
+
+// TestDecodeInto_TagOptions verifies that tag options are stripped from
+// field names and fields tagged "-" are not populated.
+func TestDecodeInto_TagOptions(t *testing.T) {
+	type Target struct {
+		Name   string `json:"name,omitempty"`
+		Secret string `json:"-"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("name", "Ada")
+	values.Set("Secret", "x")
+	values.Set("-", "x")
+	var target Target
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Name != "Ada" || target.Secret != "" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+}
+
+// TestDecodeInto_Array verifies that array fields are populated and that
+// more elements than the array length are rejected.
+func TestDecodeInto_Array(t *testing.T) {
+	type Target struct {
+		Point [2]int `json:"point"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("point[0]", "3")
+	var target Target
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Point != [2]int{3, 0} {
+		t.Errorf("expected point=[3 0], got %v", target.Point)
+	}
+
+	values.Set("point[1]", "4")
+	values.Set("point[2]", "5")
+	if err := encoder.DecodeInto(values, &target); err == nil {
+		t.Fatal("expected error for too many array elements, got nil")
+	}
+}
+
+// TestDecodeInto_SliceOfStructs verifies that indexed objects decode to
+// structs in index order, including indices of two or more digits, and that
+// the errors of all elements are collected.
+func TestDecodeInto_SliceOfStructs(t *testing.T) {
+	type Item struct {
+		SKU  string   `json:"sku"`
+		Qty  int      `json:"qty"`
+		Tags []string `json:"tags"`
+	}
+	type Cart struct {
+		Items []Item  `json:"items"`
+		Refs  []*Item `json:"refs"`
+	}
+	values := url.Values{}
+	for i := range 12 {
+		prefix := "items[" + strconv.Itoa(i) + "]."
+		values.Set(prefix+"sku", "sku"+strconv.Itoa(i))
+		values.Set(prefix+"qty", strconv.Itoa(i))
+	}
+	values.Set("items[3].tags[1]", "b")
+	values.Set("items[3].tags[0]", "a")
+	encoder := NewURLEncoder()
+	var cart Cart
+	if err := encoder.DecodeInto(values, &cart); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cart.Items) != 12 {
+		t.Fatalf("expected 12 items, got %d", len(cart.Items))
+	}
+	for i, item := range cart.Items {
+		if item.SKU != "sku"+strconv.Itoa(i) || item.Qty != i {
+			t.Errorf("unexpected item %d: %+v", i, item)
+		}
+	}
+	if !reflect.DeepEqual(cart.Items[3].Tags, []string{"a", "b"}) {
+		t.Errorf("expected tags [a b], got %v", cart.Items[3].Tags)
+	}
+	bracket := NewURLEncoder(WithNotation(BracketNotation))
+	cart = Cart{}
+	refs := url.Values{"refs[0][sku]": {"ref"}, "refs[0][qty]": {"2"}}
+	if err := bracket.DecodeInto(refs, &cart); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []*Item{{SKU: "ref", Qty: 2}}
+	if !reflect.DeepEqual(cart.Refs, expected) {
+		t.Errorf("expected one ref {ref 2}, got %v", cart.Refs)
+	}
+
+	data, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := data["items"].([]any)
+	if items[10].(map[string]any)["sku"] != "sku10" {
+		t.Errorf("expected sku10 at index 10, got %v", items[10])
+	}
+
+	values = url.Values{
+		"items[0].qty": {"x"},
+		"items[1].qty": {"1"},
+		"items[2].qty": {"y"},
+	}
+	err = NewURLEncoder(WithCollectErrors()).DecodeInto(values, &cart)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("expected two joined errors, got %v", err)
+	}
+}
+
+// TestDecodeInto_DisallowUnknownKeys verifies that keys without a matching
+// field are listed in the error in strict mode.
+func TestDecodeInto_DisallowUnknownKeys(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Target struct {
+		Base
+		Name   string            `json:"name"`
+		Secret string            `json:"-"`
+		Meta   map[string]string `json:"meta"`
+		Nested struct {
+			Value string `json:"value"`
+		} `json:"nested"`
+	}
+	values := url.Values{}
+	values.Set("id", "1")
+	values.Set("name", "Ada")
+	values.Set("meta.any", "x")
+	values.Set("nested.value", "v")
+
+	encoder := NewURLEncoder(WithDisallowUnknownKeys())
+	var target Target
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values.Set("nmae", "typo")
+	values.Set("-", "x")
+	values.Set("nested.extra", "y")
+	err := encoder.DecodeInto(values, &target)
+	expected := `unknown keys: "-", "nmae"`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+
+	values.Del("nmae")
+	values.Del("-")
+	err = encoder.DecodeInto(values, &target)
+	expected = `unknown keys: "nested.extra"`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+
+	if err := NewURLEncoder().DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error without strict mode: %v", err)
+	}
+}
+
+// TestDecodeInto_DecodeHook verifies that the decode hook can assign values,
+// including byte fields, replace strings and report errors.
+func TestDecodeInto_DecodeHook(t *testing.T) {
+	type Target struct {
+		Created *time.Time `json:"created"`
+		Active  bool       `json:"active"`
+		Name    string     `json:"name"`
+		Key     []byte     `json:"key,hex"`
+	}
+	hook := func(from string, toType reflect.Type) (any, error) {
+		switch toType {
+		case reflect.TypeFor[[]byte]():
+			return []byte(from), nil
+		case reflect.TypeFor[time.Time]():
+			ms, err := strconv.ParseInt(from, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return time.UnixMilli(ms).UTC(), nil
+		case reflect.TypeFor[bool]():
+			switch from {
+			case "yes":
+				return "true", nil
+			case "no":
+				return false, nil
+			}
+		}
+		return nil, nil
+	}
+	encoder := NewURLEncoder(WithDecodeHook(hook))
+	values := url.Values{}
+	values.Set("created", "1700000000000")
+	values.Set("active", "yes")
+	values.Set("name", "Ada")
+	values.Set("key", "plain")
+	var target Target
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(target.Key) != "plain" {
+		t.Errorf("expected key=plain, got %q", target.Key)
+	}
+	expected := time.UnixMilli(1700000000000).UTC()
+	if target.Created == nil || !target.Created.Equal(expected) {
+		t.Errorf("expected created=%v, got %v", expected, target.Created)
+	}
+	if !target.Active || target.Name != "Ada" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+
+	values.Set("created", "soon")
+	if err := encoder.DecodeInto(values, &target); err == nil {
+		t.Fatal("expected error from decode hook, got nil")
+	}
+}
+
+// TestDecodeInto_Required verifies that all missing required fields are
+// reported together with their paths.
+func TestDecodeInto_Required(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku,required"`
+		Qty int    `json:"qty"`
+	}
+	type Order struct {
+		ID    string `json:"id,required"`
+		Email string `json:"email,required"`
+		Note  string `json:"note"`
+		Items []Item `json:"items"`
+	}
+	values := url.Values{
+		"email":        {""},
+		"items[0].sku": {"a"},
+		"items[1].qty": {"2"},
+	}
+	var order Order
+	err := NewURLEncoder().DecodeInto(values, &order)
+	var missing ErrMissingFields
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected ErrMissingFields, got %v", err)
+	}
+	expected := []string{"id", "email", "items[1].sku"}
+	if !reflect.DeepEqual(missing.Keys, expected) {
+		t.Errorf("expected %v, got %v", expected, missing.Keys)
+	}
+	expectedMessage := `missing required fields: "id", "email", ` +
+		`"items[1].sku"`
+	if err.Error() != expectedMessage {
+		t.Errorf("expected %s, got %s", expectedMessage, err)
+	}
+
+	values.Set("id", "1")
+	values.Set("email", "a@b.c")
+	values.Set("items[1].sku", "b")
+	if err := NewURLEncoder().DecodeInto(values, &order); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
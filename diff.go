@@ -0,0 +1,85 @@
+package urlcodec
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// ChangeType is the type of a change found by Diff.
+type ChangeType int
+
+const (
+	// ChangeAdded is a leaf that exists only in the second payload.
+	ChangeAdded ChangeType = iota
+	// ChangeRemoved is a leaf that exists only in the first payload.
+	ChangeRemoved
+	// ChangeModified is a leaf whose value differs between the payloads.
+	ChangeModified
+)
+
+// Change is a difference between two decoded payloads at a leaf path.
+type Change struct {
+	Type ChangeType // Type of the change
+	Path string     // Path of the leaf, e.g. "user.emails[1]"
+	Old  any        // Value in the first payload, nil if added
+	New  any        // Value in the second payload, nil if removed
+}
+
+// Diff returns the leaf values that were added, removed or modified from a
+// to b, with paths in dot notation and slice indices in brackets as used by
+// GetPath. Leaves are values other than objects and slices, so empty objects
+// and slices are not reported, and a leaf replaced by an object is reported
+// as the removal of the leaf and the addition of the object's leaves.
+// Changes are sorted by path in numerically-aware order.
+//
+// Parameters:
+//   - a: First decoded payload
+//   - b: Second decoded payload
+//
+// Returns:
+//   - []Change: Changes from a to b
+func Diff(a map[string]any, b map[string]any) []Change {
+	leavesA, leavesB := leafValues(a), leafValues(b)
+	paths := slices.Collect(maps.Keys(leavesA))
+	for path := range leavesB {
+		if _, ok := leavesA[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.SortFunc(paths, compareKeys)
+	var changes []Change
+	for _, path := range paths {
+		oldValue, inA := leavesA[path]
+		newValue, inB := leavesB[path]
+		switch {
+		case !inA:
+			changes = append(changes, Change{
+				Type: ChangeAdded, Path: path, New: newValue,
+			})
+		case !inB:
+			changes = append(changes, Change{
+				Type: ChangeRemoved, Path: path, Old: oldValue,
+			})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, Change{
+				Type: ChangeModified, Path: path, Old: oldValue, New: newValue,
+			})
+		}
+	}
+	return changes
+}
+
+// leafValues returns the leaf values of decoded data by path.
+func leafValues(data map[string]any) map[string]any {
+	leaves := make(map[string]any)
+	_ = Walk(data, func(path string, value any) error {
+		switch value.(type) {
+		case map[string]any, *OrderedMap, []any:
+		default:
+			leaves[path] = value
+		}
+		return nil
+	})
+	return leaves
+}
@@ -0,0 +1,40 @@
+package urlcodec
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiff verifies that added, removed and modified leaves are reported
+// with their paths in numerically-aware order.
+func TestDiff(t *testing.T) {
+	a := map[string]any{
+		"page": "1",
+		"user": map[string]any{"name": "Ada", "role": "admin"},
+		"tags": []any{"a", "b"},
+		"sort": "name",
+		"rows": []any{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"},
+	}
+	b := map[string]any{
+		"page": "2",
+		"user": map[string]any{"name": "Ada", "email": "ada@example.com"},
+		"tags": []any{"a"},
+		"sort": map[string]any{"field": "name"},
+		"rows": []any{"1", "2", "3", "4", "5", "6", "7", "8", "9", "11"},
+	}
+	expected := []Change{
+		{Type: ChangeModified, Path: "page", Old: "1", New: "2"},
+		{Type: ChangeModified, Path: "rows[9]", Old: "10", New: "11"},
+		{Type: ChangeRemoved, Path: "sort", Old: "name"},
+		{Type: ChangeAdded, Path: "sort.field", New: "name"},
+		{Type: ChangeRemoved, Path: "tags[1]", Old: "b"},
+		{Type: ChangeAdded, Path: "user.email", New: "ada@example.com"},
+		{Type: ChangeRemoved, Path: "user.role", Old: "admin"},
+	}
+	if got := Diff(a, b); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := Diff(a, a); got != nil {
+		t.Errorf("expected no changes, got %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package urlcodec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DoubleEncoding selects how decoding treats values that appear to be
+// percent-encoded twice, such as "%2520" for an encoded "%20".
+type DoubleEncoding int
+
+const (
+	// DoubleEncodingAllow keeps such values as they are.
+	DoubleEncodingAllow DoubleEncoding = iota
+	// DoubleEncodingFix decodes the remaining escapes of such values, so
+	// that "%2520" decodes to " " rather than "%20".
+	DoubleEncodingFix
+	// DoubleEncodingReject rejects such values with an ErrDoubleEncoded.
+	DoubleEncodingReject
+)
+
+// checkDoubleEncoding applies the double encoding mode to a decoded value.
+func (e *URLEncoder) checkDoubleEncoding(value string) (string, error) {
+	if e.doubleEncoding == DoubleEncodingAllow || !isDoubleEncoded(value) {
+		return value, nil
+	}
+	if e.doubleEncoding == DoubleEncodingReject {
+		return "", fmt.Errorf("%w: %q", ErrDoubleEncoded, value)
+	}
+	return unescapeValid(value), nil
+}
+
+// isDoubleEncoded reports whether a decoded value still contains a
+// percent-encoded escape, e.g. "%20" decoded from "%2520". A "%" followed
+// by two hex digits is rare in genuine input, so this is taken as a sign
+// that the value was encoded twice.
+func isDoubleEncoded(value string) bool {
+	for i := 0; i+2 < len(value); i++ {
+		if value[i] == '%' && isHex(value[i+1]) && isHex(value[i+2]) {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeValid decodes the valid percent-encoded escapes of a value and
+// keeps other "%" and "+" characters as they are.
+func unescapeValid(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '%' && i+2 < len(value) &&
+			isHex(value[i+1]) && isHex(value[i+2]) {
+			c = unhex(value[i+1])<<4 | unhex(value[i+2])
+			i += 2
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// isHex reports whether c is a hex digit.
+func isHex(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// unhex returns the value of a hex digit.
+func unhex(c byte) byte {
+	switch {
+	case isDigit(c):
+		return c - '0'
+	case c >= 'a':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
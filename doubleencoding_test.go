@@ -0,0 +1,48 @@
+package urlcodec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestDoubleEncoding verifies that twice-encoded values are kept, fixed or
+// rejected as configured.
+func TestDoubleEncoding(t *testing.T) {
+	query := "q=a%2520b%252B&p=100%25&r=%2541"
+	tests := []struct {
+		mode DoubleEncoding
+		q    string
+		r    string
+	}{
+		{DoubleEncodingAllow, "a%20b%2B", "%41"},
+		{DoubleEncodingFix, "a b+", "A"},
+	}
+	for _, tt := range tests {
+		got, err := NewURLEncoder(WithDoubleEncoding(tt.mode)).
+			DecodeString(query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got["q"] != tt.q || got["r"] != tt.r || got["p"] != "100%" {
+			t.Errorf("mode %d: expected q=%q r=%q p=100%%, got %v",
+				tt.mode, tt.q, tt.r, got)
+		}
+	}
+
+	encoder := NewURLEncoder(
+		WithDoubleEncoding(DoubleEncodingReject), WithCollectErrors(),
+	)
+	_, err := encoder.DecodeString(query)
+	if !errors.Is(err, ErrDoubleEncoded) {
+		t.Fatalf("expected ErrDoubleEncoded, got %v", err)
+	}
+	for _, key := range []string{`"q"`, `"r"`} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected error for %s, got %v", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), `"p"`) {
+		t.Errorf("expected no error for \"p\", got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package urlcodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrMaxDepthExceeded is returned when a key has more nested segments
+	// than allowed by WithMaxDepth.
+	ErrMaxDepthExceeded = errors.New("exceeded maximum recursion depth")
+	// ErrMaxSliceSize is returned when a decoded slice has more elements
+	// than allowed.
+	ErrMaxSliceSize = errors.New("exceeded maximum slice size")
+	// ErrMaxDecodedBytes is returned when decoded data uses more memory
+	// than allowed by WithMaxDecodedBytes.
+	ErrMaxDecodedBytes = errors.New("exceeded maximum decoded bytes")
+	// ErrMaxKeys is returned when decoded data has more keys than allowed
+	// by WithMaxKeys.
+	ErrMaxKeys = errors.New("exceeded maximum number of keys")
+	// ErrMaxValueLength is returned when a decoded value is longer than
+	// allowed by WithMaxValueLength.
+	ErrMaxValueLength = errors.New("exceeded maximum value length")
+	// ErrMaxQueryLength is returned when encoded values are longer than
+	// allowed by WithMaxQueryLength.
+	ErrMaxQueryLength = errors.New("exceeded maximum query length")
+	// ErrDoubleEncoded is returned for decoded values that appear to be
+	// percent-encoded twice with DoubleEncodingReject.
+	ErrDoubleEncoded = errors.New("value is percent-encoded twice")
+	// ErrMaxDecompressedSize is returned when a compressed value holds more
+	// bytes than allowed by WithMaxDecompressedSize.
+	ErrMaxDecompressedSize = errors.New("exceeded maximum decompressed size")
+	// ErrInvalidPagination is returned when pagination parameters are out
+	// of bounds or combined in conflicting ways.
+	ErrInvalidPagination = errors.New("invalid pagination")
+	// ErrInvalidSort is returned when a sort parameter has an empty,
+	// repeated or unsortable field.
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrInvalidFilter is returned when a filter has an unknown operator or
+	// the wrong number of values.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidFieldSelection is returned when a field selection has an
+	// empty, indexed or unselectable path.
+	ErrInvalidFieldSelection = errors.New("invalid field selection")
+	// ErrInvalidSearch is returned when a search parameter has an
+	// unterminated phrase.
+	ErrInvalidSearch = errors.New("invalid search")
+	// ErrCookieTooLarge is returned when a cookie value is longer than
+	// browsers are required to store.
+	ErrCookieTooLarge = errors.New("exceeded maximum cookie size")
+	// ErrInvalidStructuredField is returned when an HTTP structured field
+	// value cannot be parsed or a value cannot be represented in one.
+	ErrInvalidStructuredField = errors.New("invalid structured field")
+	// ErrTemplateMismatch is returned by MatchTemplate when a URL does not
+	// match the template.
+	ErrTemplateMismatch = errors.New("url does not match template")
+)
+
+// ErrConflictingKey is returned when a key is set twice, or both as a value
+// and as an object or slice, and conflicts are errors. Use errors.As with a
+// variable of type ErrConflictingKey to access the key.
+type ErrConflictingKey struct {
+	Key string // Key that conflicts with an earlier key
+}
+
+// Error returns the error message.
+func (e ErrConflictingKey) Error() string {
+	if e.Key == "" {
+		return "conflicting key: empty key already set"
+	}
+	return fmt.Sprintf("conflicting key: %q already set", e.Key)
+}
+
+// ErrInvalidIndex is returned when a key has a malformed slice index, e.g.
+// "a[x]" or "a[-1]". Use errors.As with a variable of type ErrInvalidIndex
+// to access the key.
+type ErrInvalidIndex struct {
+	Key string // Key with the invalid index
+}
+
+// Error returns the error message.
+func (e ErrInvalidIndex) Error() string {
+	return fmt.Sprintf("invalid slice index: %q", e.Key)
+}
+
+// ErrKeyNotAllowed is returned when a decoded key is excluded by
+// WithAllowedKeys or WithDeniedKeys and such keys are rejected. Use
+// errors.As with a variable of type ErrKeyNotAllowed to access the key.
+type ErrKeyNotAllowed struct {
+	Key string // Key that is not allowed
+}
+
+// Error returns the error message.
+func (e ErrKeyNotAllowed) Error() string {
+	return fmt.Sprintf("key %q is not allowed", e.Key)
+}
+
+// ErrKeyLimit is returned when a decoded key is longer or has more segments
+// than allowed by WithMaxKeyLength or WithMaxKeySegments. Use errors.As with
+// a variable of type ErrKeyLimit to access the key.
+type ErrKeyLimit struct {
+	Key      string // Key that exceeds the limit
+	Limit    int    // Exceeded limit
+	Segments bool   // Whether the segment limit was exceeded
+}
+
+// Error returns the error message.
+func (e ErrKeyLimit) Error() string {
+	if e.Segments {
+		return fmt.Sprintf(
+			"key %q has more than the maximum of %d segments", e.Key, e.Limit,
+		)
+	}
+	return fmt.Sprintf(
+		"key %q is longer than the maximum of %d bytes", e.Key, e.Limit,
+	)
+}
+
+// ErrUnsupportedType is returned when a value of a type that cannot be
+// encoded or decoded is found. Use errors.As with a variable of type
+// ErrUnsupportedType to access the type and path.
+type ErrUnsupportedType struct {
+	Type reflect.Type // Unsupported type
+	Path string       // Key of the value, empty at the top level
+}
+
+// Error returns the error message.
+func (e ErrUnsupportedType) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("unsupported type %s", e.Type)
+	}
+	return fmt.Sprintf("unsupported type %s at %q", e.Type, e.Path)
+}
+
+// ErrMissingFields is returned by DecodeInto when struct fields with the
+// "required" tag option are missing or empty. Use errors.As with a variable
+// of type ErrMissingFields to access their keys.
+type ErrMissingFields struct {
+	Keys []string // Keys of the missing fields in field order
+}
+
+// Error returns the error message.
+func (e ErrMissingFields) Error() string {
+	if len(e.Keys) == 1 {
+		return fmt.Sprintf("missing required field %q", e.Keys[0])
+	}
+	keys := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		keys[i] = strconv.Quote(key)
+	}
+	return "missing required fields: " + strings.Join(keys, ", ")
+}
+
+// ErrSchemaViolations is returned by Schema.Validate with the values that do
+// not match the schema. Use errors.As with a variable of type
+// ErrSchemaViolations to access them.
+type ErrSchemaViolations []SchemaViolation
+
+// Error returns the error message.
+func (e ErrSchemaViolations) Error() string {
+	messages := make([]string, len(e))
+	for i, violation := range e {
+		messages[i] = violation.String()
+	}
+	return "schema violations: " + strings.Join(messages, "; ")
+}
+
+// withKey sets the full key of conflicting key, invalid index and slice
+// size errors that were created for a segment of the key.
+func withKey(err error, key string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrMaxSliceSize) {
+		return fmt.Errorf(
+			"%w of %d at %q", ErrMaxSliceSize, maxSliceSize, key,
+		)
+	}
+	var conflict ErrConflictingKey
+	if errors.As(err, &conflict) {
+		return ErrConflictingKey{Key: key}
+	}
+	var invalid ErrInvalidIndex
+	if errors.As(err, &invalid) {
+		return ErrInvalidIndex{Key: key}
+	}
+	return err
+}
+
+// errorCollector records decoding errors. It stops at the first error
+// unless errors are collected with WithCollectErrors.
+type errorCollector struct {
+	collect bool    // Continue after errors
+	errs    []error // Recorded errors
+}
+
+// add records an error and reports whether decoding must stop. A nil error
+// is ignored.
+func (c *errorCollector) add(err error) bool {
+	if err == nil {
+		return false
+	}
+	c.errs = append(c.errs, err)
+	return !c.collect
+}
+
+// err returns the recorded error, all recorded errors joined with
+// errors.Join, or nil if there are none.
+func (c *errorCollector) err() error {
+	if len(c.errs) == 1 {
+		return c.errs[0]
+	}
+	return errors.Join(c.errs...)
+}
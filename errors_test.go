@@ -0,0 +1,166 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestErrors_Decode verifies that decode failures can be classified with
+// errors.Is and errors.As.
+func TestErrors_Decode(t *testing.T) {
+	encoder := NewURLEncoder(WithMaxDepth(2))
+	_, err := encoder.Decode(url.Values{"a.b.c": {"1"}})
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("expected ErrMaxDepthExceeded, got %v", err)
+	}
+
+	values := url.Values{}
+	for i := 0; i <= maxSliceSize; i++ {
+		values.Set("a["+strconv.Itoa(i)+"]", "x")
+	}
+	_, err = NewURLEncoder().Decode(values)
+	if !errors.Is(err, ErrMaxSliceSize) {
+		t.Errorf("expected ErrMaxSliceSize, got %v", err)
+	}
+
+	for _, values := range []url.Values{
+		{"a": {"1"}, "a.b": {"2"}},
+		{"a[0]": {"1"}, "a[]": {"2"}},
+		{"a.b": {"1"}, "a[0]": {"2"}},
+	} {
+		_, err = NewURLEncoder().Decode(values)
+		var conflict ErrConflictingKey
+		if !errors.As(err, &conflict) || conflict.Key == "" {
+			t.Errorf("%v: expected ErrConflictingKey, got %v", values, err)
+		}
+	}
+	qs := NewURLEncoder(WithQSCompat(QSOptions{}))
+	_, err = qs.Decode(url.Values{"a": {"1"}, "a[b]": {"2"}})
+	var conflict ErrConflictingKey
+	if !errors.As(err, &conflict) || conflict.Key != "a[b]" {
+		t.Errorf("expected ErrConflictingKey for a[b], got %v", err)
+	}
+
+	_, err = NewURLEncoder().Decode(url.Values{"x.a[b]": {"1"}})
+	var invalid ErrInvalidIndex
+	if !errors.As(err, &invalid) || invalid.Key != "x.a[b]" {
+		t.Errorf("expected ErrInvalidIndex for x.a[b], got %v", err)
+	}
+}
+
+// TestErrors_UnsupportedType verifies that unsupported types are reported
+// with their type and path.
+func TestErrors_UnsupportedType(t *testing.T) {
+	encoder := NewURLEncoder()
+	_, err := encoder.Encode(map[string]any{"ch": make(chan int)})
+	var unsupported ErrUnsupportedType
+	if !errors.As(err, &unsupported) || unsupported.Path != "ch" ||
+		unsupported.Type != reflect.TypeFor[chan int]() {
+		t.Errorf("expected ErrUnsupportedType at ch, got %v", err)
+	}
+
+	_, err = encoder.Encode(1)
+	if !errors.As(err, &unsupported) || unsupported.Path != "" {
+		t.Errorf("expected top-level ErrUnsupportedType, got %v", err)
+	}
+
+	var target struct {
+		Fn func() `json:"fn"`
+	}
+	err = encoder.DecodeInto(url.Values{"fn": {"x"}}, &target)
+	if !errors.As(err, &unsupported) || unsupported.Path != "fn" {
+		t.Errorf("expected ErrUnsupportedType at fn, got %v", err)
+	}
+}
+
+// TestWithCollectErrors verifies that all offending keys are reported.
+func TestWithCollectErrors(t *testing.T) {
+	values := url.Values{
+		"a":     {"1"},
+		"a.b":   {"2"},
+		"x[y]":  {"3"},
+		"n:int": {"four"},
+		"ok":    {"fine"},
+		"d.e.f": {"5"},
+	}
+	encoder := NewURLEncoder(
+		WithCollectErrors(), WithTypeHints(), WithMaxDepth(2),
+	)
+	_, err := encoder.Decode(values)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 4 {
+		t.Fatalf("expected four joined errors, got %v", err)
+	}
+	var conflict ErrConflictingKey
+	var invalid ErrInvalidIndex
+	if !errors.As(err, &conflict) || !errors.As(err, &invalid) ||
+		!errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("expected all error classes in %v", err)
+	}
+
+	_, err = NewURLEncoder(WithTypeHints()).Decode(values)
+	if _, ok := err.(interface{ Unwrap() []error }); ok || err == nil {
+		t.Errorf("expected a single error without collecting, got %v", err)
+	}
+
+	type Target struct {
+		Age    int  `json:"age"`
+		Active bool `json:"active"`
+		Name   string
+	}
+	var target Target
+	err = NewURLEncoder(WithCollectErrors()).DecodeInto(
+		url.Values{"age": {"old"}, "active": {"maybe"}}, &target,
+	)
+	joined, ok = err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 3 {
+		t.Errorf("expected three joined errors, got %v", err)
+	}
+}
+
+// TestErrors_EncodePath verifies that encode errors name the full key of
+// the offending value.
+func TestErrors_EncodePath(t *testing.T) {
+	type Item struct {
+		Price float64
+	}
+	type Order struct {
+		Items []Item `json:"items"`
+	}
+	encoder := NewURLEncoder()
+	data := map[string]any{"order": Order{Items: make([]Item, 4)}}
+	_, err := encoder.Encode(data)
+	if err == nil || !strings.Contains(err.Error(), `"order.items[0].Price"`) {
+		t.Errorf("expected path order.items[0].Price in error, got %v", err)
+	}
+
+	data = map[string]any{
+		"order": map[string]any{"items": []any{1, 2, 3, make(chan int)}},
+	}
+	_, err = encoder.Encode(data)
+	var unsupported ErrUnsupportedType
+	if !errors.As(err, &unsupported) ||
+		unsupported.Path != "order.items[3]" {
+		t.Errorf("expected path order.items[3], got %v", err)
+	}
+
+	data = map[string]any{"filters": map[string]any{"a.b": 1}}
+	_, err = encoder.Encode(data)
+	if err == nil || !strings.Contains(err.Error(), `"filters.a.b"`) {
+		t.Errorf("expected path filters.a.b in error, got %v", err)
+	}
+
+	bracket := NewURLEncoder(WithNotation(BracketNotation))
+	_, err = bracket.Encode(map[string]any{"order": Order{Items: []Item{{}}}})
+	expected := `"order[items][0][Price]"`
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected bracket path in error, got %v", err)
+	}
+}
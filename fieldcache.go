@@ -0,0 +1,52 @@
+package urlcodec
+
+import (
+	"reflect"
+	"sync"
+)
+
+// fieldPlans caches the parsed fields of struct types by fieldPlanKey, so
+// that tags are parsed once per type and configuration like in
+// encoding/json.
+var fieldPlans sync.Map
+
+// fieldPlanKey identifies the parsed fields of a struct type under the
+// configuration that parseField depends on.
+type fieldPlanKey struct {
+	t             reflect.Type // Struct type
+	tagName       string       // Struct tag used for field names
+	allowUntagged bool         // Name untagged fields by their Go names
+	bytesFormat   BytesFormat  // Default format used for bytes
+	timeEpoch     TimeEpoch    // Default epoch used for times
+}
+
+// fieldPlan is a struct field with its parsed tag.
+type fieldPlan struct {
+	index int                 // Index of the field in the struct
+	field reflect.StructField // Field metadata
+	spec  fieldSpec           // Parsed tag of the field
+	ok    bool                // Field has a name, see parseField
+}
+
+// structFields returns the fields of a struct type with their parsed tags,
+// parsing them on first use.
+func (e *URLEncoder) structFields(t reflect.Type) []fieldPlan {
+	key := fieldPlanKey{
+		t:             t,
+		tagName:       e.tagName,
+		allowUntagged: e.allowUntagged,
+		bytesFormat:   e.bytesFormat,
+		timeEpoch:     e.timeEpoch,
+	}
+	if plans, ok := fieldPlans.Load(key); ok {
+		return plans.([]fieldPlan)
+	}
+	plans := make([]fieldPlan, t.NumField())
+	for i := range plans {
+		field := t.Field(i)
+		spec, ok := e.parseField(field)
+		plans[i] = fieldPlan{index: i, field: field, spec: spec, ok: ok}
+	}
+	stored, _ := fieldPlans.LoadOrStore(key, plans)
+	return stored.([]fieldPlan)
+}
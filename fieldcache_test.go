@@ -0,0 +1,52 @@
+package urlcodec
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestStructFields_CachedPerConfiguration verifies that cached field plans
+// are kept apart for encoders whose configuration changes parsed tags, and
+// that concurrent encoding shares them safely.
+func TestStructFields_CachedPerConfiguration(t *testing.T) {
+	type Item struct {
+		ID   string `json:"id" schema:"item_id"`
+		Data []byte `json:"data"`
+	}
+	item := Item{ID: "1", Data: []byte{0xff}}
+	tests := []struct {
+		encoder  *URLEncoder
+		expected map[string]string
+	}{
+		{NewURLEncoder(), map[string]string{"id": "1", "data": "_w=="}},
+		{
+			NewURLEncoder(WithBytesFormat(BytesHex)),
+			map[string]string{"id": "1", "data": "ff"},
+		},
+		{
+			NewURLEncoder(WithSchemaCompat()),
+			map[string]string{"item_id": "1", "Data": "_w=="},
+		},
+	}
+	var wg sync.WaitGroup
+	for range 4 {
+		for _, tt := range tests {
+			wg.Go(func() {
+				values, err := tt.encoder.Encode(item)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				got := make(map[string]string)
+				for key := range values {
+					got[key] = values.Get(key)
+				}
+				if !reflect.DeepEqual(got, tt.expected) {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+			})
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,242 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// FieldSelection is the tree of fields selected by a sparse fieldset
+// parameter such as "author.name,author.id,title", for partial responses.
+// Each key is a selected field and maps to the selection of its subfields,
+// which is nil if the whole field is selected. Paths use the dot and
+// bracket syntax of GetPath. It implements encoding.TextMarshaler and
+// encoding.TextUnmarshaler, so that it can be used as a field of structs
+// that are encoded or decoded with DecodeInto.
+type FieldSelection map[string]FieldSelection
+
+// ParseFieldSelection parses a comma-separated list of field paths such as
+// "author.name,author.id,title". Spaces around paths are ignored. Selecting
+// a field entirely, e.g. "author", includes all of its subfields. Empty
+// paths, paths with slice indices and paths outside of allowed, unless it
+// is empty, are rejected with an ErrInvalidFieldSelection.
+//
+// Parameters:
+//   - s: Field list
+//   - allowed: Selectable paths, or none to allow all paths
+//
+// Returns:
+//   - FieldSelection: Selected fields
+//   - error: Error
+func ParseFieldSelection(
+	s string, allowed ...string,
+) (FieldSelection, error) {
+	selection := FieldSelection{}
+	if strings.TrimSpace(s) == "" {
+		return selection, nil
+	}
+	for _, path := range strings.Split(s, ",") {
+		if err := selection.Add(strings.TrimSpace(path)); err != nil {
+			return nil, err
+		}
+	}
+	if err := selection.Validate(allowed...); err != nil {
+		return nil, err
+	}
+	return selection, nil
+}
+
+// Add selects the field at a path and all of its subfields.
+//
+// Parameters:
+//   - path: Field path, e.g. "author.name"
+//
+// Returns:
+//   - error: Error wrapping ErrInvalidFieldSelection if the path is empty
+//     or has slice indices
+func (s FieldSelection) Add(path string) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFieldSelection, err)
+	}
+	node := s
+	for i, segment := range segments {
+		if segment.index {
+			return fmt.Errorf(
+				"%w: index in path %q", ErrInvalidFieldSelection, path,
+			)
+		}
+		child, ok := node[segment.name]
+		switch {
+		case i == len(segments)-1:
+			node[segment.name] = nil
+			return nil
+		case ok && child == nil:
+			// The field is already selected entirely.
+			return nil
+		case !ok:
+			child = FieldSelection{}
+			node[segment.name] = child
+		}
+		node = child
+	}
+	return nil
+}
+
+// Has reports whether the field at a path is selected, entirely or in
+// part.
+//
+// Parameters:
+//   - path: Field path, e.g. "author.name"
+//
+// Returns:
+//   - bool: Whether the field is selected
+func (s FieldSelection) Has(path string) bool {
+	segments, err := parsePath(path)
+	if err != nil {
+		return false
+	}
+	node := s
+	for _, segment := range segments {
+		child, ok := node[segment.name]
+		if !ok {
+			return false
+		}
+		if child == nil {
+			return true
+		}
+		node = child
+	}
+	return true
+}
+
+// Paths returns the paths of the fields selected entirely in sorted order.
+//
+// Returns:
+//   - []string: Field paths
+func (s FieldSelection) Paths() []string {
+	var paths []string
+	s.appendPaths(&paths, "")
+	return paths
+}
+
+// appendPaths appends the paths below a prefix to paths.
+func (s FieldSelection) appendPaths(paths *[]string, prefix string) {
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if child := s[name]; child != nil {
+			child.appendPaths(paths, path)
+		} else {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// Validate checks that every selected path is in allowed or below a path
+// in it, unless allowed is empty.
+//
+// Parameters:
+//   - allowed: Selectable paths, or none to allow all paths
+//
+// Returns:
+//   - error: Error wrapping ErrInvalidFieldSelection naming the first
+//     invalid path
+func (s FieldSelection) Validate(allowed ...string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, path := range s.Paths() {
+		if !slices.ContainsFunc(allowed, func(a string) bool {
+			return path == a || strings.HasPrefix(path, a+".")
+		}) {
+			return fmt.Errorf(
+				"%w: cannot select %q", ErrInvalidFieldSelection, path,
+			)
+		}
+	}
+	return nil
+}
+
+// String returns the field list, e.g. "author.id,author.name,title".
+//
+// Returns:
+//   - string: Field list
+func (s FieldSelection) String() string {
+	return strings.Join(s.Paths(), ",")
+}
+
+// MarshalText returns the field list.
+//
+// Returns:
+//   - []byte: Field list
+//   - error: Always nil
+func (s FieldSelection) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a field list with ParseFieldSelection, allowing all
+// paths.
+//
+// Parameters:
+//   - text: Field list
+//
+// Returns:
+//   - error: Error
+func (s *FieldSelection) UnmarshalText(text []byte) error {
+	selection, err := ParseFieldSelection(string(text))
+	if err != nil {
+		return err
+	}
+	*s = selection
+	return nil
+}
+
+// Project returns a copy of decoded data with only the selected fields.
+// Slices are projected element by element and objects may be maps or
+// OrderedMap values. An empty selection selects nothing.
+//
+// Parameters:
+//   - data: Decoded data
+//
+// Returns:
+//   - map[string]any: Projected data
+func (s FieldSelection) Project(data map[string]any) map[string]any {
+	projected := make(map[string]any)
+	for name, child := range s {
+		if value, ok := data[name]; ok {
+			projected[name] = child.projectValue(value)
+		}
+	}
+	return projected
+}
+
+// projectValue projects a value with the selection of its subfields, or
+// returns it as it is if the value is selected entirely.
+func (s FieldSelection) projectValue(value any) any {
+	if s == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return s.Project(v)
+	case *OrderedMap:
+		m := &OrderedMap{}
+		for key, elem := range v.All() {
+			if child, ok := s[key]; ok {
+				m.Set(key, child.projectValue(elem))
+			}
+		}
+		return m
+	case []any:
+		elems := make([]any, len(v))
+		for i, elem := range v {
+			elems[i] = s.projectValue(elem)
+		}
+		return elems
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestParseFieldSelection verifies that field lists are parsed into a tree
+// and written back in sorted order.
+func TestParseFieldSelection(t *testing.T) {
+	selection, err := ParseFieldSelection(
+		"author.name, author.id,title,meta[tags]", "author", "title", "meta",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := FieldSelection{
+		"author": {"name": nil, "id": nil},
+		"title":  nil,
+		"meta":   {"tags": nil},
+	}
+	if !reflect.DeepEqual(selection, expected) {
+		t.Errorf("expected %v, got %v", expected, selection)
+	}
+	expectedString := "author.id,author.name,meta.tags,title"
+	if got := selection.String(); got != expectedString {
+		t.Errorf("expected %s, got %s", expectedString, got)
+	}
+	for path, want := range map[string]bool{
+		"author": true, "author.id": true, "author.email": false,
+		"title": true, "title.x": true, "body": false,
+	} {
+		if got := selection.Has(path); got != want {
+			t.Errorf("%q: expected %v, got %v", path, want, got)
+		}
+	}
+
+	selection, err = ParseFieldSelection("author.name,author,author.id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := selection.String(); got != "author" {
+		t.Errorf("expected author, got %s", got)
+	}
+
+	for _, s := range []string{"a,", "a..b", "tags[0]", "secret"} {
+		_, err := ParseFieldSelection(s, "a", "tags")
+		if !errors.Is(err, ErrInvalidFieldSelection) {
+			t.Errorf("%q: expected ErrInvalidFieldSelection, got %v", s, err)
+		}
+	}
+}
+
+// TestFieldSelection_Binding verifies that FieldSelection fields are
+// decoded and encoded as field lists.
+func TestFieldSelection_Binding(t *testing.T) {
+	type request struct {
+		Fields FieldSelection `json:"fields"`
+	}
+	values := url.Values{"fields": {"title,author.name"}}
+	encoder := NewURLEncoder()
+	var req request
+	if err := encoder.DecodeInto(values, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !req.Fields.Has("author.name") || req.Fields.Has("author.id") {
+		t.Errorf("unexpected selection %v", req.Fields)
+	}
+	encoded, err := encoder.Encode(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := encoded.Get("fields"); got != "author.name,title" {
+		t.Errorf("expected author.name,title, got %s", got)
+	}
+}
+
+// TestFieldSelection_Project verifies that decoded data is reduced to the
+// selected fields.
+func TestFieldSelection_Project(t *testing.T) {
+	selection, err := ParseFieldSelection("title,comments.body,author")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := map[string]any{
+		"title": "Hello",
+		"body":  "World",
+		"author": map[string]any{
+			"name": "Ann", "id": "1",
+		},
+		"comments": []any{
+			map[string]any{"body": "a", "id": "1"},
+			map[string]any{"body": "b", "id": "2"},
+		},
+	}
+	expected := map[string]any{
+		"title": "Hello",
+		"author": map[string]any{
+			"name": "Ann", "id": "1",
+		},
+		"comments": []any{
+			map[string]any{"body": "a"},
+			map[string]any{"body": "b"},
+		},
+	}
+	if got := selection.Project(data); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
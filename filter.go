@@ -0,0 +1,199 @@
+package urlcodec
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// FilterOperator is the comparison of a filter, written as the last key
+// segment, e.g. "gte" in "price[gte]=10".
+type FilterOperator string
+
+const (
+	// FilterEq matches values equal to the value. It is also used for
+	// fields without an operator, e.g. "status=open".
+	FilterEq FilterOperator = "eq"
+	// FilterNe matches values not equal to the value.
+	FilterNe FilterOperator = "ne"
+	// FilterGt matches values greater than the value.
+	FilterGt FilterOperator = "gt"
+	// FilterGte matches values greater than or equal to the value.
+	FilterGte FilterOperator = "gte"
+	// FilterLt matches values less than the value.
+	FilterLt FilterOperator = "lt"
+	// FilterLte matches values less than or equal to the value.
+	FilterLte FilterOperator = "lte"
+	// FilterIn matches values equal to one of the comma-separated values.
+	FilterIn FilterOperator = "in"
+	// FilterNin matches values equal to none of the comma-separated
+	// values.
+	FilterNin FilterOperator = "nin"
+	// FilterContains matches values that contain the value.
+	FilterContains FilterOperator = "contains"
+)
+
+// filterOperators holds the known filter operators.
+var filterOperators = []FilterOperator{
+	FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte,
+	FilterIn, FilterNin, FilterContains,
+}
+
+// Filter is a condition on a field, e.g. "price" FilterGte "10" for
+// "price[gte]=10". Nested fields use dot notation, e.g. "author.name".
+type Filter struct {
+	Field    string         // Field path in dot notation
+	Operator FilterOperator // Comparison
+	Values   []string       // One value, or several for in and nin
+}
+
+// DecodeFilters decodes URL values like Decode and returns the filters in
+// them, sorted by field and operator, e.g. "price[gte]=10&status[in]=a,b"
+// gives price >= 10 and status in (a, b). A key segment that is a known
+// operator applies it to the field before it; values of fields without an
+// operator are compared with FilterEq. The values of in and nin are split at
+// commas. Filters that have the wrong number of values are rejected with an
+// ErrInvalidFilter. Use WithPrefix("filter") for keys such as
+// "filter[price][gte]".
+//
+// Parameters:
+//   - values: URL values
+//
+// Returns:
+//   - []Filter: Filters
+//   - error: Error
+func (e URLEncoder) DecodeFilters(values url.Values) ([]Filter, error) {
+	data, err := e.Decode(values)
+	if err != nil {
+		return nil, err
+	}
+	var filters []Filter
+	if err := collectFilters(&filters, "", data); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(filters, func(a, b Filter) int {
+		return cmp.Or(
+			compareKeys(a.Field, b.Field),
+			cmp.Compare(a.Operator, b.Operator),
+		)
+	})
+	return filters, nil
+}
+
+// collectFilters appends the filters in decoded data below a field path.
+func collectFilters(filters *[]Filter, field string, data any) error {
+	m, ok := data.(map[string]any)
+	if !ok {
+		return addFilter(filters, field, FilterEq, data)
+	}
+	for _, key := range slices.SortedFunc(maps.Keys(m), compareKeys) {
+		op := FilterOperator(key)
+		var err error
+		if field != "" && slices.Contains(filterOperators, op) {
+			err = addFilter(filters, field, op, m[key])
+		} else {
+			err = collectFilters(filters, joinPath(field, key), m[key])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFilter appends a filter with the values of a decoded value.
+func addFilter(
+	filters *[]Filter, field string, op FilterOperator, value any,
+) error {
+	var vals []string
+	switch v := value.(type) {
+	case []any:
+		for _, elem := range v {
+			vals = append(vals, fmt.Sprint(elem))
+		}
+	case map[string]any:
+		return fmt.Errorf(
+			"%w: %q of %q is not a value", ErrInvalidFilter, op, field,
+		)
+	default:
+		vals = []string{fmt.Sprint(v)}
+		if op == FilterIn || op == FilterNin {
+			vals = strings.Split(vals[0], ",")
+		}
+	}
+	if len(vals) != 1 && op != FilterIn && op != FilterNin {
+		return fmt.Errorf(
+			"%w: %q of %q needs one value, got %d",
+			ErrInvalidFilter, op, field, len(vals),
+		)
+	}
+	*filters = append(*filters, Filter{
+		Field: field, Operator: op, Values: vals,
+	})
+	return nil
+}
+
+// EncodeFilters encodes filters as URL values that DecodeFilters with the
+// same options decodes to the same filters, e.g. "price[gte]=10" with
+// BracketNotation. FilterEq filters are written without an operator unless
+// the field has other filters, and the values of in and nin are joined with
+// commas. Filters whose values contain a comma for in and nin, or the slice
+// delimiter of a delimited SliceStyle for other operators, are rejected
+// with an ErrInvalidFilter, since they would not decode to the same values.
+//
+// Parameters:
+//   - filters: Filters
+//
+// Returns:
+//   - url.Values: Encoded values
+//   - error: Error
+func (e URLEncoder) EncodeFilters(filters []Filter) (url.Values, error) {
+	counts := make(map[string]int, len(filters))
+	for _, filter := range filters {
+		counts[filter.Field]++
+	}
+	data := make(map[string]any)
+	for _, filter := range filters {
+		if !slices.Contains(filterOperators, filter.Operator) {
+			return nil, fmt.Errorf(
+				"%w: unknown operator %q", ErrInvalidFilter, filter.Operator,
+			)
+		}
+		if err := e.checkFilterValues(filter); err != nil {
+			return nil, err
+		}
+		path := filter.Field
+		if filter.Operator != FilterEq || counts[filter.Field] > 1 {
+			path += "." + string(filter.Operator)
+		}
+		value := strings.Join(filter.Values, ",")
+		if err := SetPath(data, path, value); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+		}
+	}
+	return e.Encode(data)
+}
+
+// checkFilterValues returns an ErrInvalidFilter if a value of a filter
+// contains the delimiter it is decoded with.
+func (e *URLEncoder) checkFilterValues(filter Filter) error {
+	sep, ok := byte(','), true
+	if filter.Operator != FilterIn && filter.Operator != FilterNin {
+		sep, ok = e.sliceDelimiter()
+	}
+	if !ok {
+		return nil
+	}
+	for _, value := range filter.Values {
+		if strings.IndexByte(value, sep) >= 0 {
+			return fmt.Errorf(
+				"%w: value %q of %q contains %q",
+				ErrInvalidFilter, value, filter.Field, sep,
+			)
+		}
+	}
+	return nil
+}
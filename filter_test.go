@@ -0,0 +1,99 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestDecodeFilters verifies that operators, nested fields and lists are
+// decoded into filters and encoded back.
+func TestDecodeFilters(t *testing.T) {
+	query := "price[gte]=10&price[lt]=100&status[in]=open,closed" +
+		"&author[name]=ann&tags[nin][0]=a&tags[nin][1]=b"
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoder := NewURLEncoder(WithNotation(BracketNotation))
+	filters, err := encoder.DecodeFilters(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Filter{
+		{Field: "author.name", Operator: FilterEq, Values: []string{"ann"}},
+		{Field: "price", Operator: FilterGte, Values: []string{"10"}},
+		{Field: "price", Operator: FilterLt, Values: []string{"100"}},
+		{
+			Field: "status", Operator: FilterIn,
+			Values: []string{"open", "closed"},
+		},
+		{Field: "tags", Operator: FilterNin, Values: []string{"a", "b"}},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("expected %v, got %v", expected, filters)
+	}
+
+	encoded, err := encoder.EncodeFilters(append(filters, Filter{
+		Field: "price", Operator: FilterEq, Values: []string{"50"},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := url.Values{
+		"author[name]": {"ann"},
+		"price[eq]":    {"50"},
+		"price[gte]":   {"10"},
+		"price[lt]":    {"100"},
+		"status[in]":   {"open,closed"},
+		"tags[nin]":    {"a,b"},
+	}
+	if !reflect.DeepEqual(encoded, want) {
+		t.Errorf("expected %v, got %v", want, encoded)
+	}
+}
+
+// TestDecodeFilters_Invalid verifies that filters with the wrong number of
+// values, unknown operators and values containing their delimiter are
+// rejected with ErrInvalidFilter.
+func TestDecodeFilters_Invalid(t *testing.T) {
+	encoder := NewURLEncoder()
+	for _, query := range []string{
+		"price.gte[0]=1&price.gte[1]=2", "price.gte.x=1",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := encoder.DecodeFilters(values); !errors.Is(
+			err, ErrInvalidFilter,
+		) {
+			t.Errorf("%q: expected ErrInvalidFilter, got %v", query, err)
+		}
+	}
+	_, err := encoder.EncodeFilters([]Filter{
+		{Field: "a", Operator: "like", Values: []string{"x"}},
+	})
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("expected ErrInvalidFilter, got %v", err)
+	}
+	_, err = encoder.EncodeFilters([]Filter{
+		{Field: "a", Operator: FilterIn, Values: []string{"x,y", "z"}},
+	})
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("in: expected ErrInvalidFilter, got %v", err)
+	}
+	if _, err := encoder.EncodeFilters([]Filter{
+		{Field: "a", Operator: FilterEq, Values: []string{"x,y"}},
+	}); err != nil {
+		t.Errorf("eq: unexpected error: %v", err)
+	}
+	comma := NewURLEncoder(WithSliceStyle(CommaSlices))
+	_, err = comma.EncodeFilters([]Filter{
+		{Field: "a", Operator: FilterEq, Values: []string{"x,y"}},
+	})
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("eq: expected ErrInvalidFilter, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Flatten encodes nested data like Encode but returns a flat map from key
+// paths to values, e.g. {"user": {"id": 1}} gives {"user.id": "1"}, for
+// stores such as config files or Redis hashes. Slices always use indexed
+// keys since a flat map cannot hold repeated keys.
+//
+// Parameters:
+//   - data: Data to flatten
+//
+// Returns:
+//   - map[string]string: Values by key path
+//   - error: Error
+func (e URLEncoder) Flatten(data map[string]any) (map[string]string, error) {
+	if e.sliceStyle == UnindexedSlices || e.sliceStyle == RepeatedKeySlices {
+		e.sliceStyle = IndexedSlices
+	}
+	values, err := e.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	flat := make(map[string]string, len(values))
+	for key, vals := range values {
+		if len(vals) > 1 {
+			return nil, fmt.Errorf(
+				"cannot flatten %q: %d values for one key", key, len(vals),
+			)
+		}
+		flat[key] = vals[0]
+	}
+	return flat, nil
+}
+
+// Unflatten decodes a flat map from key paths to values, as returned by
+// Flatten, into nested data like Decode.
+//
+// Parameters:
+//   - flat: Values by key path
+//
+// Returns:
+//   - map[string]any: Nested data
+//   - error: Error
+func (e URLEncoder) Unflatten(flat map[string]string) (map[string]any, error) {
+	values := make(url.Values, len(flat))
+	for key, value := range flat {
+		values.Set(key, value)
+	}
+	return e.Decode(values)
+}
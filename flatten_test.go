@@ -0,0 +1,47 @@
+package urlcodec
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFlattenUnflatten verifies that nested data round-trips through a flat
+// map of key paths, also with slice styles that repeat keys.
+func TestFlattenUnflatten(t *testing.T) {
+	data := map[string]any{
+		"user": map[string]any{"id": "1", "name": "Ada"},
+		"tags": []any{"a", "b"},
+	}
+	expected := map[string]string{
+		"user.id":   "1",
+		"user.name": "Ada",
+		"tags[0]":   "a",
+		"tags[1]":   "b",
+	}
+	for _, encoder := range []*URLEncoder{
+		NewURLEncoder(),
+		NewURLEncoder(WithSliceStyle(RepeatedKeySlices)),
+	} {
+		flat, err := encoder.Flatten(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(flat, expected) {
+			t.Errorf("expected %v, got %v", expected, flat)
+		}
+		got, err := encoder.Unflatten(flat)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, data) {
+			t.Errorf("expected %v, got %v", data, got)
+		}
+	}
+
+	_, err := NewURLEncoder().Unflatten(map[string]string{
+		"a": "1", "a.b": "2",
+	})
+	if err == nil {
+		t.Fatal("expected error for conflicting paths, got nil")
+	}
+}
@@ -0,0 +1,53 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// fragmentSafe holds the characters other than unreserved ones that are
+// written as they are in fragment parameters.
+const fragmentSafe = "!$'()*,/:;?@[]"
+
+// EncodeFragment encodes data as parameters for the fragment of a URL,
+// e.g. "a.b=1&c[0]=x", for single-page apps that keep state after the "#".
+// Keys are sorted like in EncodeToString. Characters that are allowed in a
+// fragment are written as they are, including "/", "?", ":", "@" and
+// brackets, so that the state stays readable; "&", "=", "+", "#", "%" and
+// spaces are percent-encoded. The result does not include the "#".
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Fragment
+//   - error: Error
+func (e URLEncoder) EncodeFragment(data any) (string, error) {
+	e.sortMapKeys = true
+	values, err := e.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	return joinPairs(values, fragmentSafe), nil
+}
+
+// DecodeFragment decodes the parameters of a URL fragment, such as
+// "#a.b=1&c[0]=x", like Decode. A leading "#" is optional. A "+" is kept as
+// it is rather than decoded as a space.
+//
+// Parameters:
+//   - fragment: Raw fragment
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error
+func (e URLEncoder) DecodeFragment(fragment string) (map[string]any, error) {
+	values, err := splitPairs(
+		strings.TrimPrefix(fragment, "#"), "&", url.PathUnescape,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse fragment %q: %w", fragment, err)
+	}
+	return e.Decode(values)
+}
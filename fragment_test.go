@@ -0,0 +1,46 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestFragment verifies that fragment parameters round trip through a URL
+// and keep fragment-safe characters readable.
+func TestFragment(t *testing.T) {
+	data := map[string]any{
+		"filter": map[string]any{"q": "a&b=c", "path": "/docs?x"},
+		"tags":   []any{"x y", "1+1"},
+	}
+	encoder := NewURLEncoder()
+	encoded, err := encoder.EncodeFragment(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "filter.path=/docs?x&filter.q=a%26b%3Dc" +
+		"&tags[0]=x%20y&tags[1]=1%2B1"
+	if encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	u, err := url.Parse("https://example.com/app#" + encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := encoder.DecodeFragment("#" + u.EscapedFragment())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %v, got %v", data, decoded)
+	}
+
+	decoded, err = encoder.DecodeFragment("a=1+1")
+	if err != nil || decoded["a"] != "1+1" {
+		t.Errorf("expected a=1+1, got %v, %v", decoded, err)
+	}
+	if _, err := encoder.DecodeFragment("#a=%zz"); err == nil {
+		t.Error("expected error for invalid escape, got nil")
+	}
+}
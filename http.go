@@ -0,0 +1,254 @@
+package urlcodec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const (
+	// formContentType is the content type of URL-encoded form bodies.
+	formContentType = "application/x-www-form-urlencoded"
+	// multipartMaxMemory is the number of bytes of a multipart form kept in
+	// memory, as with http.Request.FormValue.
+	multipartMaxMemory = 32 << 20
+)
+
+var (
+	fileHeaderType      = reflect.TypeFor[multipart.FileHeader]()
+	fileHeaderPtrType   = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeaderSliceType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// Precedence selects which source wins when a key is present in both the
+// URL query and the form body of a request.
+type Precedence int
+
+const (
+	// BodyPrecedence uses the form body values for keys in both sources.
+	BodyPrecedence Precedence = iota
+	// QueryPrecedence uses the URL query values for keys in both sources.
+	QueryPrecedence
+)
+
+// NewRequest creates an HTTP request with data encoded into it. Data is
+// sent as a form body for POST, PUT and PATCH and as the URL query for
+// other methods, as described in ApplyToRequest.
+//
+// Parameters:
+//   - ctx: Request context
+//   - method: HTTP method
+//   - baseURL: Request URL
+//   - data: Data to encode
+//
+// Returns:
+//   - *http.Request: Request
+//   - error: Error
+func (e URLEncoder) NewRequest(
+	ctx context.Context, method string, baseURL string, data any,
+) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, baseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+	if err := e.ApplyToRequest(req, data); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ApplyToRequest encodes data into an HTTP request. For POST, PUT and PATCH
+// the data replaces the body as an "application/x-www-form-urlencoded"
+// form. For other methods it is merged into the URL query, replacing
+// existing keys with the same name. Keys are written in the order of
+// EncodeToString. Form bodies always use the standard form format, while the
+// URL query follows the query format options such as WithPairSeparator.
+//
+// Parameters:
+//   - req: Request to modify
+//   - data: Data to encode
+//
+// Returns:
+//   - error: Error
+func (e URLEncoder) ApplyToRequest(req *http.Request, data any) error {
+	e.raw = &rawValues{}
+	values, err := e.Encode(data)
+	if err != nil {
+		return err
+	}
+	if !methodHasBody(req.Method) {
+		query := req.URL.Query()
+		for key, value := range values {
+			query[key] = value
+		}
+		req.URL.RawQuery = e.query.encodeSorted(query, e.raw)
+		return nil
+	}
+	body := queryFormat{}.encodeSorted(values, e.raw)
+	req.Body = io.NopCloser(strings.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", formContentType)
+	return nil
+}
+
+// EncodeBody encodes data as an "application/x-www-form-urlencoded" form
+// body. Keys are written in the order of EncodeToString. Pairs are always
+// separated by "&" and escaped as form values, whatever the query format.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - io.Reader: Form body
+//   - string: Content type of the body
+//   - error: Error
+func (e URLEncoder) EncodeBody(data any) (io.Reader, string, error) {
+	e.query = queryFormat{}
+	body, err := e.EncodeToString(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return strings.NewReader(body), formContentType, nil
+}
+
+// methodHasBody reports whether encoded data is sent as the body for an
+// HTTP method.
+func methodHasBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// DecodeRequest decodes the URL query and the form body of an HTTP request
+// like Decode. The form body is read for the
+// "application/x-www-form-urlencoded" and "multipart/form-data" content
+// types. A key present in both sources takes its values from the one
+// selected with WithPrecedence. Uploaded files are placed at their keys as
+// *multipart.FileHeader values, or as []*multipart.FileHeader if a key has
+// several files.
+//
+// Parameters:
+//   - r: Request
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error
+func (e URLEncoder) DecodeRequest(r *http.Request) (map[string]any, error) {
+	values, files, err := e.requestValues(r)
+	if err != nil {
+		return nil, err
+	}
+	return e.decode(values, files)
+}
+
+// DecodeRequestInto decodes the URL query and the form body of an HTTP
+// request into the value pointed to by target, like DecodeInto. Sources are
+// merged as in DecodeRequest. Uploaded files can be assigned to fields of
+// type *multipart.FileHeader, multipart.FileHeader and
+// []*multipart.FileHeader.
+//
+// Parameters:
+//   - r: Request
+//   - target: Non-nil pointer to the value to populate
+//
+// Returns:
+//   - error: Error
+func (e URLEncoder) DecodeRequestInto(r *http.Request, target any) error {
+	values, files, err := e.requestValues(r)
+	if err != nil {
+		return err
+	}
+	return e.decodeInto(values, files, target)
+}
+
+// requestValues merges the URL query and the form body of a request and
+// returns them with the uploaded files of a multipart form.
+func (e *URLEncoder) requestValues(
+	r *http.Request,
+) (url.Values, map[string][]*multipart.FileHeader, error) {
+	var files map[string][]*multipart.FileHeader
+	if isMultipart(r) {
+		err := r.ParseMultipartForm(multipartMaxMemory)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"cannot parse multipart form: %w", err,
+			)
+		}
+		files = r.MultipartForm.File
+	} else if err := r.ParseForm(); err != nil {
+		return nil, nil, fmt.Errorf("cannot parse request form: %w", err)
+	}
+	low, high := r.URL.Query(), r.PostForm
+	if e.precedence == QueryPrecedence {
+		low, high = high, low
+	}
+	values := make(url.Values, len(low)+len(high))
+	for key, value := range low {
+		values[key] = value
+	}
+	for key, value := range high {
+		values[key] = value
+	}
+	return values, files, nil
+}
+
+// isMultipart reports whether a request has a multipart/form-data body.
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// fileValue returns the decoded value of the files uploaded with a key.
+func fileValue(headers []*multipart.FileHeader) any {
+	if len(headers) == 1 {
+		return headers[0]
+	}
+	return headers
+}
+
+// assignFile assigns uploaded files to a *multipart.FileHeader,
+// multipart.FileHeader or []*multipart.FileHeader value. It returns false
+// if src is not an uploaded file.
+func assignFile(dst reflect.Value, src any, path string) (bool, error) {
+	var headers []*multipart.FileHeader
+	switch v := src.(type) {
+	case *multipart.FileHeader:
+		headers = []*multipart.FileHeader{v}
+	case []*multipart.FileHeader:
+		headers = v
+	default:
+		return false, nil
+	}
+	switch dst.Type() {
+	case fileHeaderSliceType:
+		dst.Set(reflect.ValueOf(headers))
+		return true, nil
+	case fileHeaderPtrType, fileHeaderType:
+		if len(headers) != 1 {
+			return true, fmt.Errorf(
+				"expected one file at %q, got %d", path, len(headers),
+			)
+		}
+		if dst.Type() == fileHeaderType {
+			dst.Set(reflect.ValueOf(headers[0]).Elem())
+		} else {
+			dst.Set(reflect.ValueOf(headers[0]))
+		}
+		return true, nil
+	case reflect.TypeFor[any]():
+		dst.Set(reflect.ValueOf(src))
+		return true, nil
+	}
+	return true, fmt.Errorf("cannot assign file to %s at %q", dst.Type(), path)
+}
@@ -0,0 +1,210 @@
+package urlcodec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestNewRequest_Query verifies that data is merged into the URL query of
+// requests without a body.
+func TestNewRequest_Query(t *testing.T) {
+	encoder := NewURLEncoder()
+	data := map[string]any{"page": 2, "filter": map[string]any{"q": "x y"}}
+	req, err := encoder.NewRequest(
+		context.Background(), http.MethodGet,
+		"https://example.com/items?page=1&sort=name", data,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "filter.q=x+y&page=2&sort=name"
+	if req.URL.RawQuery != expected {
+		t.Errorf("expected query %q, got %q", expected, req.URL.RawQuery)
+	}
+	if req.Body != nil {
+		t.Error("expected no body")
+	}
+}
+
+// TestNewRequest_Body verifies that data is sent as a form body for
+// requests with a body.
+func TestNewRequest_Body(t *testing.T) {
+	encoder := NewURLEncoder()
+	data := map[string]any{"tags": []string{"a", "b"}}
+	req, err := encoder.NewRequest(
+		context.Background(), http.MethodPost,
+		"https://example.com/items?x=1", data,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != formContentType {
+		t.Errorf("expected content type %q, got %q", formContentType, ct)
+	}
+	if req.URL.RawQuery != "x=1" {
+		t.Errorf("expected query to be unchanged, got %q", req.URL.RawQuery)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading body: %v", err)
+	}
+	expected := "tags%5B0%5D=a&tags%5B1%5D=b"
+	if string(body) != expected {
+		t.Errorf("expected body %q, got %q", expected, body)
+	}
+	if req.ContentLength != int64(len(expected)) {
+		t.Errorf("expected content length %d, got %d",
+			len(expected), req.ContentLength)
+	}
+	if err := req.ParseForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestNewRequest_Invalid verifies that invalid URLs and data are rejected.
+func TestNewRequest_Invalid(t *testing.T) {
+	encoder := NewURLEncoder()
+	ctx := context.Background()
+	_, err := encoder.NewRequest(ctx, http.MethodGet, "http://[::1", nil)
+	if err == nil {
+		t.Error("expected error for invalid URL, got nil")
+	}
+	if _, err = encoder.NewRequest(ctx, http.MethodGet, "/", 1); err == nil {
+		t.Error("expected error for invalid data, got nil")
+	}
+}
+
+// TestDecodeRequest verifies that the query and form body are merged with
+// the configured precedence.
+func TestDecodeRequest(t *testing.T) {
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(
+			http.MethodPost, "/items?id=1&user.name=query",
+			strings.NewReader("user.name=body&tags[0]=a"),
+		)
+		req.Header.Set("Content-Type", formContentType)
+		return req
+	}
+
+	got, err := NewURLEncoder().DecodeRequest(newRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"id":   "1",
+		"user": map[string]any{"name": "body"},
+		"tags": []any{"a"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	type Target struct {
+		ID   int `json:"id"`
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	var target Target
+	encoder := NewURLEncoder(WithPrecedence(QueryPrecedence))
+	if err := encoder.DecodeRequestInto(newRequest(), &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.ID != 1 || target.User.Name != "query" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+}
+
+// TestDecodeRequest_Multipart verifies that multipart text fields are
+// decoded as nested keys and uploaded files are placed at their keys.
+func TestDecodeRequest_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("doc.title", "Report"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"doc.file", "attachments", "attachments"} {
+		part, err := writer.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := part.Write([]byte("data")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(
+			http.MethodPost, "/upload", bytes.NewReader(body.Bytes()),
+		)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	encoder := NewURLEncoder()
+	got, err := encoder.DecodeRequest(newRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc, _ := got["doc"].(map[string]any)
+	if doc["title"] != "Report" {
+		t.Errorf("expected doc.title=Report, got %v", doc["title"])
+	}
+	if file, ok := doc["file"].(*multipart.FileHeader); !ok ||
+		file.Filename != "doc.file.txt" {
+		t.Errorf("expected file header at doc.file, got %v", doc["file"])
+	}
+	if files, ok := got["attachments"].([]*multipart.FileHeader); !ok ||
+		len(files) != 2 {
+		t.Errorf("expected two attachments, got %v", got["attachments"])
+	}
+
+	type Target struct {
+		Doc struct {
+			Title string                `json:"title"`
+			File  *multipart.FileHeader `json:"file"`
+		} `json:"doc"`
+		Attachments []*multipart.FileHeader `json:"attachments"`
+	}
+	var target Target
+	if err := encoder.DecodeRequestInto(newRequest(), &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Doc.Title != "Report" || target.Doc.File == nil ||
+		len(target.Attachments) != 2 {
+		t.Errorf("unexpected target: %+v", target)
+	}
+}
+
+// TestEncodeBody verifies that data is encoded as a form body.
+func TestEncodeBody(t *testing.T) {
+	encoder := NewURLEncoder()
+	body, contentType, err := encoder.EncodeBody(
+		map[string]any{"user": map[string]any{"name": "Ada"}, "id": 1},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != formContentType {
+		t.Errorf("expected %q, got %q", formContentType, contentType)
+	}
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "id=1&user.name=Ada" {
+		t.Errorf("expected %q, got %q", "id=1&user.name=Ada", got)
+	}
+	if _, _, err := encoder.EncodeBody(1); err == nil {
+		t.Error("expected error for invalid data, got nil")
+	}
+}
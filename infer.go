@@ -0,0 +1,58 @@
+package urlcodec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// inferTypes replaces string values of a decoded map that parse cleanly as
+// integers, floats or booleans with values of those types, recursively.
+func inferTypes(data map[string]any) {
+	for key, value := range data {
+		data[key] = inferType(value)
+	}
+}
+
+// inferType converts a decoded string to an int, float64 or bool if it
+// parses cleanly as one, and recurses into maps and slices. Integers must
+// be written without leading zeros or a "+" sign, integers too large for an
+// int stay strings so that long IDs are not rounded, and only "true" and
+// "false" are booleans.
+func inferType(value any) any {
+	switch v := value.(type) {
+	case string:
+		return inferString(v)
+	case map[string]any:
+		inferTypes(v)
+	case []any:
+		for i, elem := range v {
+			v[i] = inferType(elem)
+		}
+	}
+	return value
+}
+
+// inferString converts a string to the type it parses cleanly as. It returns
+// the string unchanged otherwise.
+func inferString(s string) any {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if !isNumberLiteral(s) {
+		return s
+	}
+	if !strings.ContainsAny(s, ".eE") {
+		if n, err := strconv.ParseInt(s, 10, 0); err == nil {
+			return int(n)
+		}
+		// A float64 cannot hold such integers exactly.
+		return s
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
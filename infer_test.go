@@ -0,0 +1,62 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestWithTypeInference verifies that values parsing cleanly as numbers or
+// booleans are converted and other values stay strings.
+func TestWithTypeInference(t *testing.T) {
+	values := url.Values{}
+	values.Set("age", "30")
+	values.Set("score", "9.5")
+	values.Set("exp", "1e3")
+	values.Set("active", "true")
+	values.Set("zip", "007")
+	values.Set("plus", "+1")
+	values.Set("name", "Ada")
+	values.Set("big", "99999999999999999999")
+	values.Set("id", "-123456789012345678901")
+	values.Set("flags[0]", "false")
+	values.Set("nested.neg", "-4")
+
+	encoder := NewURLEncoder(WithTypeInference())
+	got, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"age":    30,
+		"score":  9.5,
+		"exp":    1000.0,
+		"active": true,
+		"zip":    "007",
+		"plus":   "+1",
+		"name":   "Ada",
+		"big":    "99999999999999999999",
+		"id":     "-123456789012345678901",
+		"flags":  []any{false},
+		"nested": map[string]any{"neg": -4},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	data := map[string]any{
+		"n": 3, "ok": true, "list": []any{1, "x"},
+		"id": "12345678901234567890123",
+	}
+	encoded, err := encoder.Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := encoder.Decode(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected round trip %v, got %v", data, decoded)
+	}
+}
@@ -0,0 +1,133 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// JSONAPIQuery holds the query parameters defined by JSON:API:
+// "filter[...]", "page[...]", "sort", "include" and "fields[type]".
+type JSONAPIQuery struct {
+	Filters []Filter            // Filters from "filter[...]"
+	Page    Pagination          // Page from "page[number]", "page[size]" etc.
+	Sort    Sort                // Sort fields from "sort"
+	Include []string            // Relationship paths from "include"
+	Fields  map[string][]string // Sparse fieldsets by resource type
+}
+
+// jsonAPIPage holds the "page[...]" parameters of a JSON:API query.
+type jsonAPIPage struct {
+	Number int    `json:"number,omitempty"`
+	Size   int    `json:"size,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// DecodeJSONAPI decodes the JSON:API query parameters of URL values.
+// "filter[...]" parameters are decoded as with DecodeFilters, e.g.
+// "filter[price][gte]=10". "page[number]" and "page[size]" become Page and
+// PerPage of the Pagination, and "page[offset]", "page[limit]" and
+// "page[cursor]" its fields of the same name; use Pagination.Apply to check
+// them. "sort" is parsed with ParseSort and "include" and "fields[type]"
+// are split at commas. Other parameters are ignored. Keys use bracket
+// notation whatever the configured notation.
+//
+// Parameters:
+//   - values: URL values
+//
+// Returns:
+//   - JSONAPIQuery: Query parameters
+//   - error: Error
+func (e URLEncoder) DecodeJSONAPI(values url.Values) (JSONAPIQuery, error) {
+	e.notation = BracketNotation
+	var q JSONAPIQuery
+	var err error
+	e.prefix = "filter"
+	if q.Filters, err = e.DecodeFilters(values); err != nil {
+		return JSONAPIQuery{}, err
+	}
+	var page jsonAPIPage
+	e.prefix = "page"
+	if err := e.DecodeInto(values, &page); err != nil {
+		return JSONAPIQuery{}, err
+	}
+	q.Page = Pagination{
+		Page: page.Number, PerPage: page.Size,
+		Offset: page.Offset, Limit: page.Limit, Cursor: page.Cursor,
+	}
+	if q.Sort, err = ParseSort(values.Get("sort")); err != nil {
+		return JSONAPIQuery{}, err
+	}
+	q.Include = splitList(values.Get("include"))
+	for key, vals := range values {
+		name, ok := strings.CutPrefix(key, "fields[")
+		if !ok || !strings.HasSuffix(name, "]") {
+			continue
+		}
+		name = strings.TrimSuffix(name, "]")
+		if name == "" || strings.ContainsAny(name, "[]") {
+			return JSONAPIQuery{}, fmt.Errorf(
+				"invalid sparse fieldset key %q", key,
+			)
+		}
+		if q.Fields == nil {
+			q.Fields = make(map[string][]string)
+		}
+		q.Fields[name] = splitList(vals[0])
+	}
+	return q, nil
+}
+
+// EncodeJSONAPI encodes JSON:API query parameters as URL values that
+// DecodeJSONAPI decodes to the same query. Filters are written with
+// EncodeFilters under "filter", e.g. "filter[price][gte]=10", and empty
+// parameters are omitted.
+//
+// Parameters:
+//   - q: Query parameters
+//
+// Returns:
+//   - url.Values: Encoded values
+//   - error: Error
+func (e URLEncoder) EncodeJSONAPI(q JSONAPIQuery) (url.Values, error) {
+	e.notation = BracketNotation
+	e.prefix = "filter"
+	values, err := e.EncodeFilters(q.Filters)
+	if err != nil {
+		return nil, err
+	}
+	e.prefix = "page"
+	err = e.EncodeInto(values, jsonAPIPage{
+		Number: q.Page.Page, Size: q.Page.PerPage,
+		Offset: q.Page.Offset, Limit: q.Page.Limit, Cursor: q.Page.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Sort) > 0 {
+		values.Set("sort", q.Sort.String())
+	}
+	if len(q.Include) > 0 {
+		values.Set("include", strings.Join(q.Include, ","))
+	}
+	for _, name := range slices.Sorted(maps.Keys(q.Fields)) {
+		values.Set("fields["+name+"]", strings.Join(q.Fields[name], ","))
+	}
+	return values, nil
+}
+
+// splitList splits a comma-separated list, dropping spaces around items
+// and empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
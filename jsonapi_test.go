@@ -0,0 +1,76 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestJSONAPI verifies that JSON:API query parameters are decoded into a
+// typed query and encoded back.
+func TestJSONAPI(t *testing.T) {
+	query := "filter[author.name]=ann&filter[published][gte]=2024" +
+		"&page[number]=2&page[size]=10&sort=-created,title" +
+		"&include=author,comments.author&fields[articles]=title,body" +
+		"&fields[people]=name&other=x"
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoder := NewURLEncoder()
+	q, err := encoder.DecodeJSONAPI(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := JSONAPIQuery{
+		Filters: []Filter{
+			{Field: "author.name", Operator: FilterEq, Values: []string{"ann"}},
+			{Field: "published", Operator: FilterGte, Values: []string{"2024"}},
+		},
+		Page: Pagination{Page: 2, PerPage: 10},
+		Sort: Sort{
+			{Field: "created", Direction: Descending},
+			{Field: "title", Direction: Ascending},
+		},
+		Include: []string{"author", "comments.author"},
+		Fields: map[string][]string{
+			"articles": {"title", "body"},
+			"people":   {"name"},
+		},
+	}
+	if !reflect.DeepEqual(q, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, q)
+	}
+
+	encoded, err := encoder.EncodeJSONAPI(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := url.Values{
+		"filter[author][name]":   {"ann"},
+		"filter[published][gte]": {"2024"},
+		"page[number]":           {"2"},
+		"page[size]":             {"10"},
+		"sort":                   {"-created,title"},
+		"include":                {"author,comments.author"},
+		"fields[articles]":       {"title,body"},
+		"fields[people]":         {"name"},
+	}
+	if !reflect.DeepEqual(encoded, want) {
+		t.Errorf("expected %v, got %v", want, encoded)
+	}
+	again, err := encoder.DecodeJSONAPI(encoded)
+	if err != nil || !reflect.DeepEqual(again, expected) {
+		t.Errorf("expected %+v, got %+v, %v", expected, again, err)
+	}
+
+	for _, query := range []string{"page[number]=x", "fields[]=a", "sort=,"} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := encoder.DecodeJSONAPI(values); err == nil {
+			t.Errorf("%q: expected error", query)
+		}
+	}
+}
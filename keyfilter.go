@@ -0,0 +1,97 @@
+package urlcodec
+
+import (
+	"maps"
+	"path"
+	"slices"
+)
+
+// FilteredKeys selects what decoding does with keys that are excluded by
+// WithAllowedKeys or WithDeniedKeys.
+type FilteredKeys int
+
+const (
+	// FilteredKeysStrip drops excluded keys silently.
+	FilteredKeysStrip FilteredKeys = iota
+	// FilteredKeysReject rejects excluded keys with an ErrKeyNotAllowed.
+	FilteredKeysReject
+)
+
+// filtersKeys reports whether allowed or denied key patterns are set.
+func (e *URLEncoder) filtersKeys() bool {
+	return len(e.allowedKeys) > 0 || len(e.deniedKeys) > 0
+}
+
+// keyAllowed reports whether a decoded key matches an allowed pattern, if
+// any are set, and no denied pattern.
+func (e *URLEncoder) keyAllowed(key string) bool {
+	name, _ := e.splitTypeHint(key)
+	segments := slices.DeleteFunc(keySegments(name), func(s string) bool {
+		return s == ""
+	})
+	matches := func(pattern []string) bool {
+		return matchKeyPattern(pattern, segments)
+	}
+	if slices.ContainsFunc(e.deniedKeys, matches) {
+		return false
+	}
+	return len(e.allowedKeys) == 0 ||
+		slices.ContainsFunc(e.allowedKeys, matches)
+}
+
+// matchKeyPattern reports whether the segments of a pattern match the
+// segments of a key or of one of its ancestors. Pattern segments are
+// matched with path.Match, and a "**" segment matches any number of key
+// segments.
+func matchKeyPattern(pattern []string, key []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		return matchKeyPattern(pattern[1:], key) ||
+			len(key) > 0 && matchKeyPattern(pattern, key[1:])
+	}
+	if len(key) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], key[0])
+	return ok && err == nil && matchKeyPattern(pattern[1:], key[1:])
+}
+
+// keyPatterns splits patterns into their segments.
+func keyPatterns(patterns []string) [][]string {
+	split := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		split[i] = keySegments(pattern)
+	}
+	return split
+}
+
+// filterKeys returns the entries of a map whose keys are allowed. Other
+// keys are dropped, or rejected with an ErrKeyNotAllowed naming them with
+// FilteredKeysReject.
+func filterKeys[S ~[]E, E any](
+	e *URLEncoder, m map[string]S,
+) (map[string]S, error) {
+	if !e.filtersKeys() {
+		return m, nil
+	}
+	filtered := make(map[string]S, len(m))
+	errs := errorCollector{collect: e.collectErrors}
+	for _, key := range slices.SortedFunc(maps.Keys(m), compareKeys) {
+		if e.keyAllowed(key) {
+			filtered[key] = m[key]
+			continue
+		}
+		if e.filteredKeys == FilteredKeysReject {
+			err := ErrKeyNotAllowed{Key: key}
+			if errs.add(err) {
+				return nil, err
+			}
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
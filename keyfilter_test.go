@@ -0,0 +1,85 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestWithAllowedKeys verifies that keys outside of the allowed patterns
+// and inside of denied patterns are dropped.
+func TestWithAllowedKeys(t *testing.T) {
+	values := url.Values{
+		"user.name":        {"ann"},
+		"user.password":    {"secret"},
+		"items[0].id":      {"1"},
+		"items[0].price":   {"9"},
+		"internal.debug":   {"1"},
+		"meta.auth.token":  {"x"},
+		"meta.auth.expiry": {"2"},
+	}
+	encoder := NewURLEncoder(
+		WithAllowedKeys("user", "items[*].id", "meta"),
+		WithDeniedKeys("**.password", "meta.*.token"),
+	)
+	data, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"user":  map[string]any{"name": "ann"},
+		"items": []any{map[string]any{"id": "1"}},
+		"meta": map[string]any{
+			"auth": map[string]any{"expiry": "2"},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+}
+
+// TestWithDeniedKeys verifies that denied keys are rejected with
+// FilteredKeysReject, and that patterns apply after the prefix is removed.
+func TestWithDeniedKeys(t *testing.T) {
+	values := url.Values{
+		"filter.name":     {"a"},
+		"filter.password": {"b"},
+		"filter.token":    {"c"},
+	}
+	encoder := NewURLEncoder(
+		WithPrefix("filter"),
+		WithDeniedKeys("password", "tok*"),
+		WithFilteredKeys(FilteredKeysReject),
+	)
+	_, err := encoder.Decode(values)
+	var notAllowed ErrKeyNotAllowed
+	if !errors.As(err, &notAllowed) || notAllowed.Key != "password" {
+		t.Fatalf("expected ErrKeyNotAllowed for password, got %v", err)
+	}
+
+	encoder = NewURLEncoder(
+		WithPrefix("filter"),
+		WithDeniedKeys("password", "tok*"),
+		WithFilteredKeys(FilteredKeysReject),
+		WithCollectErrors(),
+	)
+	_, err = encoder.Decode(values)
+	expectedMessage := "key \"password\" is not allowed\n" +
+		"key \"token\" is not allowed"
+	if err == nil || err.Error() != expectedMessage {
+		t.Errorf("expected %q, got %v", expectedMessage, err)
+	}
+
+	type request struct {
+		Name string `json:"name"`
+	}
+	var req request
+	encoder = NewURLEncoder(WithPrefix("filter"), WithAllowedKeys("name"))
+	if err := encoder.DecodeInto(values, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Name != "a" {
+		t.Errorf("expected a, got %q", req.Name)
+	}
+}
@@ -0,0 +1,134 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// segmentOverhead is the approximate number of bytes used by each segment of
+// a decoded key for its map entry, interface value and string header.
+const segmentOverhead = 64
+
+// decodeBudget tracks the number of keys and the approximate number of
+// bytes used by decoded data.
+type decodeBudget struct {
+	limit   int // Maximum number of bytes, 0 or less for none
+	used    int // Bytes used so far
+	maxKeys int // Maximum number of keys, 0 or less for none
+	keys    int // Keys decoded so far
+
+	maxKeyLength int // Maximum length of a key, 0 or less for none
+	maxSegments  int // Maximum segments of a key, 0 or less for none
+}
+
+// newDecodeBudget returns a budget with the limits of the encoder.
+func (e *URLEncoder) newDecodeBudget() decodeBudget {
+	return decodeBudget{
+		limit:        e.maxDecoded,
+		maxKeys:      e.maxKeys,
+		maxKeyLength: e.maxKeyLength,
+		maxSegments:  e.maxSegments,
+	}
+}
+
+// checkKeys returns an ErrMaxKeys if n keys are more than allowed. It lets
+// decoding fail before the keys of a request with too many keys are
+// cleaned, filtered or sorted.
+func (b *decodeBudget) checkKeys(n int) error {
+	if b.maxKeys > 0 && n > b.maxKeys {
+		return fmt.Errorf("%w of %d", ErrMaxKeys, b.maxKeys)
+	}
+	return nil
+}
+
+// charge counts a decoded key and value and returns an ErrKeyLimit, an
+// ErrMaxKeys or an ErrMaxDecodedBytes naming the key if a limit is
+// exceeded.
+func (b *decodeBudget) charge(key string, value string) error {
+	if b.maxKeyLength > 0 && len(key) > b.maxKeyLength {
+		return ErrKeyLimit{Key: key, Limit: b.maxKeyLength}
+	}
+	b.keys++
+	if b.maxKeys > 0 && b.keys > b.maxKeys {
+		return fmt.Errorf("%w of %d at %q", ErrMaxKeys, b.maxKeys, key)
+	}
+	if b.limit <= 0 && b.maxSegments <= 0 {
+		return nil
+	}
+	segments := keySegmentCount(key)
+	if b.maxSegments > 0 && segments > b.maxSegments {
+		return ErrKeyLimit{Key: key, Limit: b.maxSegments, Segments: true}
+	}
+	if b.limit <= 0 {
+		return nil
+	}
+	b.used += len(key) + len(value) + segments*segmentOverhead
+	if b.used > b.limit {
+		return fmt.Errorf(
+			"%w of %d at %q", ErrMaxDecodedBytes, b.limit, key,
+		)
+	}
+	return nil
+}
+
+// keySegmentCount returns the number of object keys and slice indices in a
+// key, e.g. 3 for "a.b[0]" or "a[b][0]".
+func keySegmentCount(key string) int {
+	return 1 + strings.Count(key, ".") + strings.Count(key, "[")
+}
+
+// queryLength tracks the length of the query string of encoded pairs.
+type queryLength struct {
+	limit  int         // Maximum length, 0 or less for none
+	n      int         // Length of the pairs added so far
+	format queryFormat // Escaping of the pairs
+}
+
+// add counts a pair as "key=value" with a separating "&" and returns an
+// ErrMaxQueryLength naming the key if the limit is exceeded.
+func (q *queryLength) add(key string, value string) error {
+	if q.limit <= 0 {
+		return nil
+	}
+	if q.n > 0 {
+		q.n++
+	}
+	q.n += q.format.pairLength(key, value)
+	if q.n > q.limit {
+		return fmt.Errorf(
+			"%w of %d at %q", ErrMaxQueryLength, q.limit, key,
+		)
+	}
+	return nil
+}
+
+// checkQueryLength returns an ErrMaxQueryLength if the query string of the
+// values is longer than the maximum query length. The length does not
+// depend on the key order, which is only used to name the key at which the
+// limit is exceeded.
+func (e *URLEncoder) checkQueryLength(values url.Values) error {
+	if e.maxQueryLength <= 0 {
+		return nil
+	}
+	total := -1
+	for key, vals := range values {
+		for _, value := range vals {
+			total += e.query.pairLength(key, value) + 1
+		}
+	}
+	if total <= e.maxQueryLength {
+		return nil
+	}
+	length := queryLength{limit: e.maxQueryLength, format: e.query}
+	for _, key := range slices.SortedFunc(maps.Keys(values), compareKeys) {
+		for _, value := range values[key] {
+			if err := length.add(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestWithMaxDecodedBytes verifies that decoding stops once the decoded data
+// exceeds the byte budget, also when errors are collected and in qs mode.
+func TestWithMaxDecodedBytes(t *testing.T) {
+	values := url.Values{}
+	for i := range 100 {
+		values.Set("items["+strconv.Itoa(i)+"].name", "value")
+	}
+	encoders := map[string]*URLEncoder{
+		"default": NewURLEncoder(WithMaxDecodedBytes(1000)),
+		"collect": NewURLEncoder(
+			WithMaxDecodedBytes(1000), WithCollectErrors(),
+		),
+		"qs": NewURLEncoder(
+			WithMaxDecodedBytes(1000), WithQSCompat(QSOptions{}),
+		),
+	}
+	for name, encoder := range encoders {
+		_, err := encoder.Decode(values)
+		if !errors.Is(err, ErrMaxDecodedBytes) {
+			t.Errorf("%s: expected ErrMaxDecodedBytes, got %v", name, err)
+		}
+	}
+
+	encoder := NewURLEncoder(WithMaxDecodedBytes(1 << 20))
+	data, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data["items"].([]any)) != 100 {
+		t.Errorf("expected 100 items, got %v", data["items"])
+	}
+}
+
+// TestWithMaxDecodedBytes_LongValue verifies that a single long value is
+// counted against the budget and that the error names its key.
+func TestWithMaxDecodedBytes_LongValue(t *testing.T) {
+	encoder := NewURLEncoder(WithMaxDecodedBytes(100))
+	values := url.Values{"a": {"x"}, "big": {strings.Repeat("x", 200)}}
+	_, err := encoder.Decode(values)
+	if !errors.Is(err, ErrMaxDecodedBytes) {
+		t.Fatalf("expected ErrMaxDecodedBytes, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"big"`) {
+		t.Errorf("expected error to name the key, got %v", err)
+	}
+}
+
+// TestWithMaxKeys verifies that decoding rejects more keys than allowed,
+// counting repeated keys that are collected as slices once per value.
+func TestWithMaxKeys(t *testing.T) {
+	encoder := NewURLEncoder(WithMaxKeys(3))
+	tests := []struct {
+		name   string
+		values url.Values
+		ok     bool
+	}{
+		{"at limit", url.Values{"a": {"1"}, "b": {"2"}, "c": {"3"}}, true},
+		{
+			"distinct keys",
+			url.Values{"a": {"1"}, "b": {"2"}, "c": {"3"}, "d": {"4"}},
+			false,
+		},
+		{"repeated key", url.Values{"a": {"1", "2", "3", "4"}}, true},
+		{"unindexed key", url.Values{"a[]": {"1", "2", "3", "4"}}, false},
+	}
+	for _, tt := range tests {
+		_, err := encoder.Decode(tt.values)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrMaxKeys) {
+			t.Errorf("%s: expected ErrMaxKeys, got %v", tt.name, err)
+		}
+	}
+
+	collect := NewURLEncoder(WithMaxKeys(3), WithRepeatedKeys(CollectAsSlice))
+	_, err := collect.Decode(url.Values{"a": {"1", "2", "3", "4"}})
+	if !errors.Is(err, ErrMaxKeys) {
+		t.Errorf("collected: expected ErrMaxKeys, got %v", err)
+	}
+
+	qs := NewURLEncoder(WithMaxKeys(3), WithQSCompat(QSOptions{}))
+	_, err = qs.Decode(url.Values{"a[]": {"1", "2", "3", "4"}})
+	if !errors.Is(err, ErrMaxKeys) {
+		t.Errorf("qs: expected ErrMaxKeys, got %v", err)
+	}
+
+	filtered := NewURLEncoder(WithMaxKeys(3), WithAllowedKeys("a"))
+	_, err = filtered.Decode(
+		url.Values{"a": {"1"}, "b": {"2"}, "c": {"3"}, "d": {"4"}},
+	)
+	if !errors.Is(err, ErrMaxKeys) {
+		t.Errorf("filtered: expected ErrMaxKeys, got %v", err)
+	}
+}
+
+// TestWithMaxKeyLimits verifies that keys that are too long or have too many
+// segments are rejected with an ErrKeyLimit naming the key.
+func TestWithMaxKeyLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoder  *URLEncoder
+		key      string
+		segments bool
+	}{
+		{
+			"length", NewURLEncoder(WithMaxKeyLength(8)),
+			"abcdefghi", false,
+		},
+		{
+			"segments", NewURLEncoder(WithMaxKeySegments(3)),
+			"a.b[0][1]", true,
+		},
+		{
+			"qs segments",
+			NewURLEncoder(
+				WithMaxKeySegments(3), WithQSCompat(QSOptions{}),
+			),
+			"a[b][c][d]", true,
+		},
+	}
+	for _, tt := range tests {
+		values := url.Values{"ok": {"1"}, tt.key: {"1"}}
+		_, err := tt.encoder.Decode(values)
+		var limit ErrKeyLimit
+		if !errors.As(err, &limit) {
+			t.Errorf("%s: expected ErrKeyLimit, got %v", tt.name, err)
+			continue
+		}
+		if limit.Key != tt.key || limit.Segments != tt.segments {
+			t.Errorf("%s: unexpected error %+v", tt.name, limit)
+		}
+	}
+
+	encoder := NewURLEncoder(WithMaxKeyLength(8), WithMaxKeySegments(3))
+	if _, err := encoder.Decode(url.Values{"a.b[0]": {"1"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestWithMaxValueLength verifies that long values are rejected with their
+// key and collected like other invalid values.
+func TestWithMaxValueLength(t *testing.T) {
+	values := url.Values{
+		"name": {"Ada"},
+		"bio":  {strings.Repeat("x", 11)},
+		"note": {strings.Repeat("x", 12)},
+	}
+	encoder := NewURLEncoder(WithMaxValueLength(10), WithCollectErrors())
+	_, err := encoder.Decode(values)
+	if !errors.Is(err, ErrMaxValueLength) {
+		t.Fatalf("expected ErrMaxValueLength, got %v", err)
+	}
+	for _, key := range []string{`"bio"`, `"note"`} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected error to name %s, got %v", key, err)
+		}
+	}
+
+	qs := NewURLEncoder(WithMaxValueLength(10), WithQSCompat(QSOptions{}))
+	if _, err := qs.Decode(values); !errors.Is(err, ErrMaxValueLength) {
+		t.Errorf("qs: expected ErrMaxValueLength, got %v", err)
+	}
+
+	data, err := NewURLEncoder(WithMaxValueLength(12)).Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data["note"] != strings.Repeat("x", 12) {
+		t.Errorf("unexpected data: %v", data)
+	}
+}
+
+// TestWithMaxQueryLength verifies that Encode rejects values whose query
+// string is too long, naming the key that exceeds the limit, and that Pairs
+// stops before that key.
+func TestWithMaxQueryLength(t *testing.T) {
+	data := map[string]any{
+		"a": "1",
+		"b": "2",
+		"c": strings.Repeat("x", 20),
+	}
+	// "a=1&b=2" is 7 bytes and "&c=..." adds 23.
+	encoder := NewURLEncoder(WithMaxQueryLength(29))
+	_, err := encoder.Encode(data)
+	if !errors.Is(err, ErrMaxQueryLength) {
+		t.Fatalf("expected ErrMaxQueryLength, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"c"`) {
+		t.Errorf("expected error to name the key, got %v", err)
+	}
+	if _, err := encoder.EncodeToString(data); err == nil {
+		t.Error("expected error from EncodeToString, got nil")
+	}
+
+	pairs, errFn := encoder.Pairs(data)
+	var keys []string
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	if !errors.Is(errFn(), ErrMaxQueryLength) {
+		t.Errorf("expected ErrMaxQueryLength from Pairs, got %v", errFn())
+	}
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("expected pairs before the limit, got %v", keys)
+	}
+
+	values, err := NewURLEncoder(WithMaxQueryLength(30)).Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(values.Encode()); n != 30 {
+		t.Errorf("expected a query of 30 bytes, got %d", n)
+	}
+}
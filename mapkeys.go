@@ -0,0 +1,113 @@
+package urlcodec
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	stringerType        = reflect.TypeFor[fmt.Stringer]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// canEncodeMapKey reports whether map keys of the type can be encoded: they
+// must be strings or integers or implement encoding.TextMarshaler or
+// fmt.Stringer.
+func canEncodeMapKey(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t.Implements(textMarshalerType) ||
+		isIntegerKind(t.Kind()) || t.Implements(stringerType)
+}
+
+// encodeMapKey returns the text of a map key. Keys of string kind are used
+// as they are, otherwise MarshalText is preferred over String, which is
+// preferred over the decimal form of integers so that enums keep their
+// names.
+func encodeMapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	switch {
+	case key.CanInterface():
+		if m, ok := key.Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			if err != nil {
+				return "", fmt.Errorf("cannot marshal map key: %w", err)
+			}
+			return string(text), nil
+		}
+		if s, ok := key.Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+	case key.Type().Implements(textMarshalerType) ||
+		key.Type().Implements(stringerType):
+		// Keys reached through unexported fields can't call their methods,
+		// and writing them in another form would be surprising.
+		return "", fmt.Errorf(
+			"cannot marshal map key of type %s in an unexported field",
+			key.Type(),
+		)
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	default:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+}
+
+// canDecodeMapKey reports whether map keys of the type can be decoded: they
+// must be strings or integers or a pointer to the type must implement
+// encoding.TextUnmarshaler. Keys that only implement fmt.Stringer cannot be
+// decoded.
+func canDecodeMapKey(t reflect.Type) bool {
+	return t.Kind() == reflect.String || isIntegerKind(t.Kind()) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// decodeMapKey converts the text of a map key to the key type. Keys of
+// string kind are converted as they are, like in encodeMapKey.
+func decodeMapKey(t reflect.Type, key string) (reflect.Value, error) {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(key).Convert(t), nil
+	}
+	k := reflect.New(t)
+	if u, ok := k.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, fmt.Errorf(
+				"cannot unmarshal map key %q: %w", key, err,
+			)
+		}
+		return k.Elem(), nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid map key %q", key)
+		}
+		k.Elem().SetInt(n)
+	default:
+		n, err := strconv.ParseUint(key, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid map key %q", key)
+		}
+		k.Elem().SetUint(n)
+	}
+	return k.Elem(), nil
+}
+
+// isIntegerKind reports whether the kind is a signed or unsigned integer.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package urlcodec
+
+import (
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// color is an enum that implements fmt.Stringer only.
+type color int
+
+// String implements fmt.Stringer.
+func (c color) String() string {
+	return [...]string{"red", "green"}[c]
+}
+
+// TestEncodeDecode_TextMarshalerMapKeys verifies that map keys implementing
+// encoding.TextMarshaler round-trip through their text form.
+func TestEncodeDecode_TextMarshalerMapKeys(t *testing.T) {
+	type Routes struct {
+		Hosts map[netip.Addr]string `json:"hosts"`
+	}
+	routes := Routes{Hosts: map[netip.Addr]string{
+		netip.MustParseAddr("10.0.0.1"): "a",
+		netip.MustParseAddr("10.0.0.2"): "b",
+	}}
+	encoder := NewURLEncoder(WithDelimiterKeys(EscapeDelimiterKeys))
+	values, err := encoder.Encode(routes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"hosts.10%2E0%2E0%2E1": {"a"},
+		"hosts.10%2E0%2E0%2E2": {"b"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+	var decoded Routes
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, routes) {
+		t.Errorf("expected %v, got %v", routes, decoded)
+	}
+
+	values = url.Values{"hosts.bad": {"a"}}
+	if err := encoder.DecodeInto(values, &decoded); err == nil {
+		t.Fatal("expected error for invalid map key, got nil")
+	}
+}
+
+// TestEncode_StringerMapKeys verifies that map keys implementing only
+// fmt.Stringer are encoded with String and cannot be decoded.
+func TestEncode_StringerMapKeys(t *testing.T) {
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(map[string]any{
+		"votes": map[color]int{0: 1, 1: 2},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{"votes.red": {"1"}, "votes.green": {"2"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+	var decoded struct {
+		Votes map[color]string `json:"votes"`
+	}
+	if err := encoder.DecodeInto(values, &decoded); err == nil {
+		t.Fatal("expected error for map keys without UnmarshalText, got nil")
+	}
+}
+
+// TestEncode_UnexportedMapKeys verifies that map keys that need a method
+// but are reached through an unexported field are rejected with an error,
+// while integer keys are still encoded.
+func TestEncode_UnexportedMapKeys(t *testing.T) {
+	type votes map[color]int
+	type Poll struct {
+		votes
+	}
+	encoder := NewURLEncoder()
+	if _, err := encoder.Encode(Poll{votes: votes{0: 1}}); err == nil {
+		t.Fatal("expected error for unexported map keys, got nil")
+	}
+
+	type counts map[int]int
+	type Stats struct {
+		counts
+	}
+	values, err := encoder.Encode(Stats{counts: counts{7: 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("7"); got != "1" {
+		t.Errorf("expected 7=1, got %q", got)
+	}
+}
+
+// TestEncodeDecode_IntegerMapKeys verifies that integer-keyed maps encode
+// their keys in decimal and decode back, rejecting invalid keys.
+func TestEncodeDecode_IntegerMapKeys(t *testing.T) {
+	type Request struct {
+		Users  map[int]string   `json:"users"`
+		Scores map[int64]int    `json:"scores"`
+		Flags  map[uint8]string `json:"flags"`
+	}
+	request := Request{
+		Users:  map[int]string{42: "Ada", -1: "Bob"},
+		Scores: map[int64]int{9000000000: 3},
+		Flags:  map[uint8]string{7: "on"},
+	}
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"users.42":          {"Ada"},
+		"users.-1":          {"Bob"},
+		"scores.9000000000": {"3"},
+		"flags.7":           {"on"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+	var decoded Request
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, request) {
+		t.Errorf("expected %v, got %v", request, decoded)
+	}
+
+	for _, key := range []string{"flags.256", "flags.x", "users.1e3"} {
+		values := url.Values{key: {"a"}}
+		if err := encoder.DecodeInto(values, &decoded); err == nil {
+			t.Errorf("expected error for key %q, got nil", key)
+		}
+	}
+}
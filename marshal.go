@@ -0,0 +1,174 @@
+package urlcodec
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+)
+
+// URLValuesMarshaler is implemented by types that encode themselves as URL
+// values. The returned keys are nested under the key of the value, and an
+// empty key refers to the key of the value itself.
+type URLValuesMarshaler interface {
+	MarshalURLValues() (url.Values, error)
+}
+
+// URLParamMarshaler is implemented by types that encode themselves as a
+// single URL value.
+type URLParamMarshaler interface {
+	MarshalURLParam() (string, error)
+}
+
+// URLParamUnmarshaler is implemented by types that decode themselves from a
+// single URL value. It is the counterpart of URLParamMarshaler.
+type URLParamUnmarshaler interface {
+	UnmarshalURLParam(param string) error
+}
+
+// asMarshaler returns the value as a T if its type or a pointer to it
+// implements T. Pointers are not returned so that they are dereferenced
+// first, which skips nil pointers like other nil values and lets known types
+// such as time.Time use their configured format. Nil interfaces are not
+// returned either.
+func asMarshaler[T any](v reflect.Value) (T, bool) {
+	var zero T
+	if !v.IsValid() || !v.CanInterface() || v.Kind() == reflect.Ptr {
+		return zero, false
+	}
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return zero, false
+	}
+	t := reflect.TypeFor[T]()
+	if v.Type().Implements(t) {
+		m, ok := v.Interface().(T)
+		return m, ok
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(t) {
+		m, ok := v.Addr().Interface().(T)
+		return m, ok
+	}
+	return zero, false
+}
+
+// asUnmarshaler returns an addressable value as a T if a pointer to its type
+// implements T. Pointer values are not returned so that they are allocated
+// first.
+func asUnmarshaler[T any](v reflect.Value) (T, bool) {
+	var zero T
+	if !v.CanAddr() || v.Kind() == reflect.Ptr {
+		return zero, false
+	}
+	if !reflect.PointerTo(v.Type()).Implements(reflect.TypeFor[T]()) {
+		return zero, false
+	}
+	u, ok := v.Addr().Interface().(T)
+	return u, ok
+}
+
+// encodeMarshaler encodes a value that implements URLValuesMarshaler,
+// URLParamMarshaler or encoding.TextMarshaler, in that order of precedence.
+// It returns false if the value implements none of them.
+func (e *URLEncoder) encodeMarshaler(
+	values *url.Values, fieldTag string, v reflect.Value,
+) (bool, error) {
+	if m, ok := asMarshaler[URLValuesMarshaler](v); ok {
+		return true, e.encodeValuesMarshaler(values, fieldTag, m)
+	}
+	if m, ok := asMarshaler[URLParamMarshaler](v); ok {
+		return true, e.encodeParamMarshaler(values, fieldTag, m)
+	}
+	if m, ok := asMarshaler[encoding.TextMarshaler](v); ok {
+		return true, e.encodeTextMarshaler(values, fieldTag, m)
+	}
+	return false, nil
+}
+
+// encodeValuesMarshaler encodes a value using its MarshalURLValues method.
+func (e *URLEncoder) encodeValuesMarshaler(
+	values *url.Values, fieldTag string, m URLValuesMarshaler,
+) error {
+	marshaled, err := m.MarshalURLValues()
+	if err != nil {
+		return fmt.Errorf("cannot marshal %q as URL values: %w", fieldTag, err)
+	}
+	for _, key := range slices.Sorted(maps.Keys(marshaled)) {
+		newFieldTag := fieldTag
+		if key != "" {
+			newFieldTag = e.joinKey(fieldTag, key)
+		}
+		for _, val := range marshaled[key] {
+			if err := e.addValue(values, newFieldTag, val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeParamMarshaler encodes a value using its MarshalURLParam method.
+func (e *URLEncoder) encodeParamMarshaler(
+	values *url.Values, fieldTag string, m URLParamMarshaler,
+) error {
+	param, err := m.MarshalURLParam()
+	if err != nil {
+		return fmt.Errorf("cannot marshal %q as URL param: %w", fieldTag, err)
+	}
+	return e.setValue(values, fieldTag, param)
+}
+
+// encodeTextMarshaler encodes a value using its MarshalText method.
+func (e *URLEncoder) encodeTextMarshaler(
+	values *url.Values, fieldTag string, m encoding.TextMarshaler,
+) error {
+	text, err := m.MarshalText()
+	if err != nil {
+		return fmt.Errorf("cannot marshal %q as text: %w", fieldTag, err)
+	}
+	return e.setValue(values, fieldTag, string(text))
+}
+
+// assignUnmarshaler assigns a decoded string to a value that implements
+// URLParamUnmarshaler or encoding.TextUnmarshaler, in that order of
+// precedence. It returns false if the value implements neither of them.
+func (e *URLEncoder) assignUnmarshaler(
+	dst reflect.Value, src any, path string,
+) (bool, error) {
+	if u, ok := asUnmarshaler[URLParamUnmarshaler](dst); ok {
+		return true, e.assignParamUnmarshaler(u, src, path)
+	}
+	if u, ok := asUnmarshaler[encoding.TextUnmarshaler](dst); ok {
+		return true, e.assignTextUnmarshaler(u, src, path)
+	}
+	return false, nil
+}
+
+// assignParamUnmarshaler assigns a decoded string using UnmarshalURLParam.
+func (e *URLEncoder) assignParamUnmarshaler(
+	u URLParamUnmarshaler, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	if err := u.UnmarshalURLParam(s); err != nil {
+		return fmt.Errorf("cannot unmarshal %q from URL param: %w", path, err)
+	}
+	return nil
+}
+
+// assignTextUnmarshaler assigns a decoded string using UnmarshalText.
+func (e *URLEncoder) assignTextUnmarshaler(
+	u encoding.TextUnmarshaler, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	if err := u.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("cannot unmarshal %q from text: %w", path, err)
+	}
+	return nil
+}
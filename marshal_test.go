@@ -0,0 +1,305 @@
+package urlcodec
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// upperID is a custom ID type that marshals itself as upper-case text.
+type upperID string
+
+// MarshalText implements encoding.TextMarshaler.
+func (id upperID) MarshalText() ([]byte, error) {
+	if id == "" {
+		return nil, errors.New("empty id")
+	}
+	return []byte(strings.ToUpper(string(id))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *upperID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty id")
+	}
+	*id = upperID(strings.ToLower(string(text)))
+	return nil
+}
+
+// TestEncode_TextMarshaler verifies that types implementing
+// encoding.TextMarshaler are encoded as scalars using MarshalText.
+func TestEncode_TextMarshaler(t *testing.T) {
+	type Request struct {
+		ID   upperID     `json:"id"`
+		Addr netip.Addr  `json:"addr"`
+		Ptr  *netip.Addr `json:"ptr"`
+	}
+	addr := netip.MustParseAddr("10.0.0.1")
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(map[string]any{
+		"req": Request{ID: "abc", Addr: addr, Ptr: &addr},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("req.id"); got != "ABC" {
+		t.Errorf("expected req.id=ABC, got %q", got)
+	}
+	if got := values.Get("req.addr"); got != "10.0.0.1" {
+		t.Errorf("expected req.addr=10.0.0.1, got %q", got)
+	}
+	if got := values.Get("req.ptr"); got != "10.0.0.1" {
+		t.Errorf("expected req.ptr=10.0.0.1, got %q", got)
+	}
+}
+
+// TestEncode_TextMarshalerError verifies that MarshalText errors are
+// returned.
+func TestEncode_TextMarshalerError(t *testing.T) {
+	encoder := NewURLEncoder()
+	_, err := encoder.Encode(map[string]any{"id": upperID("")})
+	if err == nil {
+		t.Fatal("expected error from MarshalText, got nil")
+	}
+}
+
+// TestDecodeInto_TextUnmarshaler verifies that fields whose types implement
+// encoding.TextUnmarshaler are populated using UnmarshalText.
+func TestDecodeInto_TextUnmarshaler(t *testing.T) {
+	type Request struct {
+		ID    upperID      `json:"id"`
+		Addr  netip.Addr   `json:"addr"`
+		Ptr   *netip.Addr  `json:"ptr"`
+		Addrs []netip.Addr `json:"addrs"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("id", "ABC")
+	values.Set("addr", "10.0.0.1")
+	values.Set("ptr", "::1")
+	values.Set("addrs[0]", "192.168.0.1")
+
+	var req Request
+	if err := encoder.DecodeInto(values, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.ID != "abc" {
+		t.Errorf("expected id=abc, got %q", req.ID)
+	}
+	if req.Addr != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("expected addr=10.0.0.1, got %v", req.Addr)
+	}
+	if req.Ptr == nil || *req.Ptr != netip.MustParseAddr("::1") {
+		t.Errorf("expected ptr=::1, got %v", req.Ptr)
+	}
+	if len(req.Addrs) != 1 ||
+		req.Addrs[0] != netip.MustParseAddr("192.168.0.1") {
+		t.Errorf("expected addrs=[192.168.0.1], got %v", req.Addrs)
+	}
+}
+
+// TestDecodeInto_TextUnmarshalerError verifies that UnmarshalText errors are
+// returned.
+func TestDecodeInto_TextUnmarshalerError(t *testing.T) {
+	type Request struct {
+		Addr netip.Addr `json:"addr"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("addr", "not-an-ip")
+	var req Request
+	if err := encoder.DecodeInto(values, &req); err == nil {
+		t.Fatal("expected error from UnmarshalText, got nil")
+	}
+}
+
+// point is a test type that marshals itself as URL values.
+type point struct {
+	X, Y int
+}
+
+// MarshalURLValues implements URLValuesMarshaler.
+func (p point) MarshalURLValues() (url.Values, error) {
+	return url.Values{
+		"":  {fmt.Sprintf("%d,%d", p.X, p.Y)},
+		"x": {strconv.Itoa(p.X)},
+	}, nil
+}
+
+// level is a test type that marshals itself as a single URL value.
+type level int
+
+// MarshalURLParam implements URLParamMarshaler.
+func (l *level) MarshalURLParam() (string, error) {
+	if *l < 0 {
+		return "", errors.New("negative level")
+	}
+	return "L" + strconv.Itoa(int(*l)), nil
+}
+
+// UnmarshalURLParam implements URLParamUnmarshaler.
+func (l *level) UnmarshalURLParam(param string) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(param, "L"))
+	if err != nil {
+		return err
+	}
+	*l = level(n)
+	return nil
+}
+
+// hexBytes is a test byte slice type that marshals itself as a hex value.
+type hexBytes []byte
+
+// MarshalURLParam implements URLParamMarshaler.
+func (b hexBytes) MarshalURLParam() (string, error) {
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+// UnmarshalURLParam implements URLParamUnmarshaler.
+func (b *hexBytes) UnmarshalURLParam(param string) error {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(param, "0x"))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// TestEncode_URLValuesMarshaler verifies that URLValuesMarshaler output is
+// nested under the key of the value, including at the top level.
+func TestEncode_URLValuesMarshaler(t *testing.T) {
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(map[string]any{"p": point{X: 1, Y: 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{"p": {"1,2"}, "p.x": {"1"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	values, err = encoder.Encode(&point{X: 3, Y: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = url.Values{"": {"3,4"}, "x": {"3"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+// TestEncode_URLValuesMarshaler_Brackets verifies that URLValuesMarshaler
+// keys are nested in the configured notation.
+func TestEncode_URLValuesMarshaler_Brackets(t *testing.T) {
+	encoder := NewURLEncoder(WithNotation(BracketNotation))
+	values, err := encoder.Encode(map[string]any{
+		"a": map[string]any{"p": point{X: 1, Y: 2}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{"a[p]": {"1,2"}, "a[p][x]": {"1"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+// TestEncode_URLParamMarshaler verifies that URLParamMarshaler output is
+// used as the value and that its errors are returned.
+func TestEncode_URLParamMarshaler(t *testing.T) {
+	type Settings struct {
+		Level level `json:"level"`
+	}
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(&Settings{Level: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("level"); got != "L3" {
+		t.Errorf("expected level=L3, got %q", got)
+	}
+	if _, err := encoder.Encode(&Settings{Level: -1}); err == nil {
+		t.Fatal("expected error from MarshalURLParam, got nil")
+	}
+}
+
+// TestEncode_URLParamMarshalerBytes verifies that URLParamMarshaler takes
+// precedence over the bytes format for byte slice fields, like it does for
+// map values.
+func TestEncode_URLParamMarshalerBytes(t *testing.T) {
+	type Key struct {
+		ID hexBytes `json:"id,base64"`
+	}
+	encoder := NewURLEncoder()
+	key := Key{ID: hexBytes{0xca, 0xfe}}
+	values, err := encoder.Encode(&key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("id"); got != "0xcafe" {
+		t.Errorf("expected id=0xcafe, got %q", got)
+	}
+	values, err = encoder.Encode(map[string]any{"id": key.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("id"); got != "0xcafe" {
+		t.Errorf("expected map id=0xcafe, got %q", got)
+	}
+}
+
+// TestDecodeInto_URLParamUnmarshalerBytes verifies that byte slice fields
+// with a URLParamUnmarshaler round-trip through it instead of the bytes
+// format.
+func TestDecodeInto_URLParamUnmarshalerBytes(t *testing.T) {
+	type Key struct {
+		ID hexBytes `json:"id,base64"`
+	}
+	encoder := NewURLEncoder()
+	key := Key{ID: hexBytes{0xca, 0xfe}}
+	values, err := encoder.Encode(&key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Key
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, key) {
+		t.Errorf("expected %v, got %v", key, decoded)
+	}
+}
+
+// TestDecodeInto_URLParamUnmarshaler verifies that URLParamUnmarshaler is
+// used to populate fields and that its errors are returned.
+func TestDecodeInto_URLParamUnmarshaler(t *testing.T) {
+	type Settings struct {
+		Level  level   `json:"level"`
+		Levels []level `json:"levels"`
+	}
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	values.Set("level", "L3")
+	values.Set("levels[0]", "L4")
+	var settings Settings
+	if err := encoder.DecodeInto(values, &settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.Level != 3 {
+		t.Errorf("expected level=3, got %d", settings.Level)
+	}
+	if len(settings.Levels) != 1 || settings.Levels[0] != 4 {
+		t.Errorf("expected levels=[4], got %v", settings.Levels)
+	}
+
+	values.Set("level", "high")
+	if err := encoder.DecodeInto(values, &settings); err == nil {
+		t.Fatal("expected error from UnmarshalURLParam, got nil")
+	}
+}
@@ -0,0 +1,105 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// EncodeMatrix encodes data as matrix parameters to attach to a path
+// segment, e.g. ";name=Ada;tags=a,b;user.id=1". Keys are nested like in
+// Encode and sorted like in EncodeToString, and slices of scalars are
+// written as comma-separated lists. Keys and values are escaped for a path
+// segment.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Matrix parameters, empty if there are none
+//   - error: Error
+func (e URLEncoder) EncodeMatrix(data any) (string, error) {
+	e.sortMapKeys = true
+	e.sliceStyle = CommaSlices
+	// Commas in scalars are escaped, so that they are written as "%2C".
+	e.escapeScalars = true
+	values, err := e.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	keys := slices.SortedFunc(maps.Keys(values), compareKeys)
+	var b strings.Builder
+	for _, key := range keys {
+		for _, value := range values[key] {
+			b.WriteByte(';')
+			b.WriteString(url.PathEscape(key))
+			b.WriteByte('=')
+			b.WriteString(matrixEscape(value))
+		}
+	}
+	return b.String(), nil
+}
+
+// matrixEscape escapes a matrix parameter value for a path segment. The
+// value has its literal commas escaped with a backslash, which are written
+// as "%2C", and the other commas separate list elements and are kept.
+func matrixEscape(value string) string {
+	elems := splitDelimited(value, ',')
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return strings.Join(elems, ",")
+}
+
+// matrixUnescape decodes a matrix parameter value. Commas written as they
+// are separate list elements, while literal commas written as "%2C" and
+// backslashes are escaped with a backslash, so that they are not split.
+func (e *URLEncoder) matrixUnescape(value string) (string, error) {
+	elems := strings.Split(value, ",")
+	for i, elem := range elems {
+		elem, err := url.PathUnescape(elem)
+		if err != nil {
+			return "", err
+		}
+		elems[i] = e.escapeDelimiter(elem)
+	}
+	return strings.Join(elems, ","), nil
+}
+
+// DecodeMatrix decodes the matrix parameters of a path segment, e.g.
+// "users;role=admin;tags=a,b", and returns the segment name before the
+// first ";" and the decoded parameters. Keys are decoded like in Decode and
+// comma-separated values are decoded as slices, while commas escaped as
+// "%2C" are kept in values. A parameter without "=" has an empty value.
+//
+// Parameters:
+//   - segment: Path segment with matrix parameters
+//
+// Returns:
+//   - string: Unescaped segment name
+//   - map[string]any: Decoded parameters
+//   - error: Error
+func (e URLEncoder) DecodeMatrix(
+	segment string,
+) (string, map[string]any, error) {
+	name, params, _ := strings.Cut(segment, ";")
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid path segment %q: %w", segment, err)
+	}
+	e.sliceStyle = CommaSlices
+	e.escapeScalars = true
+	values, err := splitPairs(params, ";", e.matrixUnescape)
+	if err != nil {
+		return "", nil, fmt.Errorf(
+			"invalid matrix parameters %q: %w", segment, err,
+		)
+	}
+	data, err := e.decode(values, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, data, nil
+}
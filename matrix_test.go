@@ -0,0 +1,71 @@
+package urlcodec
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMatrix verifies that matrix parameters round trip with nested keys,
+// lists and escaped characters.
+func TestMatrix(t *testing.T) {
+	data := map[string]any{
+		"role": "admin",
+		"tags": []any{"a", "b,c"},
+		"user": map[string]any{"name": "Ada Lovelace"},
+		"path": "x;y/z",
+	}
+	encoder := NewURLEncoder()
+	encoded, err := encoder.EncodeMatrix(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ";path=x%3By%2Fz;role=admin;tags=a,b%2Cc" +
+		";user.name=Ada%20Lovelace"
+	if encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	name, decoded, err := encoder.DecodeMatrix("users" + encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "users" {
+		t.Errorf("expected segment users, got %q", name)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %v, got %v", data, decoded)
+	}
+
+	name, decoded, err = encoder.DecodeMatrix("cars;color=red;year")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedData := map[string]any{"color": "red", "year": ""}
+	if name != "cars" || !reflect.DeepEqual(decoded, expectedData) {
+		t.Errorf("expected cars %v, got %q %v", expectedData, name, decoded)
+	}
+
+	name, decoded, err = encoder.DecodeMatrix(
+		`cars;k=a%2Cb;l=a%2Cb,c;m=%5C%2C`,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedData = map[string]any{
+		"k": "a,b", "l": []any{"a,b", "c"}, "m": `\,`,
+	}
+	if !reflect.DeepEqual(decoded, expectedData) {
+		t.Errorf("expected %v, got %v", expectedData, decoded)
+	}
+	encoded, err = encoder.EncodeMatrix(expectedData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := ";k=a%2Cb;l=a%2Cb,c;m=%5C%2C"; encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	if _, _, err := encoder.DecodeMatrix("a;b=%zz"); err == nil {
+		t.Error("expected error for invalid escape, got nil")
+	}
+}
@@ -0,0 +1,93 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// DecodeMerge decodes URL values like Decode and merges the result into an
+// existing map, so that defaults can be set in the map and overridden by
+// query parameters. Objects are merged key by key. A key that is set in both
+// the map and the values, other than to two objects, is resolved by the
+// conflict strategy, where the map holds the first value and the values the
+// last: use WithConflictStrategy(LastWins) to let the values override the
+// map. Slices are resolved as a whole because their indices are compacted
+// when decoding. With DeepMerge, slices are concatenated. On error, the map
+// may hold part of the decoded values.
+//
+// Parameters:
+//   - values: URL values
+//   - into: Map to merge into
+//
+// Returns:
+//   - error: Error
+func (e URLEncoder) DecodeMerge(values url.Values, into map[string]any) error {
+	if into == nil {
+		return fmt.Errorf("cannot merge into nil map")
+	}
+	data, err := e.decode(values, nil)
+	if err != nil {
+		return err
+	}
+	errs := errorCollector{collect: e.collectErrors}
+	e.mergeMap(into, data, "", &errs)
+	return errs.err()
+}
+
+// mergeMap merges decoded data into a map in sorted key order, recording
+// conflicts in the collector. It reports whether merging must stop.
+func (e *URLEncoder) mergeMap(
+	into map[string]any,
+	data map[string]any,
+	path string,
+	errs *errorCollector,
+) bool {
+	for _, key := range slices.SortedFunc(maps.Keys(data), compareKeys) {
+		value := data[key]
+		keyPath := joinPath(path, key)
+		existing, ok := into[key]
+		if !ok {
+			into[key] = value
+			continue
+		}
+		existingMap, isMap := existing.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		if isMap && valueIsMap {
+			if e.mergeMap(existingMap, valueMap, keyPath, errs) {
+				return true
+			}
+			continue
+		}
+		merged, err := e.mergeDecoded(existing, value)
+		if err != nil {
+			if errs.add(ErrConflictingKey{Key: keyPath}) {
+				return true
+			}
+			continue
+		}
+		into[key] = merged
+	}
+	return false
+}
+
+// mergeDecoded resolves a decoded value that is set at a key of the map
+// that already holds a value. With DeepMerge, slices are concatenated and an
+// object meeting another value keeps it under its empty key, as when
+// decoding.
+func (e *URLEncoder) mergeDecoded(existing any, value any) (any, error) {
+	if e.conflicts == DeepMerge {
+		switch v := value.(type) {
+		case []any:
+			if ex, ok := existing.([]any); ok {
+				return append(ex, v...), nil
+			}
+		case map[string]any:
+			obj := asObject(existing)
+			e.mergeMap(obj, v, "", &errorCollector{})
+			return obj, nil
+		}
+	}
+	return e.mergeConflict(existing, value)
+}
@@ -0,0 +1,91 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// mergeDefaults returns a map of defaults for the DecodeMerge tests.
+func mergeDefaults() map[string]any {
+	return map[string]any{
+		"page": "1",
+		"sort": map[string]any{"field": "name", "order": "asc"},
+		"tags": []any{"a"},
+	}
+}
+
+// TestDecodeMerge verifies that decoded values are merged into an existing
+// map using the conflict strategy.
+func TestDecodeMerge(t *testing.T) {
+	values := url.Values{
+		"page":       {"3"},
+		"sort.order": {"desc"},
+		"tags[0]":    {"b"},
+		"q":          {"go"},
+	}
+	tests := []struct {
+		strategy ConflictStrategy
+		expected map[string]any
+	}{
+		{LastWins, map[string]any{
+			"page": "3",
+			"sort": map[string]any{"field": "name", "order": "desc"},
+			"tags": []any{"b"},
+			"q":    "go",
+		}},
+		{FirstWins, map[string]any{
+			"page": "1",
+			"sort": map[string]any{"field": "name", "order": "asc"},
+			"tags": []any{"a"},
+			"q":    "go",
+		}},
+		{DeepMerge, map[string]any{
+			"page": []any{"1", "3"},
+			"sort": map[string]any{
+				"field": "name", "order": []any{"asc", "desc"},
+			},
+			"tags": []any{"a", "b"},
+			"q":    "go",
+		}},
+	}
+	for _, tt := range tests {
+		into := mergeDefaults()
+		encoder := NewURLEncoder(WithConflictStrategy(tt.strategy))
+		if err := encoder.DecodeMerge(values, into); err != nil {
+			t.Fatalf("strategy %d: unexpected error: %v", tt.strategy, err)
+		}
+		if !reflect.DeepEqual(into, tt.expected) {
+			t.Errorf(
+				"strategy %d: expected %v, got %v",
+				tt.strategy, tt.expected, into,
+			)
+		}
+	}
+
+	into := mergeDefaults()
+	err := NewURLEncoder(WithCollectErrors()).DecodeMerge(values, into)
+	var conflict ErrConflictingKey
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected ErrConflictingKey, got %v", err)
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 3 {
+		t.Errorf("expected three conflicts, got %v", err)
+	}
+
+	into = map[string]any{"a": "x"}
+	deep := NewURLEncoder(WithConflictStrategy(DeepMerge))
+	if err := deep.DecodeMerge(url.Values{"a.b": {"1"}}, into); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{"a": map[string]any{"": "x", "b": "1"}}
+	if !reflect.DeepEqual(into, expected) {
+		t.Errorf("expected %v, got %v", expected, into)
+	}
+
+	if err := NewURLEncoder().DecodeMerge(values, nil); err == nil {
+		t.Error("expected error for nil map, got nil")
+	}
+}
@@ -0,0 +1,104 @@
+package urlcodec
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// Normalize returns URL values in a normal form for comparing,
+// deduplicating and caching URLs. Keys are rewritten in dot notation with
+// slice indices in brackets, e.g. "user[emails][1]" gives "user.emails[1]",
+// slice indices are compacted in numerically-aware order, e.g. "a[2]" and
+// "a[10]" give "a[0]" and "a[1]", and repeated identical values of a key are
+// removed. Keys that are not valid paths are kept as they are. Use
+// Canonicalize to write the result as a query string with sorted keys and
+// consistent escaping.
+//
+// Parameters:
+//   - values: URL values
+//
+// Returns:
+//   - url.Values: Normalized values
+func Normalize(values url.Values) url.Values {
+	root := &indexTrie{}
+	paths := make(map[string][]pathSegment, len(values))
+	for key := range values {
+		segments, err := parsePath(key)
+		if err != nil {
+			continue
+		}
+		paths[key] = segments
+		root.add(segments)
+	}
+	root.sortIndices()
+	normalized := make(url.Values, len(values))
+	for _, key := range slices.SortedFunc(maps.Keys(values), compareKeys) {
+		newKey := key
+		if segments, ok := paths[key]; ok {
+			newKey = pathString(root.compact(segments))
+		}
+		for _, value := range values[key] {
+			if !slices.Contains(normalized[newKey], value) {
+				normalized[newKey] = append(normalized[newKey], value)
+			}
+		}
+	}
+	return normalized
+}
+
+// indexTrie holds the path segments of keys and the slice indices used
+// under each path.
+type indexTrie struct {
+	children map[string]*indexTrie // Tries of the child segments
+	indices  []string              // Index segments, sorted by sortIndices
+}
+
+// add adds the segments of a key to the trie.
+func (t *indexTrie) add(segments []pathSegment) {
+	for _, segment := range segments {
+		if t.children == nil {
+			t.children = make(map[string]*indexTrie)
+		}
+		child, ok := t.children[segment.name]
+		if !ok {
+			child = &indexTrie{}
+			t.children[segment.name] = child
+		}
+		// The child may have been added by a name segment such as "1" in
+		// "a.1", so the index is added separately and deduplicated by
+		// sortIndices.
+		if segment.index {
+			t.indices = append(t.indices, segment.name)
+		}
+		t = child
+	}
+}
+
+// sortIndices sorts the indices under each path in numerically-aware order
+// and removes duplicates.
+func (t *indexTrie) sortIndices() {
+	slices.SortFunc(t.indices, compareKeys)
+	t.indices = slices.Compact(t.indices)
+	for _, child := range t.children {
+		child.sortIndices()
+	}
+}
+
+// compact returns the segments of a key with each index replaced by its
+// position among the indices under the same path.
+func (t *indexTrie) compact(segments []pathSegment) []pathSegment {
+	compacted := make([]pathSegment, len(segments))
+	for i, segment := range segments {
+		compacted[i] = segment
+		if segment.index {
+			position, _ := slices.BinarySearchFunc(
+				t.indices, segment.name, compareKeys,
+			)
+			compacted[i].name = strconv.Itoa(position)
+		}
+		t = t.children[segment.name]
+	}
+	return compacted
+}
@@ -0,0 +1,55 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestNormalize verifies that keys are rewritten in dot notation, slice
+// indices are compacted per path and duplicate values are removed.
+func TestNormalize(t *testing.T) {
+	values := url.Values{
+		"user[emails][10]": {"b@example.com"},
+		"user.emails[2]":   {"a@example.com"},
+		"items[5].sku":     {"x"},
+		"items[5].tags[7]": {"t"},
+		"items[9].sku":     {"y"},
+		"q":                {"go", "go", "rust", "go"},
+		"a[]":              {"1"},
+	}
+	expected := url.Values{
+		"user.emails[0]":   {"a@example.com"},
+		"user.emails[1]":   {"b@example.com"},
+		"items[0].sku":     {"x"},
+		"items[0].tags[0]": {"t"},
+		"items[1].sku":     {"y"},
+		"q":                {"go", "rust"},
+		"a[]":              {"1"},
+	}
+	got := Normalize(values)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if again := Normalize(got); !reflect.DeepEqual(again, got) {
+		t.Errorf("expected normalizing twice to be stable, got %v", again)
+	}
+	if Canonicalize(Normalize(url.Values{"a[b]": {"1"}})) !=
+		Canonicalize(Normalize(url.Values{"a.b": {"1", "1"}})) {
+		t.Error("expected equal canonical forms")
+	}
+}
+
+// TestNormalize_MixedIndices verifies that an index is compacted even when
+// the same segment is also used as a field name, in any order of the keys.
+func TestNormalize_MixedIndices(t *testing.T) {
+	values := url.Values{"a.1": {"x"}, "a[1]": {"y"}, "a[3]": {"z"}}
+	expected := url.Values{"a.1": {"x"}, "a[0]": {"y"}, "a[1]": {"z"}}
+	// Keys are added to the index trie in map order, so several runs cover
+	// both orders.
+	for range 10 {
+		if got := Normalize(values); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package urlcodec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Notation selects how nested map keys and struct fields are written in
+// URL keys.
+type Notation int
+
+const (
+	// DotNotation joins nested keys with dots, e.g. "user.address.street".
+	DotNotation Notation = iota
+	// BracketNotation wraps nested keys in brackets, e.g.
+	// "user[address][street]".
+	BracketNotation
+)
+
+// DelimiterKeys selects how Encode handles map keys that contain the
+// characters used to separate nested keys: ".", "[" and "]". Without
+// handling, the map key "a.b" would decode as the nested keys "a" and "b".
+type DelimiterKeys int
+
+const (
+	// RejectDelimiterKeys returns an error for map keys that contain
+	// delimiters.
+	RejectDelimiterKeys DelimiterKeys = iota
+	// EscapeDelimiterKeys escapes delimiters in map keys as "%2E", "%5B"
+	// and "%5D", and "%" as "%25", e.g. "a.b" is written as "a%2Eb".
+	// Decode and DecodeInto unescape the keys.
+	EscapeDelimiterKeys
+	// AllowDelimiterKeys writes map keys as they are, so that delimiters
+	// in them create nested keys when decoding.
+	AllowDelimiterKeys
+)
+
+// keyEscapes maps characters that are escaped in map keys to their escapes.
+var keyEscapes = map[byte]string{
+	'.': "%2E", '[': "%5B", ']': "%5D", '%': "%25",
+}
+
+// escapeMapKey handles delimiters in a map key as configured with
+// WithDelimiterKeys.
+func (e *URLEncoder) escapeMapKey(key string) (string, error) {
+	switch e.delimiterKeys {
+	case AllowDelimiterKeys:
+		return key, nil
+	case EscapeDelimiterKeys:
+		if !strings.ContainsAny(key, ".[]%") {
+			return key, nil
+		}
+		var b strings.Builder
+		for i := 0; i < len(key); i++ {
+			if escape, ok := keyEscapes[key[i]]; ok {
+				b.WriteString(escape)
+			} else {
+				b.WriteByte(key[i])
+			}
+		}
+		return b.String(), nil
+	}
+	if strings.ContainsAny(key, e.keyDelimiters()) {
+		return "", fmt.Errorf("map key %q contains a key delimiter", key)
+	}
+	return key, nil
+}
+
+// keyDelimiters returns the characters that separate nested keys. Dots
+// are not delimiters with WithQSCompat unless AllowDots is set.
+func (e *URLEncoder) keyDelimiters() string {
+	if e.qs != nil && !e.qs.AllowDots {
+		return "[]"
+	}
+	return ".[]"
+}
+
+// unescapeMapKey reverses escapeMapKey if delimiters in map keys are
+// escaped. Other percent signs are kept as they are.
+func (e *URLEncoder) unescapeMapKey(key string) string {
+	if e.delimiterKeys != EscapeDelimiterKeys ||
+		!strings.Contains(key, "%") {
+		return key
+	}
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == '%' && i+2 < len(key) {
+			if c, ok := keyUnescape(key[i+1 : i+3]); ok {
+				b.WriteByte(c)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(key[i])
+	}
+	return b.String()
+}
+
+// keyUnescape returns the character escaped by the two hex digits after a
+// "%" in a map key.
+func keyUnescape(hex string) (byte, bool) {
+	for c, escape := range keyEscapes {
+		if strings.EqualFold(escape[1:], hex) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// joinKey joins a parent key and a child name using the configured
+// notation.
+func (e *URLEncoder) joinKey(fieldTag string, name string) string {
+	if fieldTag == "" {
+		return name
+	}
+	if e.notation == BracketNotation {
+		return fieldTag + "[" + name + "]"
+	}
+	return fieldTag + "." + name
+}
+
+// normalizeKey rewrites a key written in the configured notation to dot
+// notation, which is used internally when decoding. In bracket notation,
+// numeric and empty segments are kept as slice indices, e.g.
+// "items[0][sku]" gives "items[0].sku". Keys with unbalanced brackets are
+// returned unchanged.
+func (e *URLEncoder) normalizeKey(key string) string {
+	if e.notation != BracketNotation {
+		return key
+	}
+	root, rest, found := strings.Cut(key, "[")
+	if !found {
+		return key
+	}
+	var b strings.Builder
+	b.WriteString(root)
+	for rest != "" {
+		segment, after, ok := strings.Cut(rest, "]")
+		if !ok || strings.Contains(segment, "[") {
+			return key
+		}
+		if segment == "" || isIndex(segment) {
+			b.WriteString("[" + segment + "]")
+		} else {
+			b.WriteString("." + segment)
+		}
+		if after == "" {
+			break
+		}
+		if after[0] != '[' {
+			return key
+		}
+		rest = after[1:]
+	}
+	return b.String()
+}
+
+// isIndex reports whether s is a non-empty string of decimal digits.
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestBracketNotation_Encode verifies that nested keys are wrapped in
+// brackets in bracket notation.
+func TestBracketNotation_Encode(t *testing.T) {
+	encoder := NewURLEncoder(WithNotation(BracketNotation))
+	values, err := encoder.Encode(map[string]any{
+		"user": map[string]any{
+			"address": map[string]any{"street": "Main St"},
+			"tags":    []string{"a"},
+			"items":   []map[string]any{{"sku": "x"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"user[address][street]": {"Main St"},
+		"user[tags][0]":         {"a"},
+		"user[items][0][sku]":   {"x"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+// TestBracketNotation_Decode verifies that bracket keys decode to nested
+// maps and slices.
+func TestBracketNotation_Decode(t *testing.T) {
+	encoder := NewURLEncoder(WithNotation(BracketNotation))
+	values := url.Values{}
+	values.Set("user[address][street]", "Main St")
+	values.Set("user[tags][0]", "a")
+	values.Set("user[items][0][sku]", "x")
+	values.Set("plain", "p")
+
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"user": map[string]any{
+			"address": map[string]any{"street": "Main St"},
+			"tags":    []any{"a"},
+			"items":   []any{map[string]any{"sku": "x"}},
+		},
+		"plain": "p",
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+}
+
+// TestBracketNotation_RoundTrip verifies that a struct round-trips through
+// bracket notation.
+func TestBracketNotation_RoundTrip(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type User struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+	encoder := NewURLEncoder(WithNotation(BracketNotation))
+	original := User{Name: "Ada", Address: Address{Street: "Main St"}}
+	values, err := encoder.Encode(map[string]any{"user": original})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded struct {
+		User User `json:"user"`
+	}
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.User != original {
+		t.Errorf("expected %+v, got %+v", original, decoded.User)
+	}
+}
+
+// TestWithDelimiterKeys verifies that map keys containing delimiters are
+// rejected by default, escaped on request or written as they are.
+func TestWithDelimiterKeys(t *testing.T) {
+	data := map[string]any{
+		"a.b":  1,
+		"c":    map[string]any{"[x]": "y", "50%": "z"},
+		"d.e":  []any{"f"},
+		"%2E":  "literal",
+		"plan": "ok",
+	}
+	if _, err := NewURLEncoder().Encode(data); err == nil {
+		t.Fatal("expected error for delimiter in map key, got nil")
+	}
+
+	expected := map[string]any{
+		"a.b":  "1",
+		"c":    map[string]any{"[x]": "y", "50%": "z"},
+		"d.e":  []any{"f"},
+		"%2E":  "literal",
+		"plan": "ok",
+	}
+	for _, opts := range [][]Option{
+		{WithDelimiterKeys(EscapeDelimiterKeys)},
+		{
+			WithDelimiterKeys(EscapeDelimiterKeys),
+			WithNotation(BracketNotation),
+		},
+		{
+			WithDelimiterKeys(EscapeDelimiterKeys),
+			WithQSCompat(QSOptions{AllowDots: true}),
+		},
+	} {
+		encoder := NewURLEncoder(opts...)
+		values, err := encoder.Encode(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := encoder.Decode(values)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v from %v", expected, got, values)
+		}
+	}
+
+	encoder := NewURLEncoder(WithDelimiterKeys(AllowDelimiterKeys))
+	values, err := encoder.Encode(map[string]any{"a.b": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values.Get("a.b") != "1" {
+		t.Errorf("expected a.b=1, got %v", values)
+	}
+
+	qs := NewURLEncoder(WithQSCompat(QSOptions{}))
+	if _, err := qs.Encode(map[string]any{"a.b": 1}); err != nil {
+		t.Errorf("unexpected error for dot in qs mode: %v", err)
+	}
+	if _, err := qs.Encode(map[string]any{"a[b]": 1}); err == nil {
+		t.Error("expected error for bracket in qs mode, got nil")
+	}
+}
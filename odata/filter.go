@@ -0,0 +1,510 @@
+package odata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxDepth is the deepest nesting of parentheses and "not" operators that
+// ParseFilter accepts.
+const maxDepth = 32
+
+// Operator is a comparison or logical operator of a filter expression.
+type Operator string
+
+const (
+	// Eq matches values equal to the literal.
+	Eq Operator = "eq"
+	// Ne matches values not equal to the literal.
+	Ne Operator = "ne"
+	// Gt matches values greater than the literal.
+	Gt Operator = "gt"
+	// Ge matches values greater than or equal to the literal.
+	Ge Operator = "ge"
+	// Lt matches values less than the literal.
+	Lt Operator = "lt"
+	// Le matches values less than or equal to the literal.
+	Le Operator = "le"
+	// In matches values equal to one of the literals of a list.
+	In Operator = "in"
+	// And matches when both operands match.
+	And Operator = "and"
+	// Or matches when either operand matches.
+	Or Operator = "or"
+)
+
+// Expr is a node of a filter expression: a *Comparison, *Logical, *Not or
+// *Call. Use a type switch to walk it.
+type Expr interface {
+	fmt.Stringer
+	expr()
+}
+
+// Comparison compares a property with a literal, e.g. "Price gt 10". The
+// value is a string, int64, float64, bool or nil for null literals, or a
+// []any of those for the In operator.
+type Comparison struct {
+	Field    string   // Property path in dot notation
+	Operator Operator // Eq, Ne, Gt, Ge, Lt, Le or In
+	Value    any      // Literal value
+}
+
+// Logical combines two expressions with And or Or.
+type Logical struct {
+	Operator Operator // And or Or
+	Left     Expr     // Left operand
+	Right    Expr     // Right operand
+}
+
+// Not negates an expression.
+type Not struct {
+	Expr Expr // Negated expression
+}
+
+// Call is a call of a string function with a property and a string
+// literal, e.g. "contains(Name,'abc')".
+type Call struct {
+	Function string // "contains", "startswith" or "endswith"
+	Field    string // Property path in dot notation
+	Value    string // String argument
+}
+
+func (*Comparison) expr() {}
+func (*Logical) expr()    {}
+func (*Not) expr()        {}
+func (*Call) expr()       {}
+
+// String returns the comparison in OData syntax.
+//
+// Returns:
+//   - string: Expression
+func (c *Comparison) String() string {
+	return odataPath(c.Field) + " " + string(c.Operator) + " " +
+		formatLiteral(c.Value)
+}
+
+// String returns the logical expression in OData syntax, with "or"
+// operands of "and" in parentheses.
+//
+// Returns:
+//   - string: Expression
+func (l *Logical) String() string {
+	left, right := l.Left.String(), l.Right.String()
+	if l.Operator == And {
+		left, right = groupOr(l.Left, left), groupOr(l.Right, right)
+	}
+	return left + " " + string(l.Operator) + " " + right
+}
+
+// String returns the negation in OData syntax.
+//
+// Returns:
+//   - string: Expression
+func (n *Not) String() string {
+	if _, ok := n.Expr.(*Logical); ok {
+		return "not (" + n.Expr.String() + ")"
+	}
+	return "not " + n.Expr.String()
+}
+
+// String returns the call in OData syntax.
+//
+// Returns:
+//   - string: Expression
+func (c *Call) String() string {
+	return c.Function + "(" + odataPath(c.Field) + "," +
+		formatLiteral(c.Value) + ")"
+}
+
+// groupOr puts the string of an "or" expression in parentheses.
+func groupOr(e Expr, s string) string {
+	if l, ok := e.(*Logical); ok && l.Operator == Or {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// odataPath converts a property path in dot notation back to OData.
+func odataPath(s string) string {
+	return strings.ReplaceAll(s, ".", "/")
+}
+
+// formatLiteral writes a literal value in OData syntax.
+func formatLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatLiteral(item)
+		}
+		return "(" + strings.Join(items, ",") + ")"
+	}
+	return fmt.Sprint(v)
+}
+
+// ParseFilter parses a $filter option such as
+// "Price gt 10 and (Status eq 'open' or contains(Name,'x'))". Operators
+// bind from "not" over "and" to "or". Keywords are lowercase as in OData.
+//
+// Parameters:
+//   - s: $filter option
+//
+// Returns:
+//   - Expr: Expression tree
+//   - error: Error wrapping ErrInvalidQuery
+func ParseFilter(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t)
+	}
+	return expr, nil
+}
+
+// tokenKind is the kind of a filter token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+// token is a token of a filter expression.
+type token struct {
+	kind   tokenKind // Kind of token
+	text   string    // Source text, unquoted for strings
+	offset int       // Byte offset in the expression
+}
+
+// lex splits a filter expression into tokens.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, token{tokenOpen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenClose, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case c == '\'':
+			var b strings.Builder
+			for i++; ; i++ {
+				if i == len(s) {
+					return nil, fmt.Errorf(
+						"%w: $filter: unterminated string at offset %d",
+						ErrInvalidQuery, start,
+					)
+				}
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						i++
+					} else {
+						break
+					}
+				}
+				b.WriteByte(s[i])
+			}
+			i++
+			tokens = append(tokens, token{tokenString, b.String(), start})
+		case isDigit(c) || c == '-' && i+1 < len(s) && isDigit(s[i+1]):
+			for i++; i < len(s) && isNumberPart(s, i); i++ {
+			}
+			tokens = append(tokens, token{tokenNumber, s[start:i], start})
+		case isIdentStart(c):
+			for i++; i < len(s) && (isIdentPart(s[i]) || s[i] == '/'); i++ {
+			}
+			tokens = append(tokens, token{tokenIdent, s[start:i], start})
+		default:
+			return nil, fmt.Errorf(
+				"%w: $filter: unexpected %q at offset %d",
+				ErrInvalidQuery, c, i,
+			)
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(s)}), nil
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isNumberPart reports whether the byte at i continues a number: a digit,
+// a decimal point, an exponent or the sign of an exponent.
+func isNumberPart(s string, i int) bool {
+	switch c := s[i]; c {
+	case '.', 'e', 'E':
+		return true
+	case '+', '-':
+		return s[i-1] == 'e' || s[i-1] == 'E'
+	default:
+		return isDigit(c)
+	}
+}
+
+// filterParser parses filter tokens by recursive descent.
+type filterParser struct {
+	tokens []token // Tokens ending with tokenEOF
+	pos    int     // Position of the next token
+}
+
+// peek returns the next token without consuming it.
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consumes and returns the next token.
+func (p *filterParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the given keyword.
+func (p *filterParser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenIdent && t.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the next token, which must be of the given kind.
+func (p *filterParser) expect(kind tokenKind) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.unexpected(t)
+	}
+	return t, nil
+}
+
+// unexpected returns the error for an unexpected token.
+func (p *filterParser) unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf(
+			"%w: $filter: unexpected end of expression", ErrInvalidQuery,
+		)
+	}
+	return fmt.Errorf(
+		"%w: $filter: unexpected %q at offset %d",
+		ErrInvalidQuery, t.text, t.offset,
+	)
+}
+
+// parseOr parses operands joined by "or".
+func (p *filterParser) parseOr(depth int) (Expr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(string(Or)) {
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Operator: Or, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses operands joined by "and".
+func (p *filterParser) parseAnd(depth int) (Expr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(string(And)) {
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Operator: And, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses a negation, a parenthesized expression, a function
+// call or a comparison.
+func (p *filterParser) parseUnary(depth int) (Expr, error) {
+	if depth >= maxDepth {
+		return nil, fmt.Errorf(
+			"%w: $filter: nesting deeper than %d", ErrInvalidQuery, maxDepth,
+		)
+	}
+	if p.keyword("not") {
+		expr, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr}, nil
+	}
+	if p.peek().kind == tokenOpen {
+		p.next()
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenClose); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+	name, err := p.expect(tokenIdent)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokenOpen {
+		return p.parseCall(name)
+	}
+	return p.parseComparison(name)
+}
+
+// parseCall parses the arguments of a string function.
+func (p *filterParser) parseCall(name token) (Expr, error) {
+	switch name.text {
+	case "contains", "startswith", "endswith":
+	default:
+		return nil, fmt.Errorf(
+			"%w: $filter: unsupported function %q at offset %d",
+			ErrInvalidQuery, name.text, name.offset,
+		)
+	}
+	p.next()
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenComma); err != nil {
+		return nil, err
+	}
+	value, err := p.expect(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenClose); err != nil {
+		return nil, err
+	}
+	return &Call{Function: name.text, Field: field, Value: value.text}, nil
+}
+
+// parseComparison parses the operator and literal of a comparison.
+func (p *filterParser) parseComparison(name token) (Expr, error) {
+	if !isPath(name.text) {
+		return nil, p.unexpected(name)
+	}
+	op, err := p.expect(tokenIdent)
+	if err != nil {
+		return nil, err
+	}
+	c := &Comparison{Field: fieldPath(name.text), Operator: Operator(op.text)}
+	switch c.Operator {
+	case Eq, Ne, Gt, Ge, Lt, Le:
+		c.Value, err = p.parseLiteral()
+	case In:
+		c.Value, err = p.parseList()
+	default:
+		return nil, p.unexpected(op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseField parses a property path.
+func (p *filterParser) parseField() (string, error) {
+	t, err := p.expect(tokenIdent)
+	if err != nil {
+		return "", err
+	}
+	if !isPath(t.text) {
+		return "", p.unexpected(t)
+	}
+	return fieldPath(t.text), nil
+}
+
+// parseList parses a parenthesized, comma-separated list of literals.
+func (p *filterParser) parseList() ([]any, error) {
+	if _, err := p.expect(tokenOpen); err != nil {
+		return nil, err
+	}
+	var list []any
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		t := p.next()
+		if t.kind == tokenClose {
+			return list, nil
+		}
+		if t.kind != tokenComma {
+			return nil, p.unexpected(t)
+		}
+	}
+}
+
+// parseLiteral parses a string, number, boolean or null literal.
+func (p *filterParser) parseLiteral() (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return t.text, nil
+	case tokenNumber:
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf(
+			"%w: $filter: invalid number %q at offset %d",
+			ErrInvalidQuery, t.text, t.offset,
+		)
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	}
+	return nil, p.unexpected(t)
+}
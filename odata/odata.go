@@ -0,0 +1,161 @@
+// Package odata parses a pragmatic subset of the OData query options into
+// an AST: $filter with comparison, "in" and logical operators and the
+// contains, startswith and endswith functions, $orderby, $top and $skip.
+// Other options, such as $select and $expand, are ignored.
+//
+// Property paths such as "Address/City" are returned in the dot notation
+// of urlcodec paths, "Address.City".
+package odata
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aatuh/urlcodec"
+)
+
+// ErrInvalidQuery is returned when an OData query option is malformed or
+// uses syntax outside of the supported subset.
+var ErrInvalidQuery = errors.New("invalid OData query")
+
+// Query holds the parsed OData query options of a request.
+type Query struct {
+	Filter  Expr          // $filter expression, nil if none
+	OrderBy urlcodec.Sort // $orderby fields in order of precedence
+	Top     int           // $top, 0 if none
+	Skip    int           // $skip, 0 if none
+}
+
+// Parse parses the $filter, $orderby, $top and $skip options of URL
+// values. Each option may be given once.
+//
+// Parameters:
+//   - values: URL values
+//
+// Returns:
+//   - Query: Parsed query options
+//   - error: Error wrapping ErrInvalidQuery
+func Parse(values url.Values) (Query, error) {
+	var q Query
+	var err error
+	for _, key := range []string{"$filter", "$orderby", "$top", "$skip"} {
+		if len(values[key]) > 1 {
+			return Query{}, fmt.Errorf("%w: repeated %s", ErrInvalidQuery, key)
+		}
+	}
+	if s := values.Get("$filter"); s != "" {
+		if q.Filter, err = ParseFilter(s); err != nil {
+			return Query{}, err
+		}
+	}
+	if q.OrderBy, err = ParseOrderBy(values.Get("$orderby")); err != nil {
+		return Query{}, err
+	}
+	if q.Top, err = parseCount(values, "$top"); err != nil {
+		return Query{}, err
+	}
+	if q.Skip, err = parseCount(values, "$skip"); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
+
+// Pagination returns $top and $skip as the limit and offset of a
+// urlcodec.Pagination, so that they can be checked with its Apply method.
+//
+// Returns:
+//   - urlcodec.Pagination: Pagination with Limit and Offset set
+func (q Query) Pagination() urlcodec.Pagination {
+	return urlcodec.Pagination{Limit: q.Top, Offset: q.Skip}
+}
+
+// parseCount parses a non-negative integer option.
+func parseCount(values url.Values, key string) (int, error) {
+	s := values.Get(key)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf(
+			"%w: %s must be a non-negative integer, got %q",
+			ErrInvalidQuery, key, s,
+		)
+	}
+	return n, nil
+}
+
+// ParseOrderBy parses an $orderby option such as "Name desc,Address/City".
+// Each property may be followed by "asc" or "desc".
+//
+// Parameters:
+//   - s: $orderby option
+//
+// Returns:
+//   - urlcodec.Sort: Sort fields
+//   - error: Error wrapping ErrInvalidQuery
+func ParseOrderBy(s string) (urlcodec.Sort, error) {
+	var sort urlcodec.Sort
+	if strings.TrimSpace(s) == "" {
+		return sort, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 || !isPath(words[0]) {
+			return nil, fmt.Errorf(
+				"%w: $orderby: invalid item %q", ErrInvalidQuery, part,
+			)
+		}
+		field := urlcodec.SortField{Field: fieldPath(words[0])}
+		if len(words) == 2 {
+			switch words[1] {
+			case "asc":
+			case "desc":
+				field.Direction = urlcodec.Descending
+			default:
+				return nil, fmt.Errorf(
+					"%w: $orderby: invalid direction %q",
+					ErrInvalidQuery, words[1],
+				)
+			}
+		}
+		sort = append(sort, field)
+	}
+	if err := sort.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: $orderby: %w", ErrInvalidQuery, err)
+	}
+	return sort, nil
+}
+
+// isPath reports whether s is a property path such as "Address/City".
+func isPath(s string) bool {
+	for _, segment := range strings.Split(s, "/") {
+		if segment == "" || !isIdentStart(segment[0]) {
+			return false
+		}
+		for i := 1; i < len(segment); i++ {
+			if !isIdentPart(segment[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fieldPath converts an OData property path to dot notation.
+func fieldPath(s string) string {
+	return strings.ReplaceAll(s, "/", ".")
+}
+
+// isIdentStart reports whether c may start an identifier.
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// isIdentPart reports whether c may continue an identifier.
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || '0' <= c && c <= '9'
+}
@@ -0,0 +1,127 @@
+package odata
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/aatuh/urlcodec"
+)
+
+// TestParse verifies that the supported query options are parsed and
+// others are ignored.
+func TestParse(t *testing.T) {
+	values, err := url.ParseQuery(
+		"$filter=Price+gt+10+and+Address/City+eq+'Oslo'" +
+			"&$orderby=Name+desc,Id&$top=5&$skip=10&$select=Name",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q, err := Parse(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter := &Logical{
+		Operator: And,
+		Left:     &Comparison{Field: "Price", Operator: Gt, Value: int64(10)},
+		Right: &Comparison{
+			Field: "Address.City", Operator: Eq, Value: "Oslo",
+		},
+	}
+	if !reflect.DeepEqual(q.Filter, filter) {
+		t.Errorf("expected %v, got %v", filter, q.Filter)
+	}
+	orderBy := urlcodec.Sort{
+		{Field: "Name", Direction: urlcodec.Descending},
+		{Field: "Id", Direction: urlcodec.Ascending},
+	}
+	if !reflect.DeepEqual(q.OrderBy, orderBy) {
+		t.Errorf("expected %v, got %v", orderBy, q.OrderBy)
+	}
+	if q.Top != 5 || q.Skip != 10 {
+		t.Errorf("expected top 5 and skip 10, got %d and %d", q.Top, q.Skip)
+	}
+	p := q.Pagination()
+	if p.Limit != 5 || p.Offset != 10 {
+		t.Errorf("expected limit 5 and offset 10, got %+v", p)
+	}
+
+	for _, query := range []string{
+		"$top=-1", "$skip=x", "$top=1&$top=2", "$orderby=Name+up",
+		"$orderby=Name,Name", "$filter=Name",
+	} {
+		values, _ := url.ParseQuery(query)
+		if _, err := Parse(values); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", query, err)
+		}
+	}
+}
+
+// TestParseFilter verifies operator precedence, literals and functions.
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{"a eq 1 or b eq 2 and c eq 3", "a eq 1 or b eq 2 and c eq 3"},
+		{"(a eq 1 or b eq 2) and c eq 3", "(a eq 1 or b eq 2) and c eq 3"},
+		{"not (a eq 1 and b ne -2.5)", "not (a eq 1 and b ne -2.5)"},
+		{"not a eq true", "not a eq true"},
+		{"Name eq 'O''Brien'", "Name eq 'O''Brien'"},
+		{"Deleted eq null", "Deleted eq null"},
+		{"Id in (1, 2,3)", "Id in (1,2,3)"},
+		{"contains(Tags/Name,'go') or x ge 1e3", "contains(Tags/Name,'go') " +
+			"or x ge 1000"},
+	}
+	for _, tt := range tests {
+		expr, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.filter, err)
+			continue
+		}
+		if got := expr.String(); got != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.filter, tt.expected, got)
+		}
+	}
+
+	expr, err := ParseFilter("a eq 1 or b eq 2 and c eq 3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	or, ok := expr.(*Logical)
+	if !ok || or.Operator != Or {
+		t.Fatalf("expected or at the root, got %v", expr)
+	}
+	if and, ok := or.Right.(*Logical); !ok || and.Operator != And {
+		t.Errorf("expected and on the right, got %v", or.Right)
+	}
+
+	call, err := ParseFilter("startswith(Name,'Jo')")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Call{Function: "startswith", Field: "Name", Value: "Jo"}
+	if !reflect.DeepEqual(call, expected) {
+		t.Errorf("expected %v, got %v", expected, call)
+	}
+}
+
+// TestParseFilter_Invalid verifies that malformed and unsupported filters
+// are rejected.
+func TestParseFilter_Invalid(t *testing.T) {
+	deep := ""
+	for range maxDepth + 1 {
+		deep += "("
+	}
+	for _, filter := range []string{
+		"", "a", "a eq", "a like 'x'", "a eq 'x", "a eq 1 and",
+		"(a eq 1", "a eq 1)", "length(a,'x')", "a eq 2020-01-01",
+		"a in ()", "a eq b", "a eq 1 # b", deep + "a eq 1",
+	} {
+		if _, err := ParseFilter(filter); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", filter, err)
+		}
+	}
+}
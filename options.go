@@ -0,0 +1,759 @@
+package urlcodec
+
+import "reflect"
+
+// Option configures a URLEncoder.
+type Option func(*URLEncoder)
+
+// WithMaxDepth sets the maximum number of nested key segments accepted when
+// decoding, e.g. "a.b.c" has three segments. The default is 10. A value of 0
+// or less disables the limit.
+//
+// Parameters:
+//   - n: Maximum depth
+//
+// Returns:
+//   - Option: The option
+func WithMaxDepth(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxDepth = n
+	}
+}
+
+// WithMaxDecodedBytes sets the approximate number of bytes that decoding may
+// use to build the decoded data. Each value is counted by the length of its
+// key and value and a fixed overhead per key segment. Decoding stops with an
+// ErrMaxDecodedBytes as soon as the limit is exceeded, even when errors are
+// collected. A value of 0 or less disables the limit, which is the default.
+//
+// Parameters:
+//   - n: Maximum number of bytes
+//
+// Returns:
+//   - Option: The option
+func WithMaxDecodedBytes(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxDecoded = n
+	}
+}
+
+// WithMaxKeys sets the maximum number of keys accepted when decoding. Keys
+// that are expanded to several indexed keys, e.g. repeated keys, count once
+// per index. Decoding stops with an ErrMaxKeys as soon as the limit is
+// exceeded, even when errors are collected. Keys dropped by WithPrefix,
+// WithAllowedKeys or WithDeniedKeys still count. A value of 0 or less
+// disables the limit, which is the default.
+//
+// Parameters:
+//   - n: Maximum number of keys
+//
+// Returns:
+//   - Option: The option
+func WithMaxKeys(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxKeys = n
+	}
+}
+
+// WithMaxKeyLength sets the maximum length in bytes of a decoded key.
+// Decoding stops with an ErrKeyLimit naming the key if a key is longer. A
+// value of 0 or less disables the limit, which is the default.
+//
+// Parameters:
+//   - n: Maximum key length
+//
+// Returns:
+//   - Option: The option
+func WithMaxKeyLength(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxKeyLength = n
+	}
+}
+
+// WithMaxKeySegments sets the maximum number of segments of a decoded key,
+// counting both object keys and slice indices, e.g. "a.b[0][1]" has four
+// segments. Unlike WithMaxDepth, which only counts object keys, it bounds
+// the work done for every key. Decoding stops with an ErrKeyLimit naming the
+// key if a key has more segments. A value of 0 or less disables the limit,
+// which is the default.
+//
+// Parameters:
+//   - n: Maximum number of segments
+//
+// Returns:
+//   - Option: The option
+func WithMaxKeySegments(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxSegments = n
+	}
+}
+
+// WithTrimSpace makes decoding trim leading and trailing white space, as
+// defined by Unicode, from values, e.g. stray spaces from copy-pasted links.
+// Values that become empty are then empty values, e.g. for WithEmptyAsNull.
+//
+// Returns:
+//   - Option: The option
+func WithTrimSpace() Option {
+	return func(e *URLEncoder) {
+		e.trimValues = true
+	}
+}
+
+// WithTrimKeySpace makes decoding trim leading and trailing white space
+// from whole keys, like WithTrimSpace does for values. Keys that become
+// equal are merged as for a repeated key. Spaces inside keys, e.g. around
+// dots, are kept.
+//
+// Returns:
+//   - Option: The option
+func WithTrimKeySpace() Option {
+	return func(e *URLEncoder) {
+		e.trimKeys = true
+	}
+}
+
+// WithUnicodeNormalization makes decoding normalize keys and values to a
+// Unicode normalization form, e.g. WithUnicodeNormalization(norm.NFC) with
+// the golang.org/x/text/unicode/norm package, so that visually identical
+// keys such as a precomposed "é" and "e" followed by a combining accent
+// become one key. Their values are merged as for a repeated key.
+// Normalization is applied before the prefix is removed and keys are
+// parsed. This package does not depend on x/text, so the normalizer is
+// passed in; any type with a String(string) string method works.
+//
+// Parameters:
+//   - normalizer: Unicode normalizer
+//
+// Returns:
+//   - Option: The option
+func WithUnicodeNormalization(normalizer UnicodeNormalizer) Option {
+	return func(e *URLEncoder) {
+		e.normalizer = normalizer
+	}
+}
+
+// WithMaxDecompressedSize sets the maximum size in bytes of the query string
+// that DecodeCompressed decompresses. Larger values are rejected with an
+// ErrMaxDecompressedSize. A value of 0 or less uses the default of 1 MiB.
+//
+// Parameters:
+//   - n: Maximum decompressed size
+//
+// Returns:
+//   - Option: The option
+func WithMaxDecompressedSize(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxInflated = n
+	}
+}
+
+// WithDoubleEncoding sets how decoding treats values that appear to be
+// percent-encoded twice by a misbehaving client, i.e. that still contain an
+// escape such as "%20" after the query was parsed. DoubleEncodingFix
+// decodes those escapes and DoubleEncodingReject rejects the value with an
+// ErrDoubleEncoded naming its key, which is collected like other invalid
+// values with WithCollectErrors. Keys are not checked. The default is
+// DoubleEncodingAllow, as a literal "%" followed by two hex digits can be
+// genuine input.
+//
+// Parameters:
+//   - mode: Double encoding mode
+//
+// Returns:
+//   - Option: The option
+func WithDoubleEncoding(mode DoubleEncoding) Option {
+	return func(e *URLEncoder) {
+		e.doubleEncoding = mode
+	}
+}
+
+// WithMaxValueLength sets the maximum length in bytes of a decoded value.
+// Longer values are rejected with an ErrMaxValueLength naming their key,
+// which is collected like other invalid values with WithCollectErrors. A
+// value of 0 or less disables the limit, which is the default.
+//
+// Parameters:
+//   - n: Maximum value length
+//
+// Returns:
+//   - Option: The option
+func WithMaxValueLength(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxValueLength = n
+	}
+}
+
+// WithTagName sets the struct tag used to name fields when encoding and
+// decoding structs. The default is "json".
+//
+// Parameters:
+//   - name: Tag name, e.g. "url"
+//
+// Returns:
+//   - Option: The option
+func WithTagName(name string) Option {
+	return func(e *URLEncoder) {
+		e.tagName = name
+	}
+}
+
+// WithTimeFormat sets the layout used to encode and decode time.Time
+// values. The default is time.RFC3339.
+//
+// Parameters:
+//   - layout: Layout as accepted by time.Time.Format
+//
+// Returns:
+//   - Option: The option
+func WithTimeFormat(layout string) Option {
+	return func(e *URLEncoder) {
+		e.timeFormat = layout
+	}
+}
+
+// WithTimeEpoch makes time.Time values encode and decode as Unix seconds or
+// milliseconds instead of layout strings. Decoded times are in UTC. Struct
+// fields can override it with the "unix" and "unixmilli" tag options.
+//
+// Parameters:
+//   - epoch: Time epoch, NoEpoch to use the time layout
+//
+// Returns:
+//   - Option: The option
+func WithTimeEpoch(epoch TimeEpoch) Option {
+	return func(e *URLEncoder) {
+		e.timeEpoch = epoch
+	}
+}
+
+// WithDurationFormat sets the format used to encode and decode
+// time.Duration values. The default is DurationString.
+//
+// Parameters:
+//   - format: Duration format
+//
+// Returns:
+//   - Option: The option
+func WithDurationFormat(format DurationFormat) Option {
+	return func(e *URLEncoder) {
+		e.durationFormat = format
+	}
+}
+
+// WithBytesFormat sets the format used to encode and decode []byte and
+// [N]byte values. The default is BytesBase64. Struct fields can override it
+// with the "hex" or "base64" tag options.
+//
+// Parameters:
+//   - format: Bytes format
+//
+// Returns:
+//   - Option: The option
+func WithBytesFormat(format BytesFormat) Option {
+	return func(e *URLEncoder) {
+		e.bytesFormat = format
+	}
+}
+
+// WithBoolFormat sets the format used to encode bool values. The default is
+// BoolTrueFalse. DecodeInto accepts the tokens of the format in addition to
+// the values accepted by strconv.ParseBool. Type hints always use "true" and
+// "false".
+//
+// Parameters:
+//   - format: Bool format
+//
+// Returns:
+//   - Option: The option
+func WithBoolFormat(format BoolFormat) Option {
+	return func(e *URLEncoder) {
+		e.boolFormat = format
+	}
+}
+
+// WithBoolTokens sets additional tokens that DecodeInto accepts as true and
+// false for bool values, e.g. "y" and "n". Tokens are compared
+// case-insensitively.
+//
+// Parameters:
+//   - truthy: Tokens decoded as true
+//   - falsey: Tokens decoded as false
+//
+// Returns:
+//   - Option: The option
+func WithBoolTokens(truthy []string, falsey []string) Option {
+	return func(e *URLEncoder) {
+		e.truthy = truthy
+		e.falsey = falsey
+	}
+}
+
+// KeyTransformer rewrites the name of a map key or struct field when
+// encoding. The path holds the already transformed segments of the parent
+// key, with slice indices as separate segments, e.g. "items[0]" gives
+// "items" and "0". The returned name replaces the key name.
+type KeyTransformer func(path []string, key string) string
+
+// WithKeyTransformer sets a function that rewrites generated key names when
+// encoding, e.g. to convert them to snake_case or kebab-case.
+//
+// Parameters:
+//   - fn: Key transformer
+//
+// Returns:
+//   - Option: The option
+func WithKeyTransformer(fn KeyTransformer) Option {
+	return func(e *URLEncoder) {
+		e.keyTransformer = fn
+	}
+}
+
+// ValueTransformer rewrites or rejects a value when encoding. It is called
+// with the full key and the value just before the value is set.
+type ValueTransformer func(key string, value string) (string, error)
+
+// WithValueTransformer sets a function that is applied to every value when
+// encoding, e.g. to trim, normalize or validate values. An error returned
+// by the function aborts encoding.
+//
+// Parameters:
+//   - fn: Value transformer
+//
+// Returns:
+//   - Option: The option
+func WithValueTransformer(fn ValueTransformer) Option {
+	return func(e *URLEncoder) {
+		e.valueTransformer = fn
+	}
+}
+
+// DecodeHook converts a decoded string before DecodeInto assigns it to a
+// value of type toType. It returns the value to assign, a replacement
+// string that is decoded as usual, or nil to leave the string unchanged.
+type DecodeHook func(from string, toType reflect.Type) (any, error)
+
+// WithDecodeHook sets a function that is applied to every decoded string
+// before it is assigned by DecodeInto, e.g. to parse epoch milliseconds
+// into time.Time or "yes"/"no" into bool. The hook runs before custom
+// unmarshalers and built-in conversions. An error returned by the hook
+// aborts decoding.
+//
+// Parameters:
+//   - fn: Decode hook
+//
+// Returns:
+//   - Option: The option
+func WithDecodeHook(fn DecodeHook) Option {
+	return func(e *URLEncoder) {
+		e.decodeHook = fn
+	}
+}
+
+// WithNotation sets the notation used for nested keys when encoding and
+// decoding. The default is DotNotation.
+//
+// Parameters:
+//   - notation: Key notation
+//
+// Returns:
+//   - Option: The option
+func WithNotation(notation Notation) Option {
+	return func(e *URLEncoder) {
+		e.notation = notation
+	}
+}
+
+// WithSliceStyle sets the style used to encode slices and arrays. The
+// default is IndexedSlices. Keys with empty brackets, e.g. "tags[]", are
+// always accepted when decoding.
+//
+// Parameters:
+//   - style: Slice style
+//
+// Returns:
+//   - Option: The option
+func WithSliceStyle(style SliceStyle) Option {
+	return func(e *URLEncoder) {
+		e.sliceStyle = style
+	}
+}
+
+// WithQSCompat enables compatibility with the npm qs library. Encoding uses
+// bracket notation (or dot notation with AllowDots) and indexed slices, and
+// decoding follows the qs parsing rules, so values encoded by qs decode to
+// the same structure in Go.
+//
+// Parameters:
+//   - opts: qs options
+//
+// Returns:
+//   - Option: The option
+func WithQSCompat(opts QSOptions) Option {
+	return func(e *URLEncoder) {
+		e.qs = &opts
+		e.notation = BracketNotation
+		if opts.AllowDots {
+			e.notation = DotNotation
+		}
+		e.sliceStyle = IndexedSlices
+	}
+}
+
+// WithSchemaCompat enables compatibility with the struct tags of
+// gorilla/schema: fields are named by "schema" tags, untagged fields use
+// their Go field names, and the "required" and "default:value" tag options
+// are honored when decoding. Defaults of slices separate their elements
+// with "|".
+//
+// Returns:
+//   - Option: The option
+func WithSchemaCompat() Option {
+	return func(e *URLEncoder) {
+		e.tagName = "schema"
+		e.allowUntagged = true
+	}
+}
+
+// WithRepeatedKeys sets how keys with more than one value are decoded. The
+// default is FirstValue, unless the slice style is RepeatedKeySlices, which
+// always collects repeated keys into slices.
+//
+// Parameters:
+//   - mode: Repeated key mode
+//
+// Returns:
+//   - Option: The option
+func WithRepeatedKeys(mode RepeatedKeys) Option {
+	return func(e *URLEncoder) {
+		e.repeatedKeys = mode
+	}
+}
+
+// WithConflictStrategy sets how conflicting keys are resolved when
+// decoding. The default is ErrorOnConflict.
+//
+// Parameters:
+//   - strategy: Conflict strategy
+//
+// Returns:
+//   - Option: The option
+func WithConflictStrategy(strategy ConflictStrategy) Option {
+	return func(e *URLEncoder) {
+		e.conflicts = strategy
+	}
+}
+
+// WithDisallowUnknownKeys makes DecodeInto return an error listing the keys
+// that do not match any field when decoding into a struct.
+//
+// Returns:
+//   - Option: The option
+func WithDisallowUnknownKeys() Option {
+	return func(e *URLEncoder) {
+		e.strictKeys = true
+	}
+}
+
+// WithAllowedKeys restricts decoding to keys that match one of the
+// patterns. Patterns are paths in dot or bracket notation whose segments
+// are matched like path.Match, e.g. "user.*" or "items[*].id", and a "**"
+// segment matches any number of segments. A pattern also matches the keys
+// nested below the keys it matches, so "user" allows "user.name". Patterns
+// apply to keys after WithPrefix has removed the prefix, and malformed
+// patterns match nothing. Other keys are dropped unless
+// WithFilteredKeys(FilteredKeysReject) is set. Repeated calls add
+// patterns.
+//
+// Parameters:
+//   - patterns: Key patterns
+//
+// Returns:
+//   - Option: The option
+func WithAllowedKeys(patterns ...string) Option {
+	return func(e *URLEncoder) {
+		e.allowedKeys = append(e.allowedKeys, keyPatterns(patterns)...)
+	}
+}
+
+// WithDeniedKeys excludes keys that match one of the patterns from
+// decoding, even if they are allowed by WithAllowedKeys. Patterns are
+// matched as described in WithAllowedKeys, e.g. "**.password" denies
+// "password" and "user.password". Patterns apply to keys after WithPrefix
+// has removed the prefix. Excluded keys are dropped unless
+// WithFilteredKeys(FilteredKeysReject) is set. Repeated calls add
+// patterns.
+//
+// Parameters:
+//   - patterns: Key patterns
+//
+// Returns:
+//   - Option: The option
+func WithDeniedKeys(patterns ...string) Option {
+	return func(e *URLEncoder) {
+		e.deniedKeys = append(e.deniedKeys, keyPatterns(patterns)...)
+	}
+}
+
+// WithFilteredKeys sets what decoding does with keys that are excluded by
+// WithAllowedKeys or WithDeniedKeys. FilteredKeysReject rejects them with
+// an ErrKeyNotAllowed, which is collected like other invalid keys with
+// WithCollectErrors. The default is FilteredKeysStrip, which drops them.
+//
+// Parameters:
+//   - mode: Filtered keys mode
+//
+// Returns:
+//   - Option: The option
+func WithFilteredKeys(mode FilteredKeys) Option {
+	return func(e *URLEncoder) {
+		e.filteredKeys = mode
+	}
+}
+
+// WithTypeInference makes Decode return values that parse cleanly as
+// integers, floats or booleans as int, float64 or bool instead of strings,
+// e.g. "age=30&active=true" gives 30 and true. Values such as "007", "+1",
+// integers too large for an int or "1" for true stay strings. DecodeInto
+// is not affected.
+//
+// Returns:
+//   - Option: The option
+func WithTypeInference() Option {
+	return func(e *URLEncoder) {
+		e.inferTypes = true
+	}
+}
+
+// WithTypeHints makes Encode append the type of each value to its key and
+// Decode restore the type from it, e.g. "age:int=30", "score:float=1.5",
+// "ok:bool=true", "name:string=Ada" and "parent:null=". Floats are written
+// exactly, so that Encode followed by Decode returns equal map[string]any
+// data, except for empty maps and slices, which are not encoded. Slices are
+// always written with indices.
+//
+// Returns:
+//   - Option: The option
+func WithTypeHints() Option {
+	return func(e *URLEncoder) {
+		e.typeHints = true
+	}
+}
+
+// WithDelimiterKeys sets how Encode handles map keys that contain ".", "["
+// or "]". The default is RejectDelimiterKeys.
+//
+// Parameters:
+//   - mode: Delimiter handling
+//
+// Returns:
+//   - Option: The option
+func WithDelimiterKeys(mode DelimiterKeys) Option {
+	return func(e *URLEncoder) {
+		e.delimiterKeys = mode
+	}
+}
+
+// WithCollectErrors makes Decode and DecodeInto process all keys and
+// struct fields instead of stopping at the first error, and return all
+// errors joined with errors.Join. Each error names its key.
+//
+// Returns:
+//   - Option: The option
+func WithCollectErrors() Option {
+	return func(e *URLEncoder) {
+		e.collectErrors = true
+	}
+}
+
+// WithOmitZero makes Encode skip struct fields and map values that are
+// zero: empty strings, 0, false, nil, empty slices and maps, and values
+// whose IsZero method reports true, such as a zero time.Time. Slice
+// elements are always encoded so that indices are kept.
+//
+// Returns:
+//   - Option: The option
+func WithOmitZero() Option {
+	return func(e *URLEncoder) {
+		e.omitZero = true
+	}
+}
+
+// WithNullSentinel makes Encode write nil pointers and interfaces as the
+// given value, e.g. "null", instead of omitting them, so that a query can
+// express that a field is set to null. Fields with the "omitempty" tag
+// option and WithOmitZero still omit nil values. Decode returns values
+// equal to the sentinel as nil and DecodeInto clears the fields they are
+// assigned to, setting them to their zero values.
+//
+// Parameters:
+//   - sentinel: Value written for nil
+//
+// Returns:
+//   - Option: The option
+func WithNullSentinel(sentinel string) Option {
+	return func(e *URLEncoder) {
+		e.nullSentinel = &sentinel
+	}
+}
+
+// WithEmptyAsNull makes Decode return empty values as nil and DecodeInto
+// clear the fields they are assigned to, as for a null sentinel.
+//
+// Returns:
+//   - Option: The option
+func WithEmptyAsNull() Option {
+	return func(e *URLEncoder) {
+		e.emptyAsNull = true
+	}
+}
+
+// WithSortedMapKeys makes Encode encode map entries in the numerically-aware
+// order of their keys instead of random map order. The entries of a map are
+// then visited in the same order on every run, so that values that several
+// entries write to the same key, calls to the value transformer and the
+// first error reported are stable. EncodeToString always sorts map keys.
+//
+// Returns:
+//   - Option: The option
+func WithSortedMapKeys() Option {
+	return func(e *URLEncoder) {
+		e.sortMapKeys = true
+	}
+}
+
+// WithPrefix nests every encoded key under a prefix, e.g. "filter" encodes
+// "name" as "filter.name", or "filter[name]" in bracket notation. Decoding
+// strips the prefix, written in either notation, and ignores keys without
+// it, so that a payload can be embedded in a larger query.
+//
+// Parameters:
+//   - prefix: Key to nest keys under
+//
+// Returns:
+//   - Option: The option
+func WithPrefix(prefix string) Option {
+	return func(e *URLEncoder) {
+		e.prefix = prefix
+	}
+}
+
+// WithMaxQueryLength sets the maximum length in bytes of the query string
+// of encoded values, e.g. 8192 to stay within common proxy and browser URL
+// limits. Encode then fails with an ErrMaxQueryLength naming the first key,
+// in numerically-aware key order, at which the query string grows beyond the
+// limit, and Pairs stops before the pair that would exceed it. A value of 0
+// or less disables the limit, which is the default.
+//
+// Parameters:
+//   - n: Maximum query string length
+//
+// Returns:
+//   - Option: The option
+func WithMaxQueryLength(n int) Option {
+	return func(e *URLEncoder) {
+		e.maxQueryLength = n
+	}
+}
+
+// WithSemicolonSeparator makes DecodeString, DecodeURL and DecodeOrdered
+// accept ";" as well as "&" between pairs, e.g. "a=1;b=2", for old clients
+// that still send them. Without it such queries fail to parse, as with
+// url.ParseQuery since Go 1.17. A literal ";" in a key or value must then
+// be escaped as "%3B". DecodeRequest uses the form parsing of net/http and
+// is not affected.
+//
+// Returns:
+//   - Option: The option
+func WithSemicolonSeparator() Option {
+	return func(e *URLEncoder) {
+		e.query.semicolons = true
+	}
+}
+
+// WithPairSeparator sets the separator that EncodeToString, EncodeOrdered
+// and the URL query written by ApplyToRequest put between pairs, e.g. ';'
+// for "a=1;b=2". The default is '&'. The separator must be a character
+// that is percent-encoded in keys and values, such as ';', so that pairs
+// can be split again. Form bodies always use '&'.
+//
+// Parameters:
+//   - sep: Separator between pairs
+//
+// Returns:
+//   - Option: The option
+func WithPairSeparator(sep byte) Option {
+	return func(e *URLEncoder) {
+		e.query.separator = sep
+	}
+}
+
+// WithSpaceEncoding sets how EncodeToString, EncodeOrdered and the URL
+// query written by ApplyToRequest write spaces, and whether DecodeString,
+// DecodeURL and DecodeOrdered read "+" as a space. The default is SpacePlus,
+// as in form encoding; use SpacePercent to talk to services that follow RFC
+// 3986 and treat "+" as a literal plus sign. Form bodies always use
+// SpacePlus.
+//
+// Parameters:
+//   - encoding: Space encoding
+//
+// Returns:
+//   - Option: The option
+func WithSpaceEncoding(encoding SpaceEncoding) Option {
+	return func(e *URLEncoder) {
+		e.query.spaces = encoding
+	}
+}
+
+// WithEscapeProfile sets which characters EncodeToString, EncodeOrdered and
+// the URL query written by ApplyToRequest percent-encode in keys and values.
+// The default is EscapeFormEncoded, which matches url.Values.Encode; the
+// other profiles keep more characters readable, e.g. "a.b=x/y:z" with
+// EscapeQueryComponent. Spaces follow WithSpaceEncoding. Form bodies always
+// use EscapeFormEncoded.
+//
+// Parameters:
+//   - profile: Escape profile
+//
+// Returns:
+//   - Option: The option
+func WithEscapeProfile(profile EscapeProfile) Option {
+	return func(e *URLEncoder) {
+		e.query.profile = profile
+	}
+}
+
+// WithParallelism makes Encode encode the entries of large top-level maps
+// on up to n goroutines. The entries are split by key in sorted order and the
+// results are merged in that order, so that the output and the first error
+// reported are the same as with WithSortedMapKeys, except that all values
+// that entries in different chunks write to the same key are kept. Custom
+// encoders and transformers must then be safe for concurrent use. Smaller
+// maps, nested maps, Pairs and EncodeOrdered are encoded on the calling
+// goroutine. Values of n below 2 disable parallel encoding.
+//
+// Parameters:
+//   - n: Maximum number of goroutines
+//
+// Returns:
+//   - Option: The option
+func WithParallelism(n int) Option {
+	return func(e *URLEncoder) {
+		e.parallelism = n
+	}
+}
+
+// WithPrecedence sets which source wins when DecodeRequest finds a key in
+// both the URL query and the form body. The default is BodyPrecedence.
+//
+// Parameters:
+//   - precedence: Winning source
+//
+// Returns:
+//   - Option: The option
+func WithPrecedence(precedence Precedence) Option {
+	return func(e *URLEncoder) {
+		e.precedence = precedence
+	}
+}
@@ -0,0 +1,323 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithMaxDepth verifies that the maximum decode depth can be raised,
+// lowered, and disabled.
+func TestWithMaxDepth(t *testing.T) {
+	values := url.Values{}
+	values.Set(strings.Repeat("a.", 11)+"a", "value") // 12 segments
+
+	if _, err := NewURLEncoder().Decode(values); err == nil {
+		t.Fatal("expected error with default depth, got nil")
+	}
+	if _, err := NewURLEncoder(WithMaxDepth(12)).Decode(values); err != nil {
+		t.Fatalf("unexpected error with raised depth: %v", err)
+	}
+	if _, err := NewURLEncoder(WithMaxDepth(0)).Decode(values); err != nil {
+		t.Fatalf("unexpected error with disabled depth: %v", err)
+	}
+
+	shallow := url.Values{}
+	shallow.Set("a.b", "value")
+	if _, err := NewURLEncoder(WithMaxDepth(1)).Decode(shallow); err == nil {
+		t.Fatal("expected error with lowered depth, got nil")
+	}
+}
+
+// TestWithTagName verifies that a custom struct tag is used for field names
+// when encoding and decoding.
+func TestWithTagName(t *testing.T) {
+	type Query struct {
+		Name  string `url:"name"`
+		Limit int    `url:"limit"`
+	}
+	encoder := NewURLEncoder(WithTagName("url"))
+	values, err := encoder.Encode(Query{Name: "Ada", Limit: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("name"); got != "Ada" {
+		t.Errorf("expected name=Ada, got %q", got)
+	}
+	var decoded Query
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Limit != 5 {
+		t.Errorf("expected limit=5, got %d", decoded.Limit)
+	}
+	if _, err := NewURLEncoder().Encode(Query{}); err == nil {
+		t.Fatal("expected error for missing json tag, got nil")
+	}
+}
+
+// TestWithKeyTransformer verifies that generated key names are rewritten
+// and that the transformer receives the parent path.
+func TestWithKeyTransformer(t *testing.T) {
+	type Item struct {
+		UnitPrice int `json:"unitPrice"`
+	}
+	type Order struct {
+		LineItems []Item `json:"lineItems"`
+	}
+	var paths []string
+	encoder := NewURLEncoder(WithKeyTransformer(
+		func(path []string, key string) string {
+			paths = append(paths, strings.Join(path, "/"))
+			var b strings.Builder
+			for _, r := range key {
+				if r >= 'A' && r <= 'Z' {
+					b.WriteByte('_')
+					r += 'a' - 'A'
+				}
+				b.WriteRune(r)
+			}
+			return b.String()
+		},
+	))
+	values, err := encoder.Encode(map[string]any{
+		"order": Order{LineItems: []Item{{UnitPrice: 5}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("order.line_items[0].unit_price"); got != "5" {
+		t.Errorf("expected transformed key, got %v", values)
+	}
+	expected := []string{"", "order", "order/line_items/0"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected paths %v, got %v", expected, paths)
+	}
+}
+
+// TestWithValueTransformer verifies that values are rewritten before they
+// are set and that transformer errors abort encoding.
+func TestWithValueTransformer(t *testing.T) {
+	encoder := NewURLEncoder(WithValueTransformer(
+		func(key, value string) (string, error) {
+			if len(value) > 5 {
+				return "", errors.New("value too long")
+			}
+			return strings.TrimSpace(strings.ReplaceAll(value, "\n", "")), nil
+		},
+	))
+	values, err := encoder.Encode(map[string]any{
+		"name": " a\nb ",
+		"n":    42,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("name"); got != "ab" {
+		t.Errorf("expected name=ab, got %q", got)
+	}
+	if got := values.Get("n"); got != "42" {
+		t.Errorf("expected n=42, got %q", got)
+	}
+	if _, err := encoder.Encode(map[string]any{"name": "too long"}); err == nil {
+		t.Fatal("expected error from value transformer, got nil")
+	}
+}
+
+// TestWithSchemaCompat verifies that gorilla/schema tags, untagged fields,
+// defaults and required fields are honored.
+func TestWithSchemaCompat(t *testing.T) {
+	type Search struct {
+		Query  string   `schema:"q,required"`
+		Page   int      `schema:"page,default:1"`
+		Kinds  []string `schema:"kinds,default:a|b"`
+		Region string
+		Secret string `schema:"-"`
+	}
+	encoder := NewURLEncoder(WithSchemaCompat())
+	values := url.Values{}
+	values.Set("q", "go")
+	values.Set("Region", "eu")
+	values.Set("Secret", "x")
+
+	var search Search
+	if err := encoder.DecodeInto(values, &search); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Search{
+		Query: "go", Page: 1, Kinds: []string{"a", "b"}, Region: "eu",
+	}
+	if !reflect.DeepEqual(search, expected) {
+		t.Errorf("expected %+v, got %+v", expected, search)
+	}
+
+	encoded, err := encoder.Encode(expected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := encoded.Get("Region"); got != "eu" {
+		t.Errorf("expected Region=eu, got %q", got)
+	}
+
+	values.Del("q")
+	if err := encoder.DecodeInto(values, &search); err == nil {
+		t.Fatal("expected error for missing required field, got nil")
+	}
+}
+
+// TestWithOmitZero verifies that zero fields and map values are skipped
+// and slice elements are kept.
+func TestWithOmitZero(t *testing.T) {
+	type Filter struct {
+		Query   string            `json:"q"`
+		Page    int               `json:"page"`
+		Active  bool              `json:"active"`
+		Since   time.Time         `json:"since"`
+		Owner   *string           `json:"owner"`
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+		Scores  []int             `json:"scores"`
+		Enabled bool              `json:"enabled"`
+	}
+	data := map[string]any{
+		"filter": Filter{Scores: []int{0, 1}, Enabled: true},
+		"empty":  "",
+		"zero":   0,
+		"nil":    nil,
+		"kept":   "x",
+	}
+	values, err := NewURLEncoder(WithOmitZero()).Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"filter.scores[0]": {"0"},
+		"filter.scores[1]": {"1"},
+		"filter.enabled":   {"true"},
+		"kept":             {"x"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+// TestWithNullSentinel_Encode verifies that nil pointers and interfaces are
+// written as the sentinel unless they are omitted.
+func TestWithNullSentinel_Encode(t *testing.T) {
+	type Patch struct {
+		Name    *string `json:"name"`
+		Parent  *int    `json:"parent"`
+		Comment *string `json:"comment,omitempty"`
+	}
+	name := "Ada"
+	data := map[string]any{
+		"patch": Patch{Name: &name},
+		"meta":  map[string]any{"owner": nil},
+	}
+	values, err := NewURLEncoder(WithNullSentinel("null")).Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"patch.name":   {"Ada"},
+		"patch.parent": {"null"},
+		"meta.owner":   {"null"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	values, err = NewURLEncoder().Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values.Has("patch.parent") || values.Has("meta.owner") {
+		t.Errorf("expected nil values to be omitted, got %v", values)
+	}
+}
+
+// TestWithNullSentinel_Decode verifies that sentinel and, on request, empty
+// values decode as nil and clear struct fields.
+func TestWithNullSentinel_Decode(t *testing.T) {
+	values := url.Values{
+		"name":    {"null"},
+		"parent":  {"null"},
+		"tags[0]": {"null"},
+		"note":    {""},
+		"age":     {"3"},
+	}
+	encoder := NewURLEncoder(WithNullSentinel("null"), WithEmptyAsNull())
+	got, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"name":   nil,
+		"parent": nil,
+		"tags":   []any{nil},
+		"note":   nil,
+		"age":    "3",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	type Patch struct {
+		Name   string  `json:"name"`
+		Parent *int    `json:"parent"`
+		Note   *string `json:"note"`
+		Age    int     `json:"age"`
+		Other  string  `json:"other"`
+	}
+	parent, note := 7, "x"
+	patch := Patch{
+		Name: "Ada", Parent: &parent, Note: &note, Age: 1, Other: "kept",
+	}
+	delete(values, "tags[0]")
+	if err := encoder.DecodeInto(values, &patch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Patch{Age: 3, Other: "kept"}
+	if !reflect.DeepEqual(patch, want) {
+		t.Errorf("expected %+v, got %+v", want, patch)
+	}
+
+	got, err = NewURLEncoder().Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["name"] != "null" || got["note"] != "" {
+		t.Errorf("expected values without sentinel to be kept, got %v", got)
+	}
+}
+
+// TestWithSortedMapKeys verifies that map entries are encoded in
+// numerically-aware key order, including integer keys.
+func TestWithSortedMapKeys(t *testing.T) {
+	var keys []string
+	record := func(key string, value string) (string, error) {
+		keys = append(keys, key)
+		return value, nil
+	}
+	data := map[string]any{
+		"b": "x",
+		"a": map[int]string{10: "x", 2: "x", 1: "x"},
+		"c": "x",
+	}
+	encoder := NewURLEncoder(
+		WithSortedMapKeys(), WithValueTransformer(record),
+	)
+	for i := 0; i < 5; i++ {
+		keys = nil
+		if _, err := encoder.Encode(data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"a.1", "a.2", "a.10", "b", "c"}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Fatalf("expected %v, got %v", expected, keys)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package urlcodec
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+)
+
+var orderedMapType = reflect.TypeFor[OrderedMap]()
+
+// OrderedMap is a map with string keys that keeps the insertion order of
+// its keys. Encode accepts it anywhere a map is accepted and encodes its
+// entries in order, and DecodeOrdered returns objects as ordered maps. The
+// zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []string       // Keys in insertion order
+	values map[string]any // Values by key
+}
+
+// NewOrderedMap returns a new empty OrderedMap.
+//
+// Returns:
+//   - *OrderedMap: New ordered map
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{}
+}
+
+// Set sets the value of a key. A new key is appended to the key order and
+// an existing key keeps its position.
+//
+// Parameters:
+//   - key: Key
+//   - value: Value
+func (m *OrderedMap) Set(key string, value any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of a key.
+//
+// Parameters:
+//   - key: Key
+//
+// Returns:
+//   - any: Value
+//   - bool: Whether the key exists
+func (m *OrderedMap) Get(key string) (any, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Delete removes a key and its value.
+//
+// Parameters:
+//   - key: Key
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k string) bool {
+		return k == key
+	})
+}
+
+// Len returns the number of keys.
+//
+// Returns:
+//   - int: Number of keys
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the keys in insertion order.
+//
+// Returns:
+//   - []string: Keys
+func (m *OrderedMap) Keys() []string {
+	return slices.Clone(m.keys)
+}
+
+// All returns an iterator over the keys and values in insertion order.
+//
+// Returns:
+//   - iter.Seq2[string, any]: Iterator over the entries
+func (m *OrderedMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, key := range m.keys {
+			if !yield(key, m.values[key]) {
+				return
+			}
+		}
+	}
+}
+
+// encodeOrderedMap encodes the entries of an OrderedMap in insertion order.
+func (e *URLEncoder) encodeOrderedMap(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	m := v.Interface().(OrderedMap)
+	for _, key := range m.keys {
+		value := m.values[key]
+		// The value is taken as an interface so that nil values are
+		// handled like nil interfaces in other maps.
+		elem := reflect.ValueOf(&value).Elem()
+		if e.omitZero && isZeroValue(elem) {
+			continue
+		}
+		newFieldTag, err := e.mapChildKey(fieldTag, key)
+		if err != nil {
+			return err
+		}
+		if err := e.encodeValue(values, newFieldTag, elem); err != nil {
+			return err
+		}
+		if err := e.flushTopLevel(values, fieldTag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeOrdered encodes data like Encode and returns it as a query string
+// whose keys are in the order in which they were encoded: struct fields in
+// declaration order, slice elements by index, OrderedMap entries in
+// insertion order and other map entries in sorted key order, as with
+// WithSortedMapKeys.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Query string
+//   - error: Error
+func (e URLEncoder) EncodeOrdered(data any) (string, error) {
+	e.sortMapKeys = true
+	e.order = &keyOrder{}
+	e.raw = &rawValues{}
+	values, err := e.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	return e.query.encodeKeys(values, e.order.keysOf(values), e.raw), nil
+}
+
+// keyOrder records the order in which keys are first written.
+type keyOrder struct {
+	keys []string        // Keys in the order they were first written
+	seen map[string]bool // Keys that were written
+}
+
+// add records a key if it was not written before. It does nothing on a nil
+// keyOrder, so that recording is disabled by default.
+func (o *keyOrder) add(key string) {
+	if o == nil || o.seen[key] {
+		return
+	}
+	if o.seen == nil {
+		o.seen = make(map[string]bool)
+	}
+	o.seen[key] = true
+	o.keys = append(o.keys, key)
+}
+
+// reset forgets the recorded keys.
+func (o *keyOrder) reset() {
+	o.keys = o.keys[:0]
+	clear(o.seen)
+}
+
+// keysOf returns the recorded keys that are in the values, followed by any
+// keys that were not recorded in sorted order.
+func (o *keyOrder) keysOf(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for _, key := range o.keys {
+		if _, ok := values[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range values {
+		if !o.seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	slices.SortFunc(rest, compareKeys)
+	return append(keys, rest...)
+}
+
+// DecodeOrdered parses a raw query string and decodes it like Decode, but
+// returns objects as OrderedMap values whose keys are in the order in which
+// they first appear in the query. Keys of objects that have no position in
+// the query, e.g. from qs compatibility mode, follow in sorted order.
+//
+// Parameters:
+//   - query: Raw query string
+//
+// Returns:
+//   - *OrderedMap: Decoded data
+//   - error: Error
+func (e URLEncoder) DecodeOrdered(query string) (*OrderedMap, error) {
+	values, err := e.query.parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse query %q: %w", query, err)
+	}
+	data, err := e.Decode(values)
+	if err != nil {
+		return nil, err
+	}
+	ranks := &keyRanks{}
+	for i, key := range e.query.keys(query) {
+		name, _ := e.splitTypeHint(e.cleanKey(key))
+		if e.prefix != "" {
+			var ok bool
+			if name, ok = cutKeyPrefix(name, e.prefix); !ok {
+				continue
+			}
+		}
+		r := ranks
+		for _, segment := range keySegments(name) {
+			r = r.child(e.unescapeMapKey(segment), i)
+		}
+	}
+	return orderedMap(data, ranks), nil
+}
+
+// keyRanks holds the position at which each key path first appears in a
+// query.
+type keyRanks struct {
+	rank     int                  // Position of the first key of the path
+	children map[string]*keyRanks // Ranks of the child segments
+}
+
+// child returns the ranks of a child segment, recording the rank if the
+// segment has not appeared before.
+func (r *keyRanks) child(segment string, rank int) *keyRanks {
+	if r.children == nil {
+		r.children = make(map[string]*keyRanks)
+	}
+	c, ok := r.children[segment]
+	if !ok {
+		c = &keyRanks{rank: rank}
+		r.children[segment] = c
+	}
+	return c
+}
+
+// lookup returns the ranks of a child segment. It is safe on nil ranks.
+func (r *keyRanks) lookup(segment string) (*keyRanks, bool) {
+	if r == nil {
+		return nil, false
+	}
+	c, ok := r.children[segment]
+	return c, ok
+}
+
+// elements returns the ranks of the index segments in index order, so that
+// the i-th element of a decoded slice, whose indices are compacted, gets the
+// ranks of the i-th index in the query. It is safe on nil ranks.
+func (r *keyRanks) elements() []*keyRanks {
+	if r == nil {
+		return nil
+	}
+	var indices []string
+	for segment := range r.children {
+		if isIndex(segment) {
+			indices = append(indices, segment)
+		}
+	}
+	slices.SortFunc(indices, compareKeys)
+	elements := make([]*keyRanks, len(indices))
+	for i, index := range indices {
+		elements[i] = r.children[index]
+	}
+	return elements
+}
+
+// orderedMap converts a decoded map to an OrderedMap with keys ordered by
+// their ranks, recursively. Keys without ranks follow in sorted order.
+func orderedMap(data map[string]any, ranks *keyRanks) *OrderedMap {
+	keys := slices.Collect(maps.Keys(data))
+	slices.SortFunc(keys, func(a, b string) int {
+		ra, okA := ranks.lookup(a)
+		rb, okB := ranks.lookup(b)
+		switch {
+		case okA && okB:
+			return ra.rank - rb.rank
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return compareKeys(a, b)
+	})
+	m := &OrderedMap{}
+	for _, key := range keys {
+		r, _ := ranks.lookup(key)
+		m.Set(key, orderedValue(data[key], r))
+	}
+	return m
+}
+
+// orderedValue converts maps in a decoded value to OrderedMap values.
+func orderedValue(value any, ranks *keyRanks) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return orderedMap(v, ranks)
+	case []any:
+		elements := ranks.elements()
+		for i, elem := range v {
+			var r *keyRanks
+			if i < len(elements) {
+				r = elements[i]
+			}
+			v[i] = orderedValue(elem, r)
+		}
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package urlcodec
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestOrderedMap verifies that keys keep their insertion order across Set,
+// Delete and iteration.
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap
+	m.Set("b", 1)
+	m.Set("a", 2)
+	m.Set("c", 3)
+	m.Set("b", 4)
+	m.Delete("a")
+	m.Delete("missing")
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("expected keys [b c], got %v", got)
+	}
+	if value, ok := m.Get("b"); !ok || value != 4 {
+		t.Errorf("expected b=4, got %v", value)
+	}
+	if _, ok := m.Get("a"); ok || m.Len() != 2 {
+		t.Errorf("expected a to be deleted, got %d keys", m.Len())
+	}
+	var values []any
+	for _, value := range m.All() {
+		values = append(values, value)
+	}
+	if !reflect.DeepEqual(values, []any{4, 3}) {
+		t.Errorf("expected values [4 3], got %v", values)
+	}
+}
+
+// TestEncodeOrdered verifies that EncodeOrdered writes keys in encoding
+// order: OrderedMap entries by insertion, struct fields by declaration and
+// plain map entries sorted.
+func TestEncodeOrdered(t *testing.T) {
+	type Page struct {
+		Size   int `json:"size"`
+		Number int `json:"number"`
+	}
+	user := NewOrderedMap()
+	user.Set("name", "Ada")
+	user.Set("id", 1)
+	data := NewOrderedMap()
+	data.Set("z", "last?")
+	data.Set("user", user)
+	data.Set("tags", []string{"b", "a"})
+	data.Set("page", Page{Size: 10, Number: 2})
+	data.Set("meta", map[string]string{"y": "1", "x": "2"})
+	data.Set("none", nil)
+
+	encoder := NewURLEncoder()
+	got, err := encoder.EncodeOrdered(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "z=last%3F&user.name=Ada&user.id=1&tags%5B0%5D=b" +
+		"&tags%5B1%5D=a&page.size=10&page.number=2&meta.x=2&meta.y=1"
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	values, err := encoder.Encode(*data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values.Get("user.id") != "1" || len(values) != 9 {
+		t.Errorf("expected Encode to accept OrderedMap, got %v", values)
+	}
+}
+
+// TestDecodeOrdered verifies that DecodeOrdered returns objects with keys
+// in the order in which they first appear in the query.
+func TestDecodeOrdered(t *testing.T) {
+	query := "z=1&user.name=Ada&items[0].b=x&items[0].a=y&user.id=1&a=2"
+	got, err := NewURLEncoder().DecodeOrdered(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := got.Keys(); !slices.Equal(keys, []string{
+		"z", "user", "items", "a",
+	}) {
+		t.Errorf("expected keys [z user items a], got %v", keys)
+	}
+	user, _ := got.Get("user")
+	keys := user.(*OrderedMap).Keys()
+	if !slices.Equal(keys, []string{"name", "id"}) {
+		t.Errorf("expected user keys [name id], got %v", keys)
+	}
+	items, _ := got.Get("items")
+	item := items.([]any)[0].(*OrderedMap)
+	if keys := item.Keys(); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("expected item keys [b a], got %v", keys)
+	}
+
+	encoded, err := NewURLEncoder().EncodeOrdered(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "z=1&user.name=Ada&user.id=1&items%5B0%5D.b=x" +
+		"&items%5B0%5D.a=y&a=2"
+	if encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	got, err = NewURLEncoder().DecodeOrdered(
+		"items[5].b=x&items[5].a=y&items[12].d=z&items[12].c=w",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items, _ = got.Get("items")
+	for i, expected := range [][]string{{"b", "a"}, {"d", "c"}} {
+		keys := items.([]any)[i].(*OrderedMap).Keys()
+		if !slices.Equal(keys, expected) {
+			t.Errorf("expected item %d keys %v, got %v", i, expected, keys)
+		}
+	}
+
+	if _, err := NewURLEncoder().DecodeOrdered("a=%zz"); err == nil {
+		t.Fatal("expected error for invalid query, got nil")
+	}
+}
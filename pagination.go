@@ -0,0 +1,145 @@
+package urlcodec
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+)
+
+const (
+	// defaultPageSize is the page size used when a request has none and
+	// the limits set none.
+	defaultPageSize = 20
+	// defaultMaxPageSize is the largest page size when the limits set none.
+	defaultMaxPageSize = 100
+)
+
+// Pagination holds the standard pagination parameters of a listing:
+// "page" and "per_page" for page-based pagination, "limit" and "offset"
+// for offset-based pagination and "cursor" for cursor-based pagination,
+// e.g. with tokens from a CursorCodec. It can be encoded and decoded like
+// any struct, or embedded into a request struct to inline its parameters.
+// Use Apply or DecodePagination to check bounds and fill in defaults.
+type Pagination struct {
+	Page    int    `json:"page,omitempty"`     // Page number from 1
+	PerPage int    `json:"per_page,omitempty"` // Items per page
+	Cursor  string `json:"cursor,omitempty"`   // Opaque cursor token
+	Limit   int    `json:"limit,omitempty"`    // Maximum number of items
+	Offset  int    `json:"offset,omitempty"`   // Number of items to skip
+}
+
+// PaginationLimits sets the page sizes allowed by Pagination.Apply.
+type PaginationLimits struct {
+	DefaultSize int // Page size if none is given, 0 for 20
+	MaxSize     int // Largest page size, 0 for 100
+}
+
+// DecodePagination decodes the pagination parameters of URL values like
+// DecodeInto and applies the limits to them as described in
+// Pagination.Apply.
+//
+// Parameters:
+//   - values: URL values
+//   - limits: Page size limits
+//
+// Returns:
+//   - Pagination: Pagination with defaults filled in
+//   - error: Error
+func (e URLEncoder) DecodePagination(
+	values url.Values, limits PaginationLimits,
+) (Pagination, error) {
+	var p Pagination
+	if err := e.DecodeInto(values, &p); err != nil {
+		return Pagination{}, err
+	}
+	if err := p.Apply(limits); err != nil {
+		return Pagination{}, err
+	}
+	return p, nil
+}
+
+// Apply checks the parameters and fills in defaults. Negative values,
+// page-based parameters combined with offset-based ones and cursors
+// combined with a page or offset are rejected with an
+// ErrInvalidPagination. A missing page size is set to the default and page
+// sizes above the maximum are lowered to it. Without an offset, limit or
+// cursor the page defaults to 1.
+//
+// Parameters:
+//   - limits: Page size limits
+//
+// Returns:
+//   - error: Error
+func (p *Pagination) Apply(limits PaginationLimits) error {
+	for _, param := range []struct {
+		name  string
+		value int
+	}{
+		{"page", p.Page}, {"per_page", p.PerPage},
+		{"limit", p.Limit}, {"offset", p.Offset},
+	} {
+		if param.value < 0 {
+			return fmt.Errorf(
+				"%w: %q must not be negative", ErrInvalidPagination,
+				param.name,
+			)
+		}
+	}
+	offsetBased := p.Limit > 0 || p.Offset > 0
+	switch {
+	case offsetBased && (p.Page > 0 || p.PerPage > 0):
+		return fmt.Errorf(
+			"%w: \"page\" and \"per_page\" cannot be combined with "+
+				"\"limit\" and \"offset\"", ErrInvalidPagination,
+		)
+	case p.Cursor != "" && (p.Page > 0 || p.Offset > 0):
+		return fmt.Errorf(
+			"%w: \"cursor\" cannot be combined with \"page\" or \"offset\"",
+			ErrInvalidPagination,
+		)
+	}
+	size := max(p.PerPage, p.Limit)
+	if size == 0 {
+		size = limits.DefaultSize
+		if size <= 0 {
+			size = defaultPageSize
+		}
+	}
+	maxSize := limits.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxPageSize
+	}
+	size = min(size, maxSize)
+	if offsetBased {
+		p.Limit = size
+		return nil
+	}
+	p.PerPage = size
+	if p.Cursor == "" && p.Page == 0 {
+		p.Page = 1
+	}
+	if p.Page > math.MaxInt/size {
+		return fmt.Errorf(
+			"%w: \"page\" %d is too large", ErrInvalidPagination, p.Page,
+		)
+	}
+	return nil
+}
+
+// Window returns the number of items to skip and the maximum number of
+// items to return, for either page-based or offset-based pagination. With
+// a cursor the offset is 0 and the position is taken from the cursor.
+// Call Apply first.
+//
+// Returns:
+//   - int: Number of items to skip
+//   - int: Maximum number of items
+func (p Pagination) Window() (int, int) {
+	if p.Limit > 0 || p.Offset > 0 {
+		return p.Offset, p.Limit
+	}
+	if p.Page == 0 {
+		return 0, p.PerPage
+	}
+	return (p.Page - 1) * p.PerPage, p.PerPage
+}
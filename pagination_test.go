@@ -0,0 +1,69 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+// TestDecodePagination verifies that defaults are filled in, sizes are
+// capped and windows are computed for each pagination style.
+func TestDecodePagination(t *testing.T) {
+	limits := PaginationLimits{DefaultSize: 25, MaxSize: 50}
+	tests := []struct {
+		query  string
+		want   Pagination
+		offset int
+		limit  int
+	}{
+		{"", Pagination{Page: 1, PerPage: 25}, 0, 25},
+		{"page=3&per_page=10", Pagination{Page: 3, PerPage: 10}, 20, 10},
+		{"page=2&per_page=500", Pagination{Page: 2, PerPage: 50}, 50, 50},
+		{"offset=40", Pagination{Limit: 25, Offset: 40}, 40, 25},
+		{"limit=5", Pagination{Limit: 5}, 0, 5},
+		{"cursor=abc", Pagination{Cursor: "abc", PerPage: 25}, 0, 25},
+	}
+	encoder := NewURLEncoder()
+	for _, tt := range tests {
+		values, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := encoder.DecodePagination(values, limits)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v", tt.query, tt.want, got)
+		}
+		if offset, limit := got.Window(); offset != tt.offset ||
+			limit != tt.limit {
+			t.Errorf("%q: expected window %d, %d, got %d, %d",
+				tt.query, tt.offset, tt.limit, offset, limit)
+		}
+	}
+
+	encoded, err := encoder.EncodeToString(tests[1].want)
+	if err != nil || encoded != "page=3&per_page=10" {
+		t.Errorf("expected page=3&per_page=10, got %s, %v", encoded, err)
+	}
+}
+
+// TestDecodePagination_Invalid verifies that negative and conflicting
+// parameters are rejected with ErrInvalidPagination.
+func TestDecodePagination_Invalid(t *testing.T) {
+	encoder := NewURLEncoder()
+	for _, query := range []string{
+		"page=-1", "limit=-5", "page=2&offset=10", "per_page=10&limit=5",
+		"cursor=abc&page=2", "page=9223372036854775807&per_page=10",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = encoder.DecodePagination(values, PaginationLimits{})
+		if !errors.Is(err, ErrInvalidPagination) {
+			t.Errorf("%q: expected ErrInvalidPagination, got %v", query, err)
+		}
+	}
+}
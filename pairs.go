@@ -0,0 +1,75 @@
+package urlcodec
+
+import (
+	"errors"
+	"iter"
+	"net/url"
+	"reflect"
+)
+
+// errStopPairs stops encoding when the consumer of Pairs stops iterating.
+var errStopPairs = errors.New("pairs iteration stopped")
+
+// Pairs encodes data like Encode but yields the encoded key/value pairs one
+// at a time instead of returning url.Values. Each top-level map entry or
+// struct field is encoded and yielded before the next one is encoded, so
+// only the values of one top-level entry are held in memory. Pairs are
+// yielded in the order of EncodeOrdered within each entry. A key written by
+// several top-level entries is yielded once per entry.
+//
+// Encoding stops when the iteration stops. The returned function reports the
+// error that ended the iteration early, if any, after the iteration is done.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - iter.Seq2[string, string]: Iterator over the keys and values
+//   - func() error: Function returning the encoding error
+func (e URLEncoder) Pairs(
+	data any,
+) (iter.Seq2[string, string], func() error) {
+	var err error
+	seq := func(yield func(string, string) bool) {
+		err = nil
+		enc := e
+		enc.sortMapKeys = true
+		enc.order = &keyOrder{}
+		length := queryLength{limit: e.maxQueryLength, format: e.query}
+		enc.flush = func(values url.Values) error {
+			for _, key := range enc.order.keysOf(values) {
+				for _, value := range values[key] {
+					if err := length.add(key, value); err != nil {
+						return err
+					}
+					if !yield(key, value) {
+						return errStopPairs
+					}
+				}
+			}
+			clear(values)
+			enc.order.reset()
+			return nil
+		}
+		values := url.Values{}
+		encErr := enc.encodeURL(&values, reflect.ValueOf(data))
+		if encErr == nil {
+			// Values that were not written by a top-level entry, e.g. by a
+			// URLValuesMarshaler, are yielded at the end.
+			encErr = enc.flush(values)
+		}
+		if !errors.Is(encErr, errStopPairs) {
+			err = encErr
+		}
+	}
+	return seq, func() error { return err }
+}
+
+// flushTopLevel passes the values encoded so far to the flush function
+// after a top-level entry has been encoded, if one is set.
+func (e *URLEncoder) flushTopLevel(values *url.Values, fieldTag string) error {
+	if e.flush == nil || fieldTag != e.prefix {
+		return nil
+	}
+	return e.flush(*values)
+}
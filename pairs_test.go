@@ -0,0 +1,61 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestPairs verifies that pairs are yielded in encoding order, that
+// iteration can stop early and that encoding errors are reported.
+func TestPairs(t *testing.T) {
+	type Request struct {
+		Name string            `json:"name"`
+		Tags []string          `json:"tags"`
+		Meta map[string]string `json:"meta"`
+	}
+	request := Request{
+		Name: "Ada",
+		Tags: []string{"a", "b"},
+		Meta: map[string]string{"y": "1", "x": "2"},
+	}
+	encoder := NewURLEncoder()
+	seq, errFn := encoder.Pairs(request)
+	var keys []string
+	values := url.Values{}
+	for key, value := range seq {
+		keys = append(keys, key)
+		values.Add(key, value)
+	}
+	if err := errFn(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"name", "tags[0]", "tags[1]", "meta.x", "meta.y"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+	encoded, _ := encoder.Encode(request)
+	if !reflect.DeepEqual(values, encoded) {
+		t.Errorf("expected %v, got %v", encoded, values)
+	}
+
+	keys = nil
+	for key := range seq {
+		keys = append(keys, key)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	if len(keys) != 2 || errFn() != nil {
+		t.Errorf("expected to stop after 2 pairs, got %v, %v", keys, errFn())
+	}
+
+	seq, errFn = encoder.Pairs(map[string]any{"a": "1", "b": complex(1, 2)})
+	for range seq {
+	}
+	var unsupported ErrUnsupportedType
+	if !errors.As(errFn(), &unsupported) {
+		t.Errorf("expected ErrUnsupportedType, got %v", errFn())
+	}
+}
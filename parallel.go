@@ -0,0 +1,69 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+// minParallelKeys is the minimum number of entries in a top-level map for it
+// to be encoded in parallel.
+const minParallelKeys = 256
+
+// isParallel reports whether a map with n entries at a field tag is encoded
+// in parallel. Streaming and key order recording need the entries in order
+// on one goroutine.
+func (e *URLEncoder) isParallel(fieldTag string, n int) bool {
+	return e.parallelism > 1 && fieldTag == e.prefix &&
+		n >= minParallelKeys &&
+		e.flush == nil && e.order == nil
+}
+
+// encodeMapParallel encodes the entries of a map on several goroutines. The
+// keys are split into contiguous chunks that are encoded into their own
+// values and merged in key order. The first error in key order is returned.
+func (e *URLEncoder) encodeMapParallel(
+	values *url.Values, fieldTag string, v reflect.Value, keys []mapKey,
+) error {
+	chunks := min(e.parallelism, len(keys))
+	results := make([]url.Values, chunks)
+	errs := make([]error, chunks)
+	var wg sync.WaitGroup
+	for i := range chunks {
+		start, end := i*len(keys)/chunks, (i+1)*len(keys)/chunks
+		wg.Go(func() {
+			chunk := url.Values{}
+			for _, key := range keys[start:end] {
+				if err := e.encodeMapEntry(
+					&chunk, fieldTag, v.MapIndex(key.value), key.name,
+				); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+			results[i] = chunk
+		})
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	// Whether a key written by several chunks is replaced or appended to
+	// depends on how each entry writes it, so such rare maps are encoded
+	// again sequentially.
+	merged := results[0]
+	for _, chunk := range results[1:] {
+		for key, vals := range chunk {
+			if _, ok := merged[key]; ok {
+				return e.encodeMapEntries(values, fieldTag, v, keys)
+			}
+			merged[key] = vals
+		}
+	}
+	for key, vals := range merged {
+		(*values)[key] = vals
+	}
+	return nil
+}
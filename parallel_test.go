@@ -0,0 +1,112 @@
+package urlcodec
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// parallelData returns a top-level map with n entries of nested values.
+func parallelData(n int) map[string]any {
+	data := make(map[string]any, n)
+	for i := range n {
+		data[fmt.Sprintf("key%d", i)] = map[string]any{
+			"name": fmt.Sprintf("name%d", i),
+			"tags": []any{"a", "b"},
+		}
+	}
+	return data
+}
+
+// TestWithParallelism_SameOutput verifies that parallel encoding gives the
+// same values as sequential encoding.
+func TestWithParallelism_SameOutput(t *testing.T) {
+	data := parallelData(1000)
+	expected, err := NewURLEncoder().Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, n := range []int{2, 3, 8, 2000} {
+		got, err := NewURLEncoder(WithParallelism(n)).Encode(data)
+		if err != nil {
+			t.Fatalf("parallelism %d: unexpected error: %v", n, err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("parallelism %d: output differs", n)
+		}
+	}
+}
+
+// TestWithParallelism_CollidingKeys verifies that parallel encoding gives
+// the same values as sequential encoding when entries in different chunks
+// write the same key.
+func TestWithParallelism_CollidingKeys(t *testing.T) {
+	data := parallelData(1000)
+	// The keys sorted between "key" and "key.b" put them in other chunks.
+	for i := range 400 {
+		data[fmt.Sprintf("key-%d", i)] = "filler"
+	}
+	data["key"] = map[string]any{"b": []string{"x", "y"}, "c": "nested"}
+	data["key.b"] = "flat"
+	data["key.c"] = "flat"
+	for _, style := range []SliceStyle{IndexedSlices, RepeatedKeySlices} {
+		opts := []Option{
+			WithSortedMapKeys(),
+			WithDelimiterKeys(AllowDelimiterKeys),
+			WithSliceStyle(style),
+		}
+		expected, err := NewURLEncoder(opts...).Encode(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		opts = append(opts, WithParallelism(4))
+		got, err := NewURLEncoder(opts...).Encode(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("style %d: output differs", style)
+		}
+	}
+}
+
+// TestWithParallelism_FirstError verifies that the error of the first
+// failing key in key order is returned.
+func TestWithParallelism_FirstError(t *testing.T) {
+	data := parallelData(1000)
+	fail := func(key string, value string) (string, error) {
+		if key == "key10.name" || key == "key900.name" {
+			return "", fmt.Errorf("bad %s", key)
+		}
+		return value, nil
+	}
+	encoder := NewURLEncoder(WithParallelism(4), WithValueTransformer(fail))
+	for range 5 {
+		_, err := encoder.Encode(data)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if got := err.Error(); got != `cannot transform value of `+
+			`"key10.name": bad key10.name` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// TestWithParallelism_Pairs verifies that Pairs streams the entries of a
+// large map in key order with parallelism enabled.
+func TestWithParallelism_Pairs(t *testing.T) {
+	data := parallelData(300)
+	encoder := NewURLEncoder(WithParallelism(4), WithSortedMapKeys())
+	pairs, errFn := encoder.Pairs(data)
+	var keys []string
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	if err := errFn(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 900 || keys[0] != "key0.name" {
+		t.Fatalf("unexpected keys: %d, first %q", len(keys), keys[0])
+	}
+}
@@ -0,0 +1,53 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ApplyPatch decodes base and patch values, applies the patch to the base
+// with JSON merge patch semantics (RFC 7386) and encodes the result. Patch
+// values replace base values and slices are replaced as a whole, patch
+// objects are applied key by key, and null values delete their key from the
+// base. Null values are written with the null sentinel, so deleting keys
+// needs WithNullSentinel or WithEmptyAsNull.
+//
+// Parameters:
+//   - base: Values to patch
+//   - patch: Patch values
+//
+// Returns:
+//   - url.Values: Patched values
+//   - error: Error
+func (e URLEncoder) ApplyPatch(
+	base url.Values, patch url.Values,
+) (url.Values, error) {
+	baseData, err := e.decode(base, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode base: %w", err)
+	}
+	patchData, err := e.decode(patch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode patch: %w", err)
+	}
+	return e.Encode(mergePatch(baseData, patchData))
+}
+
+// mergePatch applies a decoded patch to decoded data and returns the data.
+func mergePatch(data map[string]any, patch map[string]any) map[string]any {
+	for key, value := range patch {
+		switch v := value.(type) {
+		case nil:
+			delete(data, key)
+		case map[string]any:
+			target, ok := data[key].(map[string]any)
+			if !ok {
+				target = make(map[string]any)
+			}
+			data[key] = mergePatch(target, v)
+		default:
+			data[key] = value
+		}
+	}
+	return data
+}
@@ -0,0 +1,57 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestApplyPatch verifies that patch values override base values, that
+// objects are patched key by key and that null values delete keys.
+func TestApplyPatch(t *testing.T) {
+	base := url.Values{
+		"page":        {"1"},
+		"sort.field":  {"name"},
+		"sort.order":  {"asc"},
+		"tags[0]":     {"a"},
+		"tags[1]":     {"b"},
+		"filter.role": {"admin"},
+		"filter.name": {"Ada"},
+	}
+	patch := url.Values{
+		"page":        {"2"},
+		"sort.order":  {"desc"},
+		"tags[0]":     {"c"},
+		"filter.role": {"null"},
+		"view":        {"grid"},
+	}
+	encoder := NewURLEncoder(WithNullSentinel("null"))
+	got, err := encoder.ApplyPatch(base, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"page":        {"2"},
+		"sort.field":  {"name"},
+		"sort.order":  {"desc"},
+		"tags[0]":     {"c"},
+		"filter.name": {"Ada"},
+		"view":        {"grid"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	got, err = encoder.ApplyPatch(base, url.Values{"sort": {"null"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := got["sort.field"]; ok {
+		t.Errorf("expected sort to be deleted, got %v", got)
+	}
+
+	_, err = encoder.ApplyPatch(base, url.Values{"a[x]": {"1"}})
+	if err == nil {
+		t.Error("expected error for invalid patch, got nil")
+	}
+}
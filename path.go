@@ -0,0 +1,259 @@
+package urlcodec
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a segment of a path into decoded data.
+type pathSegment struct {
+	name  string // Key name, or the index of a slice element
+	index bool   // Segment was written as "[n]" and may index a slice
+}
+
+// GetPath returns the value at a path into decoded data, e.g.
+// "user.emails[1]". Paths use dots for object keys and brackets for slice
+// indices, and bracket notation such as "user[emails][1]" is accepted as
+// well. Objects may be maps or OrderedMap values.
+//
+// Parameters:
+//   - data: Decoded data
+//   - path: Path to the value
+//
+// Returns:
+//   - any: Value at the path
+//   - bool: Whether the path exists
+func GetPath(data map[string]any, path string) (any, bool) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, false
+	}
+	var value any = data
+	for _, segment := range segments {
+		var ok bool
+		if value, ok = getChild(value, segment); !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// SetPath sets the value at a path into decoded data, creating missing
+// objects and slices on the way. A segment written as an index creates a
+// slice and setting an index beyond the end of a slice pads it with nil
+// elements.
+//
+// Parameters:
+//   - data: Decoded data
+//   - path: Path to the value
+//   - value: Value to set
+//
+// Returns:
+//   - error: Error if the path is invalid or crosses a non-container value
+func SetPath(data map[string]any, path string, value any) error {
+	if data == nil {
+		return fmt.Errorf("cannot set %q in nil map", path)
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if _, err := setChild(data, segments, 0, value); err != nil {
+		return fmt.Errorf("cannot set %q: %w", path, err)
+	}
+	return nil
+}
+
+// DeletePath removes the value at a path from decoded data. Slice elements
+// are removed and the following elements move down.
+//
+// Parameters:
+//   - data: Decoded data
+//   - path: Path to the value
+//
+// Returns:
+//   - bool: Whether a value was removed
+func DeletePath(data map[string]any, path string) bool {
+	segments, err := parsePath(path)
+	if err != nil {
+		return false
+	}
+	_, ok := deleteChild(data, segments)
+	return ok
+}
+
+// parsePath splits a path into its segments, e.g. "a.b[0]" or "a[b][0]"
+// gives "a", "b" and index "0".
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		name, rest, found := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("empty segment in path %q", path)
+		}
+		segments = append(segments, pathSegment{name: name})
+		for found {
+			var ok bool
+			name, rest, ok = strings.Cut(rest, "]")
+			if !ok || name == "" {
+				return nil, ErrInvalidIndex{Key: path}
+			}
+			segments = append(segments, pathSegment{
+				name: name, index: isIndex(name),
+			})
+			if rest == "" {
+				break
+			}
+			if rest[0] != '[' {
+				return nil, ErrInvalidIndex{Key: path}
+			}
+			rest = rest[1:]
+		}
+	}
+	return segments, nil
+}
+
+// pathString formats segments as a path with dots and brackets.
+func pathString(segments []pathSegment) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		switch {
+		case segment.index:
+			b.WriteString("[" + segment.name + "]")
+		case i > 0:
+			b.WriteString("." + segment.name)
+		default:
+			b.WriteString(segment.name)
+		}
+	}
+	return b.String()
+}
+
+// getChild returns the child of a container at a segment.
+func getChild(container any, segment pathSegment) (any, bool) {
+	switch c := container.(type) {
+	case map[string]any:
+		value, ok := c[segment.name]
+		return value, ok
+	case *OrderedMap:
+		return c.Get(segment.name)
+	case []any:
+		i, err := strconv.Atoi(segment.name)
+		if !segment.index || err != nil || i >= len(c) {
+			return nil, false
+		}
+		return c[i], true
+	}
+	return nil, false
+}
+
+// setChild sets the value at the segments from the given position below a
+// container and returns the container, which is created if it is nil and
+// may be reallocated if it is a slice.
+func setChild(
+	container any, segments []pathSegment, pos int, value any,
+) (any, error) {
+	if pos == len(segments) {
+		return value, nil
+	}
+	segment := segments[pos]
+	if container == nil {
+		if segment.index {
+			container = []any{}
+		} else {
+			container = make(map[string]any)
+		}
+	}
+	switch c := container.(type) {
+	case map[string]any:
+		child, err := setChild(c[segment.name], segments, pos+1, value)
+		if err != nil {
+			return nil, err
+		}
+		c[segment.name] = child
+		return c, nil
+	case *OrderedMap:
+		old, _ := c.Get(segment.name)
+		child, err := setChild(old, segments, pos+1, value)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(segment.name, child)
+		return c, nil
+	case []any:
+		if !segment.index {
+			return nil, ErrConflictingKey{Key: pathString(segments[:pos])}
+		}
+		i, err := strconv.Atoi(segment.name)
+		if err != nil || i >= maxSliceSize {
+			return nil, fmt.Errorf(
+				"%w of %d at %q", ErrMaxSliceSize, maxSliceSize,
+				pathString(segments[:pos+1]),
+			)
+		}
+		if i >= len(c) {
+			c = append(c, make([]any, i+1-len(c))...)
+		}
+		child, err := setChild(c[i], segments, pos+1, value)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = child
+		return c, nil
+	}
+	return nil, ErrConflictingKey{Key: pathString(segments[:pos])}
+}
+
+// deleteChild removes the value at the segments below a container and
+// returns the container, which may be reallocated if it is a slice.
+func deleteChild(container any, segments []pathSegment) (any, bool) {
+	segment := segments[0]
+	if len(segments) > 1 {
+		child, ok := getChild(container, segment)
+		if !ok {
+			return container, false
+		}
+		child, ok = deleteChild(child, segments[1:])
+		if !ok {
+			return container, false
+		}
+		replaceChild(container, segment, child)
+		return container, true
+	}
+	switch c := container.(type) {
+	case map[string]any:
+		_, ok := c[segment.name]
+		delete(c, segment.name)
+		return c, ok
+	case *OrderedMap:
+		_, ok := c.Get(segment.name)
+		c.Delete(segment.name)
+		return c, ok
+	case []any:
+		i, err := strconv.Atoi(segment.name)
+		if !segment.index || err != nil || i >= len(c) {
+			return c, false
+		}
+		return slices.Delete(c, i, i+1), true
+	}
+	return container, false
+}
+
+// replaceChild replaces the existing child of a container at a segment, so
+// that slices reallocated by deleteChild are stored again.
+func replaceChild(container any, segment pathSegment, child any) {
+	switch c := container.(type) {
+	case map[string]any:
+		c[segment.name] = child
+	case *OrderedMap:
+		c.Set(segment.name, child)
+	case []any:
+		i, _ := strconv.Atoi(segment.name)
+		c[i] = child
+	}
+}
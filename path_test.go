@@ -0,0 +1,101 @@
+package urlcodec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestGetPath verifies that values are found by dot and bracket paths and
+// that missing or invalid paths report false.
+func TestGetPath(t *testing.T) {
+	data, err := NewURLEncoder().DecodeString(
+		"user.name=Ada&user.emails[0]=a@x&user.emails[1]=b@x",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		path     string
+		expected any
+		ok       bool
+	}{
+		{"user.name", "Ada", true},
+		{"user.emails[1]", "b@x", true},
+		{"user[emails][0]", "a@x", true},
+		{"user.emails[2]", nil, false},
+		{"user.name.first", nil, false},
+		{"user.emails.x", nil, false},
+		{"user..name", nil, false},
+		{"user.emails[1", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := GetPath(data, tt.path)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("GetPath(%q) = %v, %v; expected %v, %v",
+				tt.path, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// TestSetPath verifies that values are set with missing objects and
+// slices created, and that paths through scalars are rejected.
+func TestSetPath(t *testing.T) {
+	data := map[string]any{"user": map[string]any{"name": "Ada"}}
+	for path, value := range map[string]any{
+		"user.emails[1]":    "b@x",
+		"user.name":         "Bob",
+		"items[0].id":       "1",
+		"user.address.city": "Turku",
+	} {
+		if err := SetPath(data, path, value); err != nil {
+			t.Fatalf("unexpected error for %q: %v", path, err)
+		}
+	}
+	expected := map[string]any{
+		"user": map[string]any{
+			"name":    "Bob",
+			"emails":  []any{nil, "b@x"},
+			"address": map[string]any{"city": "Turku"},
+		},
+		"items": []any{map[string]any{"id": "1"}},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+
+	err := SetPath(data, "user.name.first", "Ada")
+	var conflict ErrConflictingKey
+	if !errors.As(err, &conflict) || conflict.Key != "user.name" {
+		t.Errorf("expected conflict at user.name, got %v", err)
+	}
+	err = SetPath(data, "items[100000]", "x")
+	if !errors.Is(err, ErrMaxSliceSize) {
+		t.Errorf("expected ErrMaxSliceSize, got %v", err)
+	}
+}
+
+// TestDeletePath verifies that map keys and slice elements are removed.
+func TestDeletePath(t *testing.T) {
+	data := map[string]any{
+		"user": map[string]any{
+			"name":   "Ada",
+			"emails": []any{"a@x", "b@x", "c@x"},
+		},
+	}
+	if !DeletePath(data, "user.emails[1]") {
+		t.Error("expected user.emails[1] to be deleted")
+	}
+	if !DeletePath(data, "user.name") {
+		t.Error("expected user.name to be deleted")
+	}
+	if DeletePath(data, "user.name") || DeletePath(data, "user.emails[5]") {
+		t.Error("expected missing paths not to be deleted")
+	}
+	expected := map[string]any{
+		"user": map[string]any{"emails": []any{"a@x", "c@x"}},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+}
@@ -0,0 +1,40 @@
+package urlcodec
+
+import "strings"
+
+// cutKeyPrefix returns a key without a prefix that is followed by a nested
+// segment in dot or bracket notation, e.g. "filter.name" and "filter[name]"
+// both give "name". It returns false if the key is not nested under the
+// prefix.
+func cutKeyPrefix(key string, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok || len(rest) < 2 {
+		return "", false
+	}
+	switch rest[0] {
+	case '.':
+		return rest[1:], true
+	case '[':
+		segment, after, ok := strings.Cut(rest[1:], "]")
+		if !ok || segment == "" {
+			return "", false
+		}
+		return segment + after, true
+	}
+	return "", false
+}
+
+// stripKeyPrefix returns the entries of a map whose keys are nested under a
+// prefix, keyed without the prefix. Entries of keys that give the same key
+// are concatenated.
+func stripKeyPrefix[S ~[]E, E any](
+	m map[string]S, prefix string,
+) map[string]S {
+	stripped := make(map[string]S, len(m))
+	for key, vals := range m {
+		if key, ok := cutKeyPrefix(key, prefix); ok {
+			stripped[key] = append(stripped[key], vals...)
+		}
+	}
+	return stripped
+}
@@ -0,0 +1,88 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestWithPrefix verifies that encoded keys are nested under the prefix in
+// both notations and that decoding strips it and ignores other keys.
+func TestWithPrefix(t *testing.T) {
+	type Filter struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+		Age  int      `json:"age"`
+	}
+	filter := Filter{Name: "Ada", Tags: []string{"a", "b"}, Age: 36}
+	tests := []struct {
+		notation Notation
+		expected url.Values
+	}{
+		{DotNotation, url.Values{
+			"filter.name":    {"Ada"},
+			"filter.tags[0]": {"a"},
+			"filter.tags[1]": {"b"},
+			"filter.age":     {"36"},
+		}},
+		{BracketNotation, url.Values{
+			"filter[name]":    {"Ada"},
+			"filter[tags][0]": {"a"},
+			"filter[tags][1]": {"b"},
+			"filter[age]":     {"36"},
+		}},
+	}
+	for _, tt := range tests {
+		encoder := NewURLEncoder(
+			WithPrefix("filter"), WithNotation(tt.notation),
+		)
+		values, err := encoder.Encode(filter)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("expected %v, got %v", tt.expected, values)
+		}
+
+		values.Set("page", "2")
+		values.Set("filterx", "1")
+		var decoded Filter
+		if err := encoder.DecodeInto(values, &decoded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, filter) {
+			t.Errorf("expected %+v, got %+v", filter, decoded)
+		}
+	}
+
+	encoder := NewURLEncoder(WithPrefix("filter"))
+	values := url.Values{"filter[name]": {"Ada"}, "sort": {"name"}}
+	data, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(data, map[string]any{"name": "Ada"}) {
+		t.Errorf("expected map[name:Ada], got %v", data)
+	}
+
+	ordered, err := encoder.DecodeOrdered("sort=x&filter.b=1&filter.a=2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := ordered.Keys(); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("expected keys [b a], got %v", keys)
+	}
+
+	pairs, errFn := encoder.Pairs(map[string]any{"b": "1", "a": "2"})
+	var keys []string
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	if err := errFn(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(keys, []string{"filter.a", "filter.b"}) {
+		t.Errorf("expected keys [filter.a filter.b], got %v", keys)
+	}
+}
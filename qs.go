@@ -0,0 +1,296 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"mime/multipart"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	qsDefaultArrayLimit = 20 // Default arrayLimit of the qs library
+	qsDefaultDepth      = 5  // Default depth of the qs library
+)
+
+// QSOptions configures the compatibility mode with the npm qs library. Zero
+// values use the defaults of qs.
+type QSOptions struct {
+	// AllowDots accepts and writes dot notation for nested objects, e.g.
+	// "a.b=c", like the qs allowDots option. Otherwise dots are literal
+	// characters of key names.
+	AllowDots bool
+	// ArrayLimit is the highest index decoded as an array index. Higher
+	// indices become object keys. The default is 20.
+	ArrayLimit int
+	// Depth is the maximum number of bracket segments parsed in a key. The
+	// rest of the key is kept as a single literal segment. The default is 5.
+	Depth int
+}
+
+// qsSegment is a segment of a key parsed with qs semantics.
+type qsSegment struct {
+	name      string // Segment name without brackets
+	bracketed bool   // Segment was written in brackets
+}
+
+// decodeQS decodes URL values with the semantics of the qs library: bracket
+// nesting, indices above the array limit as object keys, empty brackets and
+// repeated keys appended to arrays, and sparse arrays compacted.
+func (e *URLEncoder) decodeQS(
+	values url.Values, files map[string][]*multipart.FileHeader,
+) (map[string]any, error) {
+	data := make(map[string]any)
+	errs := errorCollector{collect: e.collectErrors}
+	// decodeURL has already checked the number of keys.
+	budget := e.newDecodeBudget()
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if key == "" {
+			continue
+		}
+		name, hint := e.splitTypeHint(key)
+		segments := e.qsKeySegments(name)
+		for _, value := range values[key] {
+			if err := budget.charge(name, value); err != nil {
+				return nil, err
+			}
+			typed, err := e.decodedValue(value, hint)
+			if err != nil {
+				err = fmt.Errorf("invalid value of %q: %w", key, err)
+			} else if _, err = e.mergeQS(data, segments, typed); err != nil {
+				err = qsKeyError(err, key)
+			}
+			if errs.add(err) {
+				return nil, err
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(files)) {
+		if key == "" {
+			continue
+		}
+		segments := e.qsKeySegments(key)
+		value := fileValue(files[key])
+		if _, err := e.mergeQS(data, segments, value); err != nil {
+			if err = qsKeyError(err, key); errs.add(err) {
+				return nil, err
+			}
+		}
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	convertMinSlicesToRegularSlices(data)
+	return data, nil
+}
+
+// qsKeyError adds the key to an error returned when merging the key.
+func qsKeyError(err error, key string) error {
+	if keyed := withKey(err, key); keyed != err {
+		return keyed
+	}
+	return fmt.Errorf("invalid key %q: %w", key, err)
+}
+
+// qsKeySegments splits a key into segments like qs: the part before the
+// first bracket group is the parent, followed by up to Depth bracket groups
+// and the remainder of the key as a single segment.
+func (e *URLEncoder) qsKeySegments(key string) []qsSegment {
+	if e.qs.AllowDots {
+		key = qsDotsToBrackets(key)
+	}
+	var segments []qsSegment
+	start, end := qsFindBracket(key, 0)
+	parent := key
+	if start >= 0 {
+		parent = key[:start]
+	}
+	if parent != "" {
+		segments = append(segments, qsSegment{name: parent})
+	}
+	depth := e.qs.Depth
+	if depth <= 0 {
+		depth = qsDefaultDepth
+	}
+	for i := 0; start >= 0 && i < depth; i++ {
+		segments = append(segments, qsSegment{
+			name: key[start+1 : end-1], bracketed: true,
+		})
+		start, end = qsFindBracket(key, end)
+	}
+	if start >= 0 {
+		segments = append(segments, qsSegment{
+			name: key[start:], bracketed: true,
+		})
+	}
+	return segments
+}
+
+// qsFindBracket returns the start and end of the next bracket group without
+// nested brackets, e.g. "[b]", at or after from. It returns -1 if there is
+// none.
+func qsFindBracket(key string, from int) (int, int) {
+	for i := from; i < len(key); i++ {
+		if key[i] != '[' {
+			continue
+		}
+		j := strings.IndexAny(key[i+1:], "[]")
+		if j >= 0 && key[i+1+j] == ']' {
+			return i, i + j + 2
+		}
+	}
+	return -1, -1
+}
+
+// qsDotsToBrackets rewrites dot segments to bracket segments, e.g. "a.b[c]"
+// gives "a[b][c]", like qs does with allowDots.
+func qsDotsToBrackets(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] != '.' {
+			b.WriteByte(key[i])
+			continue
+		}
+		j := strings.IndexAny(key[i+1:], ".[")
+		if j < 0 {
+			j = len(key) - i - 1
+		}
+		if j == 0 {
+			b.WriteByte('.')
+			continue
+		}
+		b.WriteString("[" + key[i+1:i+1+j] + "]")
+		i += j
+	}
+	return b.String()
+}
+
+// qsIndex returns the array index of a segment. Empty brackets return -1
+// meaning append. It returns false if the segment is an object key.
+func (e *URLEncoder) qsIndex(segment qsSegment) (int, bool) {
+	if !segment.bracketed {
+		return 0, false
+	}
+	if segment.name == "" {
+		return -1, true
+	}
+	index, err := strconv.Atoi(segment.name)
+	if err != nil || index < 0 || strconv.Itoa(index) != segment.name {
+		return 0, false
+	}
+	limit := e.qs.ArrayLimit
+	if limit <= 0 {
+		limit = qsDefaultArrayLimit
+	}
+	if index > limit {
+		return 0, false
+	}
+	return index, true
+}
+
+// mergeQS places a value at the segments below an existing value and
+// returns the merged value. Repeated leaf values are combined into arrays,
+// and arrays that receive object keys are converted to objects.
+func (e *URLEncoder) mergeQS(
+	existing any, segments []qsSegment, value any,
+) (any, error) {
+	if len(segments) == 0 {
+		return mergeQSLeaf(existing, value)
+	}
+	if index, ok := e.qsIndex(segments[0]); ok {
+		return e.mergeQSIndex(existing, index, segments[1:], value)
+	}
+	m, err := qsObject(existing)
+	if err != nil {
+		return nil, err
+	}
+	name := e.unescapeMapKey(segments[0].name)
+	child, err := e.mergeQS(m[name], segments[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	m[name] = child
+	return m, nil
+}
+
+// mergeQSIndex places a value at an array index below an existing value.
+// An index of -1 appends to the array.
+func (e *URLEncoder) mergeQSIndex(
+	existing any, index int, segments []qsSegment, value any,
+) (any, error) {
+	switch ex := existing.(type) {
+	case nil:
+		existing = newMinSlice()
+	case map[string]any:
+		if index < 0 {
+			index = len(ex)
+		}
+		child, err := e.mergeQS(ex[strconv.Itoa(index)], segments, value)
+		if err != nil {
+			return nil, err
+		}
+		ex[strconv.Itoa(index)] = child
+		return ex, nil
+	case *minSlice:
+	default:
+		return nil, ErrConflictingKey{}
+	}
+	slice := existing.(*minSlice)
+	if index < 0 {
+		index = slice.next()
+	}
+	child, exists := slice.get(index)
+	if !exists {
+		if err := checkSliceSize(slice); err != nil {
+			return nil, err
+		}
+	}
+	child, err := e.mergeQS(child, segments, value)
+	if err != nil {
+		return nil, err
+	}
+	slice.set(index, child)
+	return slice, nil
+}
+
+// mergeQSLeaf combines a leaf value with an existing value.
+func mergeQSLeaf(existing any, value any) (any, error) {
+	switch ex := existing.(type) {
+	case nil:
+		return value, nil
+	case string:
+		slice := newMinSlice()
+		slice.set(0, ex)
+		slice.set(1, value)
+		return slice, nil
+	case *minSlice:
+		if err := checkSliceSize(ex); err != nil {
+			return nil, err
+		}
+		ex.set(ex.next(), value)
+		return ex, nil
+	default:
+		return nil, ErrConflictingKey{}
+	}
+}
+
+// qsObject returns an existing value as an object, converting arrays to
+// objects keyed by their indices.
+func qsObject(existing any) (map[string]any, error) {
+	switch ex := existing.(type) {
+	case nil:
+		return make(map[string]any), nil
+	case map[string]any:
+		return ex, nil
+	case *minSlice:
+		m := make(map[string]any, len(ex.elements))
+		for index, elem := range ex.elements {
+			m[strconv.Itoa(index)] = elem
+		}
+		return m, nil
+	default:
+		return nil, ErrConflictingKey{}
+	}
+}
@@ -0,0 +1,169 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestQSCompat_Decode verifies that queries decode like the qs library.
+func TestQSCompat_Decode(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     QSOptions
+		query    string
+		expected map[string]any
+	}{
+		{
+			name:  "nested brackets",
+			query: "a[b][c]=d",
+			expected: map[string]any{
+				"a": map[string]any{"b": map[string]any{"c": "d"}},
+			},
+		},
+		{
+			name:     "literal dots",
+			query:    "a.b=c",
+			expected: map[string]any{"a.b": "c"},
+		},
+		{
+			name:  "allow dots",
+			opts:  QSOptions{AllowDots: true},
+			query: "a.b=c&a.d[0]=e",
+			expected: map[string]any{
+				"a": map[string]any{"b": "c", "d": []any{"e"}},
+			},
+		},
+		{
+			name:     "sparse array",
+			query:    "a[1]=b&a[3]=c",
+			expected: map[string]any{"a": []any{"b", "c"}},
+		},
+		{
+			name:  "array limit",
+			query: "a[21]=b",
+			expected: map[string]any{
+				"a": map[string]any{"21": "b"},
+			},
+		},
+		{
+			name:     "custom array limit",
+			opts:     QSOptions{ArrayLimit: 100},
+			query:    "a[21]=b",
+			expected: map[string]any{"a": []any{"b"}},
+		},
+		{
+			name:     "empty brackets",
+			query:    "a[]=b&a[]=c",
+			expected: map[string]any{"a": []any{"b", "c"}},
+		},
+		{
+			name:     "repeated keys",
+			query:    "a=b&a=c",
+			expected: map[string]any{"a": []any{"b", "c"}},
+		},
+		{
+			name:  "mixed array and object",
+			query: "a[0]=b&a[x]=c",
+			expected: map[string]any{
+				"a": map[string]any{"0": "b", "x": "c"},
+			},
+		},
+		{
+			name:  "array of objects",
+			query: "a[1][b]=c&a[0][b]=d",
+			expected: map[string]any{
+				"a": []any{
+					map[string]any{"b": "d"},
+					map[string]any{"b": "c"},
+				},
+			},
+		},
+		{
+			name:  "depth",
+			query: "a[b][c][d][e][f][g][h]=i",
+			expected: map[string]any{
+				"a": map[string]any{"b": map[string]any{
+					"c": map[string]any{"d": map[string]any{
+						"e": map[string]any{"f": map[string]any{
+							"[g][h]": "i",
+						}},
+					}},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			encoder := NewURLEncoder(WithQSCompat(tt.opts))
+			decoded, err := encoder.Decode(values)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, decoded)
+			}
+		})
+	}
+}
+
+// TestQSCompat_Conflict verifies that a scalar and an object at the same key
+// are rejected.
+func TestQSCompat_Conflict(t *testing.T) {
+	values, err := url.ParseQuery("a=b&a[c]=d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoder := NewURLEncoder(WithQSCompat(QSOptions{}))
+	if _, err := encoder.Decode(values); err == nil {
+		t.Fatal("expected error for conflicting keys, got nil")
+	}
+}
+
+// TestQSCompat_MaxSliceSize verifies that repeated keys are limited to the
+// maximum slice size.
+func TestQSCompat_MaxSliceSize(t *testing.T) {
+	values := url.Values{"a": make([]string, maxSliceSize+1)}
+	encoder := NewURLEncoder(WithQSCompat(QSOptions{}))
+	if _, err := encoder.Decode(values); !errors.Is(err, ErrMaxSliceSize) {
+		t.Errorf("expected ErrMaxSliceSize, got %v", err)
+	}
+	values["a"] = values["a"][:maxSliceSize]
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(decoded["a"].([]any)); got != maxSliceSize {
+		t.Errorf("expected %d elements, got %d", maxSliceSize, got)
+	}
+}
+
+// TestQSCompat_Encode verifies that encoding uses the qs stringify format.
+func TestQSCompat_Encode(t *testing.T) {
+	data := map[string]any{
+		"a": map[string]any{"b": "c", "d": []string{"e"}},
+	}
+	values, err := NewURLEncoder(WithQSCompat(QSOptions{})).Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{"a[b]": {"c"}, "a[d][0]": {"e"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	encoder := NewURLEncoder(WithQSCompat(QSOptions{AllowDots: true}))
+	values, err = encoder.Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = url.Values{"a.b": {"c"}, "a.d[0]": {"e"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
@@ -0,0 +1,104 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DecodeString parses a raw query string, such as "a.b=1&c[0]=2", and
+// decodes it like Decode. A leading "?" is not allowed; pass only the query
+// component.
+//
+// Parameters:
+//   - query: Raw query string
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error
+func (e URLEncoder) DecodeString(query string) (map[string]any, error) {
+	values, err := e.query.parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse query %q: %w", query, err)
+	}
+	return e.Decode(values)
+}
+
+// DecodeURL extracts the query component of an absolute or relative URL,
+// such as "https://example.com/p?a.b=1" or "/p?a.b=1", and decodes it like
+// Decode. Errors state whether the URL itself, its query or the decoded
+// structure was invalid.
+//
+// Parameters:
+//   - rawURL: URL string
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error
+func (e URLEncoder) DecodeURL(rawURL string) (map[string]any, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse URL: %w", err)
+	}
+	values, err := e.query.parse(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot parse query of URL %q: %w", rawURL, err,
+		)
+	}
+	data, err := e.Decode(values)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"cannot decode query of URL %q: %w", rawURL, err,
+		)
+	}
+	return data, nil
+}
+
+// joinPairs writes URL values as "key=value" pairs separated by "&" with
+// keys sorted like in EncodeToString. Keys and values are percent-encoded
+// except for unreserved characters and the bytes in keep.
+func joinPairs(values url.Values, keep string) string {
+	var b strings.Builder
+	for _, key := range slices.SortedFunc(maps.Keys(values), compareKeys) {
+		for _, value := range values[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			writeEscapedKeep(&b, key, keep)
+			b.WriteByte('=')
+			writeEscapedKeep(&b, value, keep)
+		}
+	}
+	return b.String()
+}
+
+// splitPairs parses "key=value" pairs separated by sep into URL values.
+// Keys are percent-decoded without decoding "+" as a space and values are
+// decoded with unescapeValue, a pair without "=" has an empty value and
+// pairs with empty keys are skipped.
+func splitPairs(
+	raw string, sep string, unescapeValue func(string) (string, error),
+) (url.Values, error) {
+	values := url.Values{}
+	if raw == "" {
+		return values, nil
+	}
+	for _, pair := range strings.Split(raw, sep) {
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.PathUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err = unescapeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			values.Add(key, value)
+		}
+	}
+	return values, nil
+}
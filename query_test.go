@@ -0,0 +1,205 @@
+package urlcodec
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDecodeString verifies that a raw query string is parsed and decoded.
+func TestDecodeString(t *testing.T) {
+	encoder := NewURLEncoder()
+	got, err := encoder.DecodeString("user.name=Ada&tags%5B0%5D=a&tags[1]=b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"user": map[string]any{"name": "Ada"},
+		"tags": []any{"a", "b"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if _, err := encoder.DecodeString("a=%zz"); err == nil {
+		t.Fatal("expected error for invalid escape, got nil")
+	}
+}
+
+// TestDecodeURL verifies that the query of absolute and relative URLs is
+// decoded and that the error kinds are distinguished.
+func TestDecodeURL(t *testing.T) {
+	encoder := NewURLEncoder()
+	expected := map[string]any{"a": map[string]any{"b": "1"}}
+	for _, rawURL := range []string{
+		"https://example.com/path?a.b=1#frag",
+		"/path?a.b=1",
+		"?a.b=1",
+	} {
+		got, err := encoder.DecodeURL(rawURL)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", rawURL, err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q: expected %v, got %v", rawURL, expected, got)
+		}
+	}
+
+	tests := []struct {
+		rawURL string
+		prefix string
+	}{
+		{"http://[::1", "cannot parse URL"},
+		{"/p?a=%zz", "cannot parse query"},
+		{"/p?a=1&a.b=2", "cannot decode query"},
+	}
+	for _, tt := range tests {
+		_, err := encoder.DecodeURL(tt.rawURL)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Errorf("%q: expected %q error, got %v", tt.rawURL, tt.prefix, err)
+		}
+	}
+}
+
+// TestSemicolonSeparator verifies that ";" separates pairs only when
+// enabled and that the pair separator is used when encoding.
+func TestSemicolonSeparator(t *testing.T) {
+	query := "a.b=1;a.c=x%3By&tags[0]=t"
+	if _, err := NewURLEncoder().DecodeString(query); err == nil {
+		t.Fatal("expected error for semicolon separator")
+	}
+
+	encoder := NewURLEncoder(WithSemicolonSeparator(), WithPairSeparator(';'))
+	got, err := encoder.DecodeString(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"a":    map[string]any{"b": "1", "c": "x;y"},
+		"tags": []any{"t"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	encoded, err := encoder.EncodeToString(expected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "a.b=1;a.c=x%3By;tags%5B0%5D=t"; encoded != want {
+		t.Errorf("expected %s, got %s", want, encoded)
+	}
+
+	ordered, err := encoder.DecodeOrdered("z=1;a=2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := ordered.Keys(); !reflect.DeepEqual(keys, []string{"z", "a"}) {
+		t.Errorf("expected keys [z a], got %v", keys)
+	}
+
+	body, _, err := encoder.EncodeBody(expected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := io.ReadAll(body)
+	if want := "a.b=1&a.c=x%3By&tags%5B0%5D=t"; string(raw) != want {
+		t.Errorf("expected body %s, got %s", want, raw)
+	}
+}
+
+// TestSpaceEncoding verifies that spaces are written and "+" is read as
+// configured.
+func TestSpaceEncoding(t *testing.T) {
+	data := map[string]any{"q": "a b+c"}
+	tests := []struct {
+		encoding SpaceEncoding
+		encoded  string
+		decoded  string
+	}{
+		{SpacePlus, "q=a+b%2Bc", "x y"},
+		{SpacePercent, "q=a%20b%2Bc", "x+y"},
+	}
+	for _, tt := range tests {
+		encoder := NewURLEncoder(WithSpaceEncoding(tt.encoding))
+		encoded, err := encoder.EncodeToString(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if encoded != tt.encoded {
+			t.Errorf("expected %s, got %s", tt.encoded, encoded)
+		}
+		got, err := encoder.DecodeString(encoded)
+		if err != nil || !reflect.DeepEqual(got, data) {
+			t.Errorf("expected %v, got %v, %v", data, got, err)
+		}
+		got, err = encoder.DecodeString("q=x+y")
+		if err != nil || got["q"] != tt.decoded {
+			t.Errorf("expected %q, got %v, %v", tt.decoded, got["q"], err)
+		}
+	}
+
+	encoder := NewURLEncoder(
+		WithSpaceEncoding(SpacePercent), WithMaxQueryLength(10),
+	)
+	if _, err := encoder.Encode(data); !errors.Is(err, ErrMaxQueryLength) {
+		t.Errorf("expected ErrMaxQueryLength, got %v", err)
+	}
+}
+
+// TestEscapeProfile verifies that each profile keeps its characters and
+// that encoded values decode back.
+func TestEscapeProfile(t *testing.T) {
+	data := map[string]any{"a": map[string]any{"b": "x/y:z;[0]@&=+ 100%"}}
+	tests := []struct {
+		profile EscapeProfile
+		encoded string
+	}{
+		{
+			EscapeFormEncoded,
+			"a.b=x%2Fy%3Az%3B%5B0%5D%40%26%3D%2B+100%25",
+		},
+		{
+			EscapeQueryComponent,
+			"a.b=x/y:z%3B%5B0%5D@%26%3D%2B+100%25",
+		},
+		{
+			EscapePathSegment,
+			"a.b=x%2Fy:z%3B%5B0%5D@%26%3D%2B+100%25",
+		},
+		{
+			EscapeFragmentComponent,
+			"a.b=x/y:z%3B[0]@%26%3D%2B+100%25",
+		},
+	}
+	for _, tt := range tests {
+		encoder := NewURLEncoder(WithEscapeProfile(tt.profile))
+		encoded, err := encoder.EncodeToString(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if encoded != tt.encoded {
+			t.Errorf("profile %d: expected %s, got %s",
+				tt.profile, tt.encoded, encoded)
+		}
+		got, err := encoder.DecodeString(encoded)
+		if err != nil || !reflect.DeepEqual(got, data) {
+			t.Errorf("profile %d: expected %v, got %v, %v",
+				tt.profile, data, got, err)
+		}
+	}
+
+	encoder := NewURLEncoder(
+		WithEscapeProfile(EscapeQueryComponent), WithPairSeparator('/'),
+		WithSpaceEncoding(SpacePercent),
+	)
+	encoded, err := encoder.EncodeToString(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "a.b=x%2Fy:z%3B%5B0%5D@%26%3D%2B%20100%25"; encoded != want {
+		t.Errorf("expected %s, got %s", want, encoded)
+	}
+}
@@ -0,0 +1,188 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// SpaceEncoding selects how spaces are written in query strings and how "+"
+// is read.
+type SpaceEncoding int
+
+const (
+	// SpacePlus writes spaces as "+" and decodes "+" as a space, as in
+	// "application/x-www-form-urlencoded" forms.
+	SpacePlus SpaceEncoding = iota
+	// SpacePercent writes spaces as "%20" and decodes "+" as a literal
+	// plus sign, as in RFC 3986 query components.
+	SpacePercent
+)
+
+// EscapeProfile selects which characters other than unreserved ones ("-",
+// ".", "_", "~", letters and digits) are written as they are in the keys and
+// values of query strings. "&", "=", "+", "#", "%", ";" and the pair
+// separator are always percent-encoded, as Go and other servers reject ";"
+// in queries.
+type EscapeProfile int
+
+const (
+	// EscapeFormEncoded percent-encodes every other character, as in
+	// "application/x-www-form-urlencoded" forms and url.Values.Encode.
+	EscapeFormEncoded EscapeProfile = iota
+	// EscapeQueryComponent keeps the characters allowed in an RFC 3986
+	// query component, "!$'()*,/:?@".
+	EscapeQueryComponent
+	// EscapePathSegment keeps the characters allowed in an RFC 3986 path
+	// segment, "!$'()*,:@".
+	EscapePathSegment
+	// EscapeFragmentComponent keeps the characters that EncodeFragment
+	// keeps, "!$'()*,/:?@[]".
+	EscapeFragmentComponent
+)
+
+// safe returns the characters other than unreserved ones that are written
+// as they are with the profile.
+func (p EscapeProfile) safe() string {
+	switch p {
+	case EscapeQueryComponent:
+		return "!$'()*,/:?@"
+	case EscapePathSegment:
+		return "!$'()*,:@"
+	case EscapeFragmentComponent:
+		return "!$'()*,/:?@[]"
+	default:
+		return ""
+	}
+}
+
+// queryFormat describes how query strings are split into pairs and joined
+// from them. The zero value is the "application/x-www-form-urlencoded"
+// format of url.Values.
+type queryFormat struct {
+	semicolons bool          // Accept ";" as well as "&" between pairs
+	separator  byte          // Separator written between pairs, 0 for "&"
+	spaces     SpaceEncoding // Encoding of spaces and meaning of "+"
+	profile    EscapeProfile // Characters written without escaping
+}
+
+// sep returns the separator written between pairs.
+func (f queryFormat) sep() byte {
+	if f.separator == 0 {
+		return '&'
+	}
+	return f.separator
+}
+
+// escape percent-encodes a key or value.
+func (f queryFormat) escape(s string) string {
+	var b strings.Builder
+	safe := strings.ReplaceAll(f.profile.safe(), string(f.sep()), "")
+	writeEscapedKeep(&b, s, safe)
+	escaped := b.String()
+	if f.spaces == SpacePlus {
+		// A literal "%" is written as "%25", so every "%20" left is a
+		// space.
+		escaped = strings.ReplaceAll(escaped, "%20", "+")
+	}
+	return escaped
+}
+
+// unescape decodes a percent-encoded key or value.
+func (f queryFormat) unescape(s string) (string, error) {
+	if f.spaces == SpacePercent {
+		return url.PathUnescape(s)
+	}
+	return url.QueryUnescape(s)
+}
+
+// pairLength returns the length of a pair in a query string as
+// "key=value".
+func (f queryFormat) pairLength(key string, value string) int {
+	return len(f.escape(key)) + 1 + len(f.escape(value))
+}
+
+// split returns the non-empty pairs of a raw query string.
+func (f queryFormat) split(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return r == '&' || f.semicolons && r == ';'
+	})
+}
+
+// parse parses a raw query string like url.ParseQuery, accepting ";"
+// between pairs and reading "+" as configured. The first error is returned
+// along with the pairs that could be parsed.
+func (f queryFormat) parse(query string) (url.Values, error) {
+	values := url.Values{}
+	var err error
+	for _, pair := range f.split(query) {
+		if strings.Contains(pair, ";") {
+			if err == nil {
+				err = errors.New("invalid semicolon separator in query")
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, keyErr := f.unescape(key)
+		if keyErr == nil {
+			value, keyErr = f.unescape(value)
+		}
+		if keyErr != nil {
+			if err == nil {
+				err = keyErr
+			}
+			continue
+		}
+		values.Add(key, value)
+	}
+	return values, err
+}
+
+// keys returns the unescaped keys of a raw query string in the order in
+// which they appear.
+func (f queryFormat) keys(query string) []string {
+	var keys []string
+	for _, pair := range f.split(query) {
+		key, _, _ := strings.Cut(pair, "=")
+		if key, err := f.unescape(key); err == nil && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// encodeKeys encodes the values of the keys as a query string in the order
+// of the keys. Values recorded in raw are written verbatim.
+func (f queryFormat) encodeKeys(
+	values url.Values, keys []string, raw *rawValues,
+) string {
+	var b strings.Builder
+	for _, key := range keys {
+		escapedKey := f.escape(key)
+		for _, value := range values[key] {
+			if b.Len() > 0 {
+				b.WriteByte(f.sep())
+			}
+			b.WriteString(escapedKey)
+			b.WriteByte('=')
+			if rawValue, ok := raw.lookup(key, value); ok {
+				b.WriteString(rawValue)
+			} else {
+				b.WriteString(f.escape(value))
+			}
+		}
+	}
+	return b.String()
+}
+
+// encodeSorted encodes URL values as a query string with keys sorted by
+// compareKeys. Values recorded in raw are written verbatim.
+func (f queryFormat) encodeSorted(values url.Values, raw *rawValues) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, compareKeys)
+	return f.encodeKeys(values, keys, raw)
+}
@@ -0,0 +1,86 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var rawValueType = reflect.TypeFor[RawValue]()
+
+// RawValue is a value that is already percent-encoded, such as a signature
+// or token that a service expects in one exact representation.
+// EncodeToString, EncodeOrdered, EncodeBody and ApplyToRequest write it
+// verbatim instead of escaping it again. Encode returns the unescaped value
+// in url.Values, so that decoding gives the same value. Value transformers
+// are not applied to raw values. A raw value must not contain spaces,
+// control characters, non-ASCII bytes, "&", ";", "#" or the pair separator,
+// and its escapes must be valid.
+type RawValue string
+
+// rawPair is a key and unescaped value written from a RawValue.
+type rawPair struct {
+	key   string // Encoded key
+	value string // Unescaped value
+}
+
+// rawValues records the raw form of values written from RawValue values,
+// so that query strings can write them verbatim. It is safe for concurrent
+// use by parallel encoding.
+type rawValues struct {
+	mu  sync.Mutex         // Guards raw
+	raw map[rawPair]string // Raw values by key and unescaped value
+}
+
+// add records the raw form of a value. It does nothing on nil rawValues, so
+// that recording is disabled by default.
+func (r *rawValues) add(key string, value string, raw string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.raw == nil {
+		r.raw = make(map[rawPair]string)
+	}
+	r.raw[rawPair{key: key, value: value}] = raw
+}
+
+// lookup returns the raw form of a value. It is safe on nil rawValues.
+func (r *rawValues) lookup(key string, value string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	raw, ok := r.raw[rawPair{key: key, value: value}]
+	return raw, ok
+}
+
+// encodeRawValue encodes a RawValue as its unescaped value and records its
+// raw form.
+func (e *URLEncoder) encodeRawValue(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	raw := v.String()
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if c <= ' ' || c >= 0x7f || c == e.query.sep() ||
+			strings.IndexByte("&;#", c) >= 0 {
+			return fmt.Errorf(
+				"invalid character %q in raw value at %q", c, fieldTag,
+			)
+		}
+	}
+	value, err := e.query.unescape(raw)
+	if err != nil {
+		return fmt.Errorf("invalid raw value at %q: %w", fieldTag, err)
+	}
+	key := e.hintKey(fieldTag, hintString)
+	values.Set(key, value)
+	e.order.add(key)
+	e.raw.add(key, value, raw)
+	return nil
+}
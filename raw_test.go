@@ -0,0 +1,65 @@
+package urlcodec
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestRawValue verifies that raw values are written verbatim by query
+// string encoders and unescaped by Encode.
+func TestRawValue(t *testing.T) {
+	data := map[string]any{
+		"sig":  RawValue("ab%2Bc%2f=="),
+		"note": "ab+c",
+	}
+	encoder := NewURLEncoder()
+	encoded, err := encoder.EncodeToString(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "note=ab%2Bc&sig=ab%2Bc%2f=="; encoded != want {
+		t.Errorf("expected %s, got %s", want, encoded)
+	}
+
+	values, err := encoder.Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("sig"); got != "ab+c/==" {
+		t.Errorf("expected unescaped value ab+c/==, got %s", got)
+	}
+	decoded, err := encoder.DecodeString(encoded)
+	if err != nil || decoded["sig"] != "ab+c/==" {
+		t.Errorf("expected sig ab+c/==, got %v, %v", decoded, err)
+	}
+
+	ordered, err := encoder.EncodeOrdered(struct {
+		Token RawValue `json:"token"`
+	}{Token: "a%7Eb"})
+	if err != nil || ordered != "token=a%7Eb" {
+		t.Errorf("expected token=a%%7Eb, got %s, %v", ordered, err)
+	}
+
+	req, err := encoder.NewRequest(
+		context.Background(), http.MethodGet, "https://example.com/p", data,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "note=ab%2Bc&sig=ab%2Bc%2f=="; req.URL.RawQuery != want {
+		t.Errorf("expected query %s, got %s", want, req.URL.RawQuery)
+	}
+}
+
+// TestRawValue_Invalid verifies that raw values that would break the query
+// string are rejected.
+func TestRawValue_Invalid(t *testing.T) {
+	encoder := NewURLEncoder()
+	for _, raw := range []RawValue{"a&b", "a b", "a;b", "a#b", "%zz", "é"} {
+		data := map[string]any{"a": raw}
+		if _, err := encoder.EncodeToString(data); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
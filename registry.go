@@ -0,0 +1,49 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// EncoderFunc renders a value of a registered type as a single URL value.
+type EncoderFunc func(v any) (string, error)
+
+// RegisterEncoder registers a function that encodes values of the given
+// type as scalars. Registered types take precedence over all built-in
+// encodings, including encoding.TextMarshaler. Registering a nil function
+// removes the registration. It must not be called concurrently with
+// encoding.
+//
+// Parameters:
+//   - t: Type to register the encoder for
+//   - fn: Function that encodes values of the type
+func (e *URLEncoder) RegisterEncoder(t reflect.Type, fn EncoderFunc) {
+	if fn == nil {
+		delete(e.encoders, t)
+		return
+	}
+	if e.encoders == nil {
+		e.encoders = make(map[reflect.Type]EncoderFunc)
+	}
+	e.encoders[t] = fn
+}
+
+// encodeRegistered encodes a value with the encoder registered for its
+// type. It returns false if no encoder is registered for the type.
+func (e *URLEncoder) encodeRegistered(
+	values *url.Values, fieldTag string, v reflect.Value,
+) (bool, error) {
+	if len(e.encoders) == 0 || !v.IsValid() || !v.CanInterface() {
+		return false, nil
+	}
+	fn, ok := e.encoders[v.Type()]
+	if !ok {
+		return false, nil
+	}
+	s, err := fn(v.Interface())
+	if err != nil {
+		return true, fmt.Errorf("cannot encode %q: %w", fieldTag, err)
+	}
+	return true, e.setValue(values, fieldTag, s)
+}
@@ -0,0 +1,68 @@
+package urlcodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// money is a test type stored in cents.
+type money struct {
+	Cents int64
+}
+
+// TestRegisterEncoder verifies that registered encoders take precedence
+// over the default encoding and can be removed.
+func TestRegisterEncoder(t *testing.T) {
+	type Order struct {
+		Total   money     `json:"total"`
+		Created time.Time `json:"created"`
+	}
+	encoder := NewURLEncoder()
+	encoder.RegisterEncoder(
+		reflect.TypeFor[money](),
+		func(v any) (string, error) {
+			m := v.(money)
+			return fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100), nil
+		},
+	)
+	encoder.RegisterEncoder(
+		reflect.TypeFor[time.Time](),
+		func(v any) (string, error) {
+			return "epoch", nil
+		},
+	)
+	order := Order{Total: money{Cents: 1234}}
+	values, err := encoder.Encode(map[string]any{"order": &order})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("order.total"); got != "12.34" {
+		t.Errorf("expected order.total=12.34, got %q", got)
+	}
+	if got := values.Get("order.created"); got != "epoch" {
+		t.Errorf("expected order.created=epoch, got %q", got)
+	}
+
+	encoder.RegisterEncoder(reflect.TypeFor[money](), nil)
+	if _, err := encoder.Encode(order); err == nil {
+		t.Fatal("expected error for unregistered struct without tags, got nil")
+	}
+}
+
+// TestRegisterEncoder_Error verifies that errors from registered encoders
+// are returned.
+func TestRegisterEncoder_Error(t *testing.T) {
+	errBad := errors.New("bad money")
+	encoder := NewURLEncoder()
+	encoder.RegisterEncoder(
+		reflect.TypeFor[money](),
+		func(v any) (string, error) { return "", errBad },
+	)
+	_, err := encoder.Encode(map[string]any{"m": money{}})
+	if !errors.Is(err, errBad) {
+		t.Fatalf("expected %v, got %v", errBad, err)
+	}
+}
@@ -0,0 +1,298 @@
+package urlcodec
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaType is the expected type of a value in a Schema.
+type SchemaType int
+
+const (
+	// SchemaAny accepts any value.
+	SchemaAny SchemaType = iota
+	// SchemaString accepts scalar values.
+	SchemaString
+	// SchemaInt accepts integers and strings that parse as integers.
+	SchemaInt
+	// SchemaNumber accepts numbers and strings that parse as numbers.
+	SchemaNumber
+	// SchemaBool accepts booleans and the strings "true" and "false".
+	SchemaBool
+	// SchemaObject accepts objects, as maps or OrderedMap values.
+	SchemaObject
+	// SchemaArray accepts slices.
+	SchemaArray
+)
+
+// String returns the name of the type used in violation messages.
+//
+// Returns:
+//   - string: Type name
+func (t SchemaType) String() string {
+	switch t {
+	case SchemaString:
+		return "string"
+	case SchemaInt:
+		return "integer"
+	case SchemaNumber:
+		return "number"
+	case SchemaBool:
+		return "boolean"
+	case SchemaObject:
+		return "object"
+	case SchemaArray:
+		return "array"
+	default:
+		return "any"
+	}
+}
+
+// Schema declares the expected shape of decoded data. A Schema with Fields
+// describes an object and Validate checks decoded data against it. Zero
+// fields do not constrain values.
+type Schema struct {
+	Type         SchemaType         // Expected type
+	Required     bool               // Key must be present and not null
+	Min          *float64           // Smallest number
+	Max          *float64           // Largest number
+	MinLength    int                // Fewest characters of strings
+	MaxLength    int                // Most characters of strings, 0 for any
+	MinItems     int                // Fewest elements of arrays
+	MaxItems     int                // Most elements of arrays, 0 for any
+	Pattern      *regexp.Regexp     // Pattern that strings must match
+	Enum         []string           // Allowed values of scalars
+	Fields       map[string]*Schema // Schemas of object keys
+	Items        *Schema            // Schema of array elements
+	AllowUnknown bool               // Allow object keys not in Fields
+}
+
+// SchemaViolation is a value that does not match its Schema.
+type SchemaViolation struct {
+	Path    string // Path of the value, e.g. "user.emails[1]"
+	Message string // What is wrong, e.g. "is required"
+}
+
+// String returns the path and the message.
+//
+// Returns:
+//   - string: Violation
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + " " + v.Message
+}
+
+// Validate checks decoded data against the schema, which describes the
+// top-level object. Object keys are checked in sorted order and all
+// violations are reported.
+//
+// Parameters:
+//   - data: Decoded data
+//
+// Returns:
+//   - error: ErrSchemaViolations listing the violations, or nil
+func (s *Schema) Validate(data map[string]any) error {
+	var violations ErrSchemaViolations
+	s.validateValue(&violations, "", data)
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// validateValue appends the violations of a value to violations.
+func (s *Schema) validateValue(
+	violations *ErrSchemaViolations, path string, value any,
+) {
+	add := func(format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{
+			Path: path, Message: fmt.Sprintf(format, args...),
+		})
+	}
+	if value == nil {
+		if s.Required {
+			add("is required")
+		}
+		return
+	}
+	switch s.Type {
+	case SchemaObject:
+		if _, ok := objectEntries(value); !ok {
+			add("must be an object")
+			return
+		}
+	case SchemaArray:
+		if _, ok := value.([]any); !ok {
+			add("must be an array")
+			return
+		}
+	case SchemaString, SchemaInt, SchemaNumber, SchemaBool:
+		if !s.Type.matches(value) {
+			if s.Type == SchemaInt {
+				add("must be an %s", s.Type)
+			} else {
+				add("must be a %s", s.Type)
+			}
+			return
+		}
+	}
+	if n, ok := schemaNumber(value); ok &&
+		(s.Type == SchemaInt || s.Type == SchemaNumber) {
+		if s.Min != nil && n < *s.Min {
+			add("must be at least %s", formatNumber(*s.Min))
+		}
+		if s.Max != nil && n > *s.Max {
+			add("must be at most %s", formatNumber(*s.Max))
+		}
+	}
+	if text, ok := value.(string); ok {
+		length := utf8.RuneCountInString(text)
+		if length < s.MinLength {
+			add("must be at least %d characters long", s.MinLength)
+		}
+		if s.MaxLength > 0 && length > s.MaxLength {
+			add("must be at most %d characters long", s.MaxLength)
+		}
+		if s.Pattern != nil && !s.Pattern.MatchString(text) {
+			add("must match %s", s.Pattern)
+		}
+	}
+	if len(s.Enum) > 0 && !isContainer(value) &&
+		!slices.Contains(s.Enum, fmt.Sprint(value)) {
+		add("must be one of %s", strings.Join(s.Enum, ", "))
+	}
+	if elems, ok := value.([]any); ok {
+		s.validateItems(violations, path, elems)
+	}
+	if entries, ok := objectEntries(value); ok && s.Fields != nil {
+		s.validateFields(violations, path, entries)
+	}
+}
+
+// validateItems appends the violations of the elements of a slice.
+func (s *Schema) validateItems(
+	violations *ErrSchemaViolations, path string, elems []any,
+) {
+	if len(elems) < s.MinItems {
+		*violations = append(*violations, SchemaViolation{
+			Path:    path,
+			Message: fmt.Sprintf("must have at least %d items", s.MinItems),
+		})
+	}
+	if s.MaxItems > 0 && len(elems) > s.MaxItems {
+		*violations = append(*violations, SchemaViolation{
+			Path:    path,
+			Message: fmt.Sprintf("must have at most %d items", s.MaxItems),
+		})
+	}
+	if s.Items == nil {
+		return
+	}
+	for i, elem := range elems {
+		s.Items.validateValue(violations, fmt.Sprintf("%s[%d]", path, i), elem)
+	}
+}
+
+// validateFields appends the violations of the keys of an object.
+func (s *Schema) validateFields(
+	violations *ErrSchemaViolations, path string, entries map[string]any,
+) {
+	keys := slices.Collect(maps.Keys(entries))
+	for key := range s.Fields {
+		if _, ok := entries[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, compareKeys)
+	for _, key := range keys {
+		field, ok := s.Fields[key]
+		if !ok {
+			if !s.AllowUnknown {
+				*violations = append(*violations, SchemaViolation{
+					Path: joinPath(path, key), Message: "is not allowed",
+				})
+			}
+			continue
+		}
+		field.validateValue(violations, joinPath(path, key), entries[key])
+	}
+}
+
+// matches reports whether a non-nil value has a scalar type.
+func (t SchemaType) matches(value any) bool {
+	if isContainer(value) {
+		return false
+	}
+	text, isString := value.(string)
+	switch t {
+	case SchemaInt:
+		if isString {
+			_, err := strconv.ParseInt(text, 10, 64)
+			return err == nil && isNumberLiteral(text)
+		}
+		n, ok := schemaNumber(value)
+		return ok && n == math.Trunc(n)
+	case SchemaNumber:
+		_, ok := schemaNumber(value)
+		return ok
+	case SchemaBool:
+		if isString {
+			return text == "true" || text == "false"
+		}
+		_, ok := value.(bool)
+		return ok
+	}
+	return true
+}
+
+// schemaNumber returns the numeric value of a number or numeric string.
+func schemaNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		if !isNumberLiteral(v) {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// formatNumber formats a bound in violation messages.
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+// isContainer reports whether a value is an object or a slice.
+func isContainer(value any) bool {
+	switch value.(type) {
+	case map[string]any, *OrderedMap, []any:
+		return true
+	}
+	return false
+}
+
+// objectEntries returns the entries of a map or OrderedMap.
+func objectEntries(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case *OrderedMap:
+		return maps.Collect(v.All()), true
+	}
+	return nil, false
+}
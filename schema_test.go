@@ -0,0 +1,109 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// TestSchema_Validate verifies that violations are reported with their
+// paths in key order.
+func TestSchema_Validate(t *testing.T) {
+	minAge, maxAge := 18.0, 130.0
+	schema := &Schema{Fields: map[string]*Schema{
+		"name": {Type: SchemaString, Required: true, MaxLength: 5},
+		"age":  {Type: SchemaInt, Min: &minAge, Max: &maxAge},
+		"role": {Enum: []string{"admin", "user"}},
+		"user": {Type: SchemaObject, Fields: map[string]*Schema{
+			"emails": {
+				Type:     SchemaArray,
+				MaxItems: 2,
+				Items: &Schema{
+					Type:    SchemaString,
+					Pattern: regexp.MustCompile(`^[^@]+@[^@]+$`),
+				},
+			},
+			"active": {Type: SchemaBool, Required: true},
+		}},
+	}}
+	values, err := url.ParseQuery(
+		"name=Alexander&age=12&role=root&debug=1&user.active=yes" +
+			"&user.emails[0]=a@b.c&user.emails[1]=bad" +
+			"&user.emails[2]=c@d.e",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := NewURLEncoder().Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = schema.Validate(data)
+	var violations ErrSchemaViolations
+	if !errors.As(err, &violations) {
+		t.Fatalf("expected ErrSchemaViolations, got %v", err)
+	}
+	expected := ErrSchemaViolations{
+		{Path: "age", Message: "must be at least 18"},
+		{Path: "debug", Message: "is not allowed"},
+		{Path: "name", Message: "must be at most 5 characters long"},
+		{Path: "role", Message: "must be one of admin, user"},
+		{Path: "user.active", Message: "must be a boolean"},
+		{Path: "user.emails", Message: "must have at most 2 items"},
+		{Path: "user.emails[1]", Message: "must match ^[^@]+@[^@]+$"},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("expected %v, got %v", expected, violations)
+	}
+
+	valid := map[string]any{
+		"name": "Ann",
+		"age":  "42",
+		"user": map[string]any{"active": "true"},
+	}
+	if err := schema.Validate(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = schema.Validate(map[string]any{"name": nil})
+	if err == nil || err.Error() != "schema violations: name is required" {
+		t.Errorf("expected name to be required, got %v", err)
+	}
+}
+
+// TestSchema_Types verifies the type checks of scalar and container types,
+// including values converted by WithInferTypes.
+func TestSchema_Types(t *testing.T) {
+	tests := []struct {
+		typ   SchemaType
+		value any
+		ok    bool
+	}{
+		{SchemaInt, "42", true},
+		{SchemaInt, 42, true},
+		{SchemaInt, "4.2", false},
+		{SchemaInt, 4.0, true},
+		{SchemaNumber, "4.2", true},
+		{SchemaNumber, "x", false},
+		{SchemaBool, "true", true},
+		{SchemaBool, false, true},
+		{SchemaBool, "yes", false},
+		{SchemaString, "x", true},
+		{SchemaString, []any{"x"}, false},
+		{SchemaArray, "x", false},
+		{SchemaArray, []any{"x"}, true},
+		{SchemaObject, NewOrderedMap(), true},
+		{SchemaObject, "x", false},
+		{SchemaAny, []any{}, true},
+	}
+	for _, tt := range tests {
+		schema := &Schema{Fields: map[string]*Schema{"v": {Type: tt.typ}}}
+		err := schema.Validate(map[string]any{"v": tt.value})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s %#v: expected ok %v, got %v", tt.typ, tt.value,
+				tt.ok, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package urlcodec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrInvalidSealedValue is returned by Sealer.Open when a sealed value is
+// malformed, was sealed with another key or was tampered with.
+var ErrInvalidSealedValue = errors.New("invalid sealed value")
+
+// Sealer encrypts encoded data into a single opaque value and decrypts it
+// again, so that state such as user IDs or internal filters can travel in
+// URLs without clients reading or changing it. Values are sealed with
+// AES-GCM, which authenticates them, and a random nonce, so that sealing
+// the same data twice gives different values.
+type Sealer struct {
+	aead    cipher.AEAD
+	encoder *URLEncoder
+}
+
+// NewSealer returns a new Sealer.
+//
+// Parameters:
+//   - key: Secret AES key of 16, 24 or 32 bytes
+//   - opts: Options to configure the encoder used by Seal and Open
+//
+// Returns:
+//   - *Sealer: The sealer
+//   - error: Error if the key has an invalid length
+func NewSealer(key []byte, opts ...Option) (*Sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create sealer: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create sealer: %w", err)
+	}
+	return &Sealer{aead: aead, encoder: NewURLEncoder(opts...)}, nil
+}
+
+// Seal encodes data like EncodeToString and encrypts the query string into
+// an unpadded base64url value for a single parameter.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - string: Sealed value
+//   - error: Error
+func (s *Sealer) Seal(data any) (string, error) {
+	query, err := s.encoder.EncodeToString(data)
+	if err != nil {
+		return "", err
+	}
+	size := s.aead.NonceSize()
+	nonce := make([]byte, size, size+len(query)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("cannot create nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(query), nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed by Seal and decodes the query string in it
+// like DecodeString. It returns an error wrapping ErrInvalidSealedValue if
+// the value cannot be decrypted.
+//
+// Parameters:
+//   - value: Sealed value
+//
+// Returns:
+//   - map[string]any: Decoded data
+//   - error: Error
+func (s *Sealer) Open(value string) (map[string]any, error) {
+	values, err := s.openValues(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.encoder.Decode(values)
+}
+
+// OpenInto decrypts a value sealed by Seal and decodes the query string in
+// it into the value pointed to by target, like DecodeInto.
+//
+// Parameters:
+//   - value: Sealed value
+//   - target: Non-nil pointer to the value to populate
+//
+// Returns:
+//   - error: Error
+func (s *Sealer) OpenInto(value string, target any) error {
+	values, err := s.openValues(value)
+	if err != nil {
+		return err
+	}
+	return s.encoder.DecodeInto(values, target)
+}
+
+// openValues decrypts a sealed value and parses the query string in it.
+// Errors do not include the query, so that the decrypted data does not end
+// up in logs or responses.
+func (s *Sealer) openValues(value string) (url.Values, error) {
+	query, err := s.open(value)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.encoder.query.parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse sealed query: %w", err)
+	}
+	return values, nil
+}
+
+// open decrypts a sealed value and returns the query string in it.
+func (s *Sealer) open(value string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed value", ErrInvalidSealedValue)
+	}
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return "", fmt.Errorf("%w: value too short", ErrInvalidSealedValue)
+	}
+	query, err := s.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: authentication failed", ErrInvalidSealedValue,
+		)
+	}
+	return string(query), nil
+}
@@ -0,0 +1,126 @@
+package urlcodec
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSealer verifies that sealed values round trip, are opaque and differ
+// each time the same data is sealed.
+func TestSealer(t *testing.T) {
+	sealer, err := NewSealer([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := map[string]any{
+		"user":   map[string]any{"id": "42"},
+		"filter": []any{"internal"},
+	}
+	sealed, err := sealer.Seal(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(sealed, "internal") || strings.ContainsAny(
+		sealed, "+/=",
+	) {
+		t.Errorf("expected opaque URL-safe value, got %s", sealed)
+	}
+	again, err := sealer.Seal(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again == sealed {
+		t.Error("expected different values for repeated seals")
+	}
+
+	opened, err := sealer.Open(sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(opened, data) {
+		t.Errorf("expected %v, got %v", data, opened)
+	}
+
+	var target struct {
+		User struct {
+			ID int `json:"id"`
+		} `json:"user"`
+	}
+	if err := sealer.OpenInto(again, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.User.ID != 42 {
+		t.Errorf("expected user ID 42, got %d", target.User.ID)
+	}
+}
+
+// TestSealer_Invalid verifies that tampered, foreign and malformed values
+// are rejected and that invalid keys are reported.
+func TestSealer_Invalid(t *testing.T) {
+	if _, err := NewSealer([]byte("short")); err == nil {
+		t.Error("expected error for invalid key length")
+	}
+	sealer, err := NewSealer([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := NewSealer([]byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sealed, err := sealer.Seal(map[string]any{"a": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	foreign, err := other.Seal(map[string]any{"a": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw[len(raw)/2] ^= 1
+	tests := map[string]string{
+		"tampered":  base64.RawURLEncoding.EncodeToString(raw),
+		"foreign":   foreign,
+		"malformed": "%%",
+		"short":     "AAAA",
+	}
+	for name, value := range tests {
+		if _, err := sealer.Open(value); !errors.Is(
+			err, ErrInvalidSealedValue,
+		) {
+			t.Errorf("%s: expected ErrInvalidSealedValue, got %v", name, err)
+		}
+	}
+}
+
+// TestSealer_ErrorsHidePlaintext verifies that errors for sealed values
+// with a malformed query do not include the decrypted query.
+func TestSealer_ErrorsHidePlaintext(t *testing.T) {
+	sealer, err := NewSealer([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := "token=hunter2&bad=%zz"
+	nonce := make([]byte, sealer.aead.NonceSize())
+	sealed := base64.RawURLEncoding.EncodeToString(
+		sealer.aead.Seal(nonce, nonce, []byte(query), nil),
+	)
+
+	_, err = sealer.Open(sealed)
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected error without plaintext, got %v", err)
+	}
+	var target struct {
+		Token string `json:"token"`
+	}
+	err = sealer.OpenInto(sealed, &target)
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected error without plaintext, got %v", err)
+	}
+}
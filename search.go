@@ -0,0 +1,212 @@
+package urlcodec
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SearchTerm is a term of a search parameter.
+type SearchTerm struct {
+	Field   string // Field qualifier, e.g. "author" in "author:ann"
+	Value   string // Term text, unquoted
+	Phrase  bool   // Value was written as a quoted phrase
+	Negated bool   // Term was prefixed with "-"
+}
+
+// Search is the value of a search parameter such as
+// `q=term1 term2 "exact phrase" field:value -excluded`: its terms in
+// order. It implements encoding.TextMarshaler and encoding.TextUnmarshaler,
+// so that it can be used as a field of structs that are encoded or decoded
+// with DecodeInto.
+type Search []SearchTerm
+
+// ParseSearch splits a search parameter into terms at whitespace outside of
+// double quotes. A term may be prefixed with "-" to negate it and with
+// "field:" to qualify it, and its value may be a double-quoted phrase in
+// which "\"" and "\\" are escapes. Qualifiers are fields made of letters,
+// digits, "_" and "."; if fields is not empty, other qualifiers are kept as
+// part of the term text, so that free text like "note: x" or URLs still
+// work. Phrases that are not terminated are rejected with an
+// ErrInvalidSearch.
+//
+// Parameters:
+//   - q: Search parameter
+//   - fields: Accepted field qualifiers, or none to accept all
+//
+// Returns:
+//   - Search: Search terms
+//   - error: Error
+func ParseSearch(q string, fields ...string) (Search, error) {
+	var search Search
+	for i := skipSpaces(q, 0); i < len(q); i = skipSpaces(q, i) {
+		var term SearchTerm
+		start := i
+		if q[i] == '-' && i+1 < len(q) && !isSearchSpace(q[i+1]) {
+			term.Negated = true
+			i++
+		}
+		if end := qualifierEnd(q, i); end > i && end+1 < len(q) &&
+			!isSearchSpace(q[end+1]) &&
+			(len(fields) == 0 || slices.Contains(fields, q[i:end])) {
+			term.Field = q[i:end]
+			i = end + 1
+		}
+		if q[i] == '"' {
+			value, end, ok := readPhrase(q, i+1)
+			if !ok {
+				return nil, fmt.Errorf(
+					"%w: unterminated phrase at offset %d",
+					ErrInvalidSearch, i,
+				)
+			}
+			term.Value, term.Phrase, i = value, true, end
+		} else {
+			end := i
+			for end < len(q) && !isSearchSpace(q[end]) {
+				end++
+			}
+			term.Value, i = q[i:end], end
+		}
+		if i < len(q) && !isSearchSpace(q[i]) {
+			// Text right after a closing quote makes the term plain text.
+			for i < len(q) && !isSearchSpace(q[i]) {
+				i++
+			}
+			term = SearchTerm{Value: q[start:i]}
+		}
+		search = append(search, term)
+	}
+	return search, nil
+}
+
+// skipSpaces returns the position of the first non-space byte from i.
+func skipSpaces(q string, i int) int {
+	for i < len(q) && isSearchSpace(q[i]) {
+		i++
+	}
+	return i
+}
+
+// isSearchSpace reports whether c separates search terms.
+func isSearchSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// qualifierEnd returns the position of the ":" that ends a field qualifier
+// starting at i, or i if there is none.
+func qualifierEnd(q string, i int) int {
+	for end := i; end < len(q); end++ {
+		switch c := q[end]; {
+		case c == ':':
+			return end
+		case c == '_' || c == '.' || isDigit(c) || isAlpha(c):
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// readPhrase reads a quoted phrase from i, after the opening quote, and
+// returns its unescaped text and the position after the closing quote.
+func readPhrase(q string, i int) (string, int, bool) {
+	var b strings.Builder
+	for ; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '"':
+			return b.String(), i + 1, true
+		case c == '\\' && i+1 < len(q):
+			i++
+			b.WriteByte(q[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", i, false
+}
+
+// Terms returns the terms qualified with a field, or the unqualified terms
+// if field is empty.
+//
+// Parameters:
+//   - field: Field qualifier
+//
+// Returns:
+//   - []SearchTerm: Terms of the field
+func (s Search) Terms(field string) []SearchTerm {
+	var terms []SearchTerm
+	for _, term := range s {
+		if term.Field == field {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// String returns the search parameter. Phrases and values that would not
+// be parsed back as they are are written quoted.
+//
+// Returns:
+//   - string: Search parameter
+func (s Search) String() string {
+	var b strings.Builder
+	for i, term := range s {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if term.Negated {
+			b.WriteByte('-')
+		}
+		if term.Field != "" {
+			b.WriteString(term.Field + ":")
+		}
+		if term.Phrase || needsQuotes(term.Value) {
+			b.WriteByte('"')
+			for j := 0; j < len(term.Value); j++ {
+				if c := term.Value[j]; c == '"' || c == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteByte(term.Value[j])
+			}
+			b.WriteByte('"')
+		} else {
+			b.WriteString(term.Value)
+		}
+	}
+	return b.String()
+}
+
+// needsQuotes reports whether a term value must be quoted to be parsed
+// back as it is: if it is empty, has spaces or quotes, or would be read as
+// negated or qualified.
+func needsQuotes(value string) bool {
+	return value == "" || value[0] == '-' || qualifierEnd(value, 0) > 0 ||
+		strings.ContainsAny(value, " \t\n\r\"")
+}
+
+// MarshalText returns the search parameter.
+//
+// Returns:
+//   - []byte: Search parameter
+//   - error: Always nil
+func (s Search) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a search parameter with ParseSearch, accepting all
+// field qualifiers.
+//
+// Parameters:
+//   - text: Search parameter
+//
+// Returns:
+//   - error: Error
+func (s *Search) UnmarshalText(text []byte) error {
+	search, err := ParseSearch(string(text))
+	if err != nil {
+		return err
+	}
+	*s = search
+	return nil
+}
@@ -0,0 +1,94 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestParseSearch verifies that search parameters are split into plain,
+// phrase, qualified and negated terms.
+func TestParseSearch(t *testing.T) {
+	search, err := ParseSearch(
+		`term1  term2 "exact \"phrase\"" author:ann -draft ` +
+			`title:"go tips" -tag:old note: x:`,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Search{
+		{Value: "term1"},
+		{Value: "term2"},
+		{Value: `exact "phrase"`, Phrase: true},
+		{Field: "author", Value: "ann"},
+		{Value: "draft", Negated: true},
+		{Field: "title", Value: "go tips", Phrase: true},
+		{Field: "tag", Value: "old", Negated: true},
+		{Value: "note:"},
+		{Value: "x:"},
+	}
+	if !reflect.DeepEqual(search, expected) {
+		t.Errorf("expected %v, got %v", expected, search)
+	}
+	expectedString := `term1 term2 "exact \"phrase\"" author:ann -draft ` +
+		`title:"go tips" -tag:old "note:" "x:"`
+	if got := search.String(); got != expectedString {
+		t.Errorf("expected %s, got %s", expectedString, got)
+	}
+	if got := search.Terms("author"); len(got) != 1 || got[0].Value != "ann" {
+		t.Errorf("expected author term, got %v", got)
+	}
+
+	if _, err := ParseSearch(`a "open`); !errors.Is(err, ErrInvalidSearch) {
+		t.Errorf("expected ErrInvalidSearch, got %v", err)
+	}
+	if search, err := ParseSearch("  "); err != nil || search != nil {
+		t.Errorf("expected empty search, got %v, %v", search, err)
+	}
+}
+
+// TestParseSearch_Fields verifies that only accepted qualifiers are split
+// from the term text.
+func TestParseSearch_Fields(t *testing.T) {
+	search, err := ParseSearch(`author:ann https://x.io "a"b -`, "author")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Search{
+		{Field: "author", Value: "ann"},
+		{Value: "https://x.io"},
+		{Value: `"a"b`},
+		{Value: "-"},
+	}
+	if !reflect.DeepEqual(search, expected) {
+		t.Errorf("expected %v, got %v", expected, search)
+	}
+}
+
+// TestSearch_Binding verifies that Search fields are decoded and encoded as
+// search parameters.
+func TestSearch_Binding(t *testing.T) {
+	type request struct {
+		Q Search `json:"q"`
+	}
+	values, err := url.ParseQuery(`q=golang+author:ann+"exact+phrase"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoder := NewURLEncoder()
+	var req request
+	if err := encoder.DecodeInto(values, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Q) != 3 || req.Q[2].Value != "exact phrase" {
+		t.Errorf("unexpected terms %v", req.Q)
+	}
+	encoded, err := encoder.Encode(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := encoded.Get("q"); got != values.Get("q") {
+		t.Errorf("expected %s, got %s", values.Get("q"), got)
+	}
+}
@@ -0,0 +1,189 @@
+package urlcodec
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureParam is the name of the query parameter that holds the
+	// signature added by a Signer.
+	SignatureParam = "sig"
+	// ExpiresParam is the name of the query parameter that holds the
+	// expiration time added by Signer.SignWithExpiry, in Unix seconds.
+	ExpiresParam = "exp"
+)
+
+var (
+	// ErrInvalidSignature is returned by Signer.Verify when the signature
+	// is missing or does not match the query.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrExpiredSignature is returned by Signer.Verify when a signed query
+	// has expired.
+	ErrExpiredSignature = errors.New("signature expired")
+)
+
+// Signer signs encoded data with HMAC-SHA256 and verifies signed queries.
+// The signature is computed over the canonical form of the query, as
+// returned by Canonicalize, so that it does not depend on key order or
+// percent-encoding.
+type Signer struct {
+	key     []byte
+	encoder *URLEncoder
+	now     func() time.Time // Clock used for expiry
+}
+
+// NewSigner returns a new Signer.
+//
+// Parameters:
+//   - key: Secret HMAC key
+//   - opts: Options to configure the encoder used by Sign
+//
+// Returns:
+//   - *Signer: The signer
+func NewSigner(key []byte, opts ...Option) *Signer {
+	return &Signer{
+		key:     append([]byte(nil), key...),
+		encoder: NewURLEncoder(opts...),
+		now:     time.Now,
+	}
+}
+
+// Sign encodes data and adds a signature parameter to the encoded values.
+//
+// Parameters:
+//   - data: Data to encode
+//
+// Returns:
+//   - url.Values: Signed URL values
+//   - error: Error
+func (s *Signer) Sign(data any) (url.Values, error) {
+	values, err := s.encode(data)
+	if err != nil {
+		return nil, err
+	}
+	values.Set(SignatureParam, s.signature(values))
+	return values, nil
+}
+
+// SignWithExpiry encodes data, adds an expiration time ttl from now and
+// signs the values including the expiration time. Verify rejects the
+// values once they have expired.
+//
+// Parameters:
+//   - data: Data to encode
+//   - ttl: Time until the signed values expire
+//
+// Returns:
+//   - url.Values: Signed URL values
+//   - error: Error
+func (s *Signer) SignWithExpiry(
+	data any, ttl time.Duration,
+) (url.Values, error) {
+	values, err := s.encode(data)
+	if err != nil {
+		return nil, err
+	}
+	expires := s.now().Add(ttl).Unix()
+	values.Set(ExpiresParam, strconv.FormatInt(expires, 10))
+	values.Set(SignatureParam, s.signature(values))
+	return values, nil
+}
+
+// encode encodes data to be signed, rejecting data that contains the
+// parameters added by the signer.
+func (s *Signer) encode(data any) (url.Values, error) {
+	values, err := s.encoder.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range []string{SignatureParam, ExpiresParam} {
+		if values.Has(param) {
+			return nil, fmt.Errorf(
+				"data must not contain the %q parameter", param,
+			)
+		}
+	}
+	return values, nil
+}
+
+// Verify checks the signature parameter of signed values in constant time.
+// It returns an error wrapping ErrInvalidSignature if the signature is
+// missing, repeated or does not match, and an error wrapping
+// ErrExpiredSignature if the values have an expiration time that has
+// passed.
+//
+// Parameters:
+//   - values: Signed URL values
+//
+// Returns:
+//   - error: Error
+func (s *Signer) Verify(values url.Values) error {
+	sigs := values[SignatureParam]
+	if len(sigs) != 1 {
+		return fmt.Errorf(
+			"%w: expected one %q parameter, got %d",
+			ErrInvalidSignature, SignatureParam, len(sigs),
+		)
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sigs[0])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	unsigned := make(url.Values, len(values))
+	for key, value := range values {
+		if key != SignatureParam {
+			unsigned[key] = value
+		}
+	}
+	if !hmac.Equal(got, s.mac(unsigned)) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+	return s.checkExpiry(values)
+}
+
+// checkExpiry returns an error if the expiration time of signed values has
+// passed. Values without an expiration time do not expire.
+func (s *Signer) checkExpiry(values url.Values) error {
+	exps := values[ExpiresParam]
+	if len(exps) == 0 {
+		return nil
+	}
+	if len(exps) > 1 {
+		return fmt.Errorf(
+			"%w: expected one %q parameter, got %d",
+			ErrInvalidSignature, ExpiresParam, len(exps),
+		)
+	}
+	expires, err := strconv.ParseInt(exps[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf(
+			"%w: malformed expiration time", ErrInvalidSignature,
+		)
+	}
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return fmt.Errorf(
+			"%w: expired at %s", ErrExpiredSignature,
+			time.Unix(expires, 0).UTC().Format(time.RFC3339),
+		)
+	}
+	return nil
+}
+
+// signature returns the encoded signature of unsigned values.
+func (s *Signer) signature(values url.Values) string {
+	return base64.RawURLEncoding.EncodeToString(s.mac(values))
+}
+
+// mac returns the HMAC-SHA256 of the canonical form of values.
+func (s *Signer) mac(values url.Values) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(Canonicalize(values)))
+	return h.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestSigner verifies that signed values verify after a round trip through
+// a query string and that tampering is detected.
+func TestSigner(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	values, err := signer.Sign(map[string]any{
+		"file": "report.pdf",
+		"ids":  []int{1, 2, 10},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.ParseQuery(values.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := signer.Verify(parsed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tampered := url.Values{}
+	for key, value := range parsed {
+		tampered[key] = value
+	}
+	tampered.Set("file", "secret.pdf")
+	tests := map[string]url.Values{
+		"tampered":  tampered,
+		"missing":   {"file": {"report.pdf"}},
+		"malformed": {"file": {"report.pdf"}, SignatureParam: {"%%"}},
+		"repeated": {
+			"file":         {"report.pdf"},
+			SignatureParam: {parsed.Get(SignatureParam), "x"},
+		},
+	}
+	for name, values := range tests {
+		err := signer.Verify(values)
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+
+	other := NewSigner([]byte("other"))
+	if err := other.Verify(parsed); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for other key, got %v", err)
+	}
+
+	_, err = signer.Sign(map[string]any{SignatureParam: "x"})
+	if err == nil {
+		t.Error("expected error for data with signature parameter, got nil")
+	}
+}
+
+// TestSigner_SignWithExpiry verifies that signed values with an expiration
+// time verify until they expire and that the expiration time is signed.
+func TestSigner_SignWithExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := NewSigner([]byte("secret"))
+	signer.now = func() time.Time { return now }
+
+	values, err := signer.SignWithExpiry(
+		map[string]any{"file": "report.pdf"}, time.Minute,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get(ExpiresParam); got != "1700000060" {
+		t.Errorf("expected %s=1700000060, got %q", ExpiresParam, got)
+	}
+	if err := signer.Verify(values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := signer.Verify(values); !errors.Is(err, ErrExpiredSignature) {
+		t.Errorf("expected ErrExpiredSignature, got %v", err)
+	}
+
+	values.Set(ExpiresParam, "1800000000")
+	if err := signer.Verify(values); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	_, err = signer.SignWithExpiry(map[string]any{ExpiresParam: 1}, time.Hour)
+	if err == nil {
+		t.Error("expected error for data with expiry parameter, got nil")
+	}
+}
@@ -0,0 +1,298 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SliceStyle selects how slices and arrays are written in URL values.
+type SliceStyle int
+
+const (
+	// IndexedSlices writes one key per element with its index, e.g.
+	// "tags[0]=a&tags[1]=b".
+	IndexedSlices SliceStyle = iota
+	// UnindexedSlices writes scalar elements under a repeated key with empty
+	// brackets, e.g. "tags[]=a&tags[]=b", as used by Rails and PHP.
+	// Elements that encode to nested keys keep their indices.
+	UnindexedSlices
+	// RepeatedKeySlices writes scalar elements under a repeated plain key,
+	// e.g. "tags=a&tags=b". When decoding, all values of a repeated key are
+	// collected into a slice. Elements that encode to nested keys keep their
+	// indices.
+	RepeatedKeySlices
+	// CommaSlices writes scalar elements as a single comma-separated value,
+	// e.g. "ids=1,2,3", like the OpenAPI form style without explode. Commas
+	// and backslashes in elements are escaped with a backslash, while
+	// scalar values are written as they are. Decode therefore returns a
+	// scalar such as "Doe, John" as a slice, whereas DecodeInto assigns it
+	// as it is to fields that are not slices. Elements that encode to
+	// nested keys keep their indices.
+	CommaSlices
+	// SpaceSlices writes scalar elements as a single space-separated value,
+	// e.g. "ids=1 2 3", like the OpenAPI spaceDelimited style. Escaping
+	// works as for CommaSlices, so text with spaces in scalar values is
+	// written as it is.
+	SpaceSlices
+	// PipeSlices writes scalar elements as a single pipe-separated value,
+	// e.g. "ids=1|2|3", like the OpenAPI pipeDelimited style. Escaping works
+	// as for CommaSlices.
+	PipeSlices
+)
+
+// RepeatedKeys selects how a key with more than one value is decoded.
+type RepeatedKeys int
+
+const (
+	// FirstValue decodes the first value of a repeated key and ignores the
+	// others.
+	FirstValue RepeatedKeys = iota
+	// CollectAsSlice decodes all values of a repeated key into a slice,
+	// e.g. "tag=a&tag=b" gives ["a", "b"].
+	CollectAsSlice
+)
+
+// valuesPool holds url.Values used to encode slice elements, so that
+// encoding large slices does not allocate a map per element.
+var valuesPool = sync.Pool{
+	New: func() any { return url.Values{} },
+}
+
+// getValues returns empty url.Values from the pool.
+func getValues() url.Values {
+	return valuesPool.Get().(url.Values)
+}
+
+// putValues clears url.Values and returns them to the pool.
+func putValues(values url.Values) {
+	clear(values)
+	valuesPool.Put(values)
+}
+
+// indexKey returns the key of the slice element at an index, e.g. "tags"
+// and 1 give "tags[1]".
+func indexKey(key string, index int) string {
+	return key + "[" + strconv.Itoa(index) + "]"
+}
+
+// encodeUnindexedSlice encodes a slice writing scalar elements under the
+// given key. Each element is encoded with its index first and renamed if it
+// produced a single value under that index.
+func (e *URLEncoder) encodeUnindexedSlice(
+	values *url.Values, fieldTag string, v reflect.Value, scalarTag string,
+) error {
+	elem := getValues()
+	defer putValues(elem)
+	for j := 0; j < v.Len(); j++ {
+		clear(elem)
+		indexTag := indexKey(fieldTag, j)
+		if err := e.encodeValue(&elem, indexTag, v.Index(j)); err != nil {
+			return err
+		}
+		if vals, ok := elem[indexTag]; ok && len(elem) == 1 {
+			(*values)[scalarTag] = append((*values)[scalarTag], vals...)
+			e.order.add(scalarTag)
+			continue
+		}
+		for key, vals := range elem {
+			(*values)[key] = append((*values)[key], vals...)
+			e.order.add(key)
+		}
+	}
+	return nil
+}
+
+// sliceDelimiter returns the delimiter of the configured slice style. It
+// returns false if the style does not join elements into a single value.
+func (e *URLEncoder) sliceDelimiter() (byte, bool) {
+	switch e.sliceStyle {
+	case CommaSlices:
+		return ',', true
+	case SpaceSlices:
+		return ' ', true
+	case PipeSlices:
+		return '|', true
+	}
+	return 0, false
+}
+
+// encodeDelimitedSlice encodes a slice joining its scalar elements into a
+// single value separated by the delimiter. Each element is encoded with its
+// index first and joined if it produced a single value under that index.
+func (e *URLEncoder) encodeDelimitedSlice(
+	values *url.Values, fieldTag string, v reflect.Value, sep byte,
+) error {
+	var elems []string
+	elem := getValues()
+	defer putValues(elem)
+	for j := 0; j < v.Len(); j++ {
+		clear(elem)
+		indexTag := indexKey(fieldTag, j)
+		if err := e.encodeValue(&elem, indexTag, v.Index(j)); err != nil {
+			return err
+		}
+		if vals, ok := elem[indexTag]; ok && len(elem) == 1 {
+			elems = append(elems, vals...)
+			continue
+		}
+		for key, vals := range elem {
+			(*values)[key] = append((*values)[key], vals...)
+			e.order.add(key)
+		}
+	}
+	if len(elems) > 0 {
+		if !e.escapeScalars {
+			for i, elem := range elems {
+				elems[i] = escapeDelimited(elem, sep)
+			}
+		}
+		// Elements escaped as scalars are joined as they are.
+		values.Set(fieldTag, strings.Join(elems, string(sep)))
+		e.order.add(fieldTag)
+	}
+	return nil
+}
+
+// escapeDelimiter escapes the delimiter of the slice style and backslashes
+// in an encoded value with a backslash. Values are returned unchanged with
+// other slice styles.
+func (e *URLEncoder) escapeDelimiter(value string) string {
+	if sep, ok := e.sliceDelimiter(); ok {
+		return escapeDelimited(value, sep)
+	}
+	return value
+}
+
+// escapeDelimited escapes a delimiter and backslashes in a value with a
+// backslash, so that the value is not split when it is decoded.
+func escapeDelimited(value string, sep byte) string {
+	if strings.IndexByte(value, sep) < 0 &&
+		strings.IndexByte(value, '\\') < 0 {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == sep || value[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// splitDelimited splits a value on unescaped delimiters and unescapes the
+// elements. It is the inverse of joining values escaped by escapeDelimited
+// with the delimiter.
+func splitDelimited(s string, sep byte) []string {
+	var elems []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case s[i] == sep:
+			elems = append(elems, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(elems, b.String())
+}
+
+// hasDelimiter reports whether s contains an unescaped delimiter.
+func hasDelimiter(s string, sep byte) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			return true
+		}
+	}
+	return false
+}
+
+// splitDelimitedValues replaces values of a decoded map that contain an
+// unescaped delimiter with slices of their elements, recursively.
+func (e *URLEncoder) splitDelimitedValues(data map[string]any, sep byte) {
+	for key, value := range data {
+		data[key] = e.splitDelimitedValue(value, sep)
+	}
+}
+
+// splitDelimitedValue splits a decoded value if it is a string containing
+// an unescaped delimiter and recurses into maps and slices. Other strings
+// are kept as they are, unless scalars are escaped too.
+func (e *URLEncoder) splitDelimitedValue(value any, sep byte) any {
+	switch v := value.(type) {
+	case string:
+		if hasDelimiter(v, sep) {
+			return stringsToAny(splitDelimited(v, sep))
+		}
+		if e.escapeScalars && strings.IndexByte(v, '\\') >= 0 {
+			return splitDelimited(v, sep)[0]
+		}
+	case map[string]any:
+		e.splitDelimitedValues(v, sep)
+	case []any:
+		for i, elem := range v {
+			v[i] = e.splitDelimitedValue(elem, sep)
+		}
+	}
+	return value
+}
+
+// stringsToAny converts a slice of strings to a slice of any.
+func stringsToAny(elems []string) []any {
+	slice := make([]any, len(elems))
+	for i, elem := range elems {
+		slice[i] = elem
+	}
+	return slice
+}
+
+// expandUnindexedKey assigns indices to the values of a key that ends with
+// empty brackets, e.g. "tags[]" with two values gives "tags[0]" and
+// "tags[1]", in the order in which the values were received. It returns
+// false if the key has no empty brackets.
+func expandUnindexedKey(key string, vals []string) (url.Values, bool) {
+	base, ok := strings.CutSuffix(key, "[]")
+	if !ok {
+		return nil, false
+	}
+	return indexValues(base, vals), true
+}
+
+// expandRepeatedKey assigns indices to the values of a key that has more
+// than one value when repeated keys are decoded as slices, e.g. "tags" with
+// two values gives "tags[0]" and "tags[1]". It returns false if the key is
+// not expanded.
+func (e *URLEncoder) expandRepeatedKey(
+	key string, vals []string,
+) (url.Values, bool) {
+	if !e.collectRepeatedKeys() || len(vals) < 2 {
+		return nil, false
+	}
+	return indexValues(key, vals), true
+}
+
+// collectRepeatedKeys reports whether the values of a repeated key are
+// decoded as a slice.
+func (e *URLEncoder) collectRepeatedKeys() bool {
+	return e.sliceStyle == RepeatedKeySlices ||
+		e.repeatedKeys == CollectAsSlice
+}
+
+// indexValues returns the values keyed by the base key with their index.
+func indexValues(base string, vals []string) url.Values {
+	indexed := make(url.Values, len(vals))
+	for i, val := range vals {
+		indexed.Set(indexKey(base, i), val)
+	}
+	return indexed
+}
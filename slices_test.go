@@ -0,0 +1,330 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestUnindexedSlices_Encode verifies that scalar elements are written under
+// empty brackets and nested elements keep their indices.
+func TestUnindexedSlices_Encode(t *testing.T) {
+	encoder := NewURLEncoder(WithSliceStyle(UnindexedSlices))
+	values, err := encoder.Encode(map[string]any{
+		"tags":  []string{"a", "b", "c"},
+		"items": []map[string]any{{"sku": "x"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"tags[]":       {"a", "b", "c"},
+		"items[0].sku": {"x"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+// TestUnindexedSlices_Decode verifies that values of keys with empty
+// brackets are appended in received order.
+func TestUnindexedSlices_Decode(t *testing.T) {
+	values, err := url.ParseQuery("tags[]=c&tags[]=a&tags[]=b&user.ids[]=1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := NewURLEncoder().Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"tags": []any{"c", "a", "b"},
+		"user": map[string]any{"ids": []any{"1"}},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+}
+
+// TestUnindexedSlices_BracketNotation verifies that empty brackets are
+// accepted in bracket notation.
+func TestUnindexedSlices_BracketNotation(t *testing.T) {
+	encoder := NewURLEncoder(
+		WithNotation(BracketNotation), WithSliceStyle(UnindexedSlices),
+	)
+	type Filter struct {
+		IDs []int `json:"ids"`
+	}
+	values, err := encoder.Encode(map[string]any{
+		"filter": Filter{IDs: []int{3, 1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values["filter[ids][]"]; !reflect.DeepEqual(
+		got, []string{"3", "1"},
+	) {
+		t.Errorf("expected filter[ids][]=[3 1], got %v", values)
+	}
+	var decoded struct {
+		Filter Filter `json:"filter"`
+	}
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Filter.IDs, []int{3, 1}) {
+		t.Errorf("expected ids=[3 1], got %v", decoded.Filter.IDs)
+	}
+}
+
+// TestRepeatedKeySlices_RoundTrip verifies that slices are written as
+// repeated keys and that repeated keys decode to slices.
+func TestRepeatedKeySlices_RoundTrip(t *testing.T) {
+	type Filter struct {
+		Tags []string `json:"tags"`
+		IDs  []int    `json:"ids"`
+		Name string   `json:"name"`
+	}
+	encoder := NewURLEncoder(WithSliceStyle(RepeatedKeySlices))
+	original := Filter{Tags: []string{"b", "a"}, IDs: []int{7}, Name: "x"}
+	values, err := encoder.Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"tags": {"b", "a"},
+		"ids":  {"7"},
+		"name": {"x"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded["tags"], []any{"b", "a"}) {
+		t.Errorf("expected tags=[b a], got %v", decoded["tags"])
+	}
+
+	var filter Filter
+	if err := encoder.DecodeInto(values, &filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(filter, original) {
+		t.Errorf("expected %+v, got %+v", original, filter)
+	}
+}
+
+// TestCommaSlices_RoundTrip verifies that slices are joined with commas,
+// that commas in elements are escaped, and that values are split on decode.
+func TestCommaSlices_RoundTrip(t *testing.T) {
+	type Filter struct {
+		IDs   []int     `json:"ids"`
+		Names []string  `json:"names"`
+		Pair  [2]string `json:"pair"`
+	}
+	encoder := NewURLEncoder(WithSliceStyle(CommaSlices))
+	original := Filter{
+		IDs:   []int{1, 2, 3},
+		Names: []string{"Smith, John", `a\b`},
+		Pair:  [2]string{"x", "y"},
+	}
+	values, err := encoder.Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"ids":   {"1,2,3"},
+		"names": {`Smith\, John,a\\b`},
+		"pair":  {"x,y"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded["names"], []any{"Smith, John", `a\b`}) {
+		t.Errorf("expected names to be split, got %v", decoded["names"])
+	}
+
+	var filter Filter
+	if err := encoder.DecodeInto(values, &filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(filter, original) {
+		t.Errorf("expected %+v, got %+v", original, filter)
+	}
+}
+
+// TestCommaSlices_Scalars verifies that scalars containing commas are
+// written as they are and round-trip through DecodeInto, while commas in
+// elements are escaped.
+func TestCommaSlices_Scalars(t *testing.T) {
+	type Person struct {
+		Name string   `json:"name"`
+		Path string   `json:"path"`
+		Tags []string `json:"tags"`
+		Any  any      `json:"any"`
+	}
+	encoder := NewURLEncoder(WithSliceStyle(CommaSlices))
+	original := Person{
+		Name: "Doe, John Smith", Path: `C:\tmp`,
+		Tags: []string{"a,b", "c"}, Any: "x,y",
+	}
+	values, err := encoder.Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedValues := url.Values{
+		"name": {"Doe, John Smith"}, "path": {`C:\tmp`},
+		"tags": {`a\,b,c`}, "any": {"x,y"},
+	}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("expected %v, got %v", expectedValues, values)
+	}
+
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"name": []any{"Doe", " John Smith"},
+		"path": `C:\tmp`,
+		"tags": []any{"a,b", "c"},
+		"any":  []any{"x", "y"},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+
+	var person Person
+	if err := encoder.DecodeInto(values, &person); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(person, original) {
+		t.Errorf("expected %+v, got %+v", original, person)
+	}
+}
+
+// TestSpaceSlices_Scalars verifies that text values containing spaces are
+// written as they are and round-trip through DecodeInto.
+func TestSpaceSlices_Scalars(t *testing.T) {
+	type Person struct {
+		Name string   `json:"name"`
+		IDs  []string `json:"ids"`
+	}
+	encoder := NewURLEncoder(WithSliceStyle(SpaceSlices))
+	original := Person{Name: "Doe, John Smith", IDs: []string{"1", "2 3"}}
+	values, err := encoder.Encode(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("name"); got != "Doe, John Smith" {
+		t.Errorf("expected unescaped spaces, got %q", got)
+	}
+
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"name": []any{"Doe,", "John", "Smith"},
+		"ids":  []any{"1", "2 3"},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+
+	var person Person
+	if err := encoder.DecodeInto(values, &person); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(person, original) {
+		t.Errorf("expected %+v, got %+v", original, person)
+	}
+}
+
+// TestDelimitedSlices verifies the space- and pipe-delimited slice styles.
+func TestDelimitedSlices(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    SliceStyle
+		expected string
+	}{
+		{"space", SpaceSlices, `a\ b c|d`},
+		{"pipe", PipeSlices, `a b|c\|d`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewURLEncoder(WithSliceStyle(tt.style))
+			original := []string{"a b", "c|d"}
+			values, err := encoder.Encode(map[string]any{
+				"s": original, "q": `a b|c\d`,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := values.Get("s"); got != tt.expected {
+				t.Errorf("expected s=%s, got %q", tt.expected, got)
+			}
+			// Scalars are written as they are, like OpenAPI does.
+			if got := values.Get("q"); got != `a b|c\d` {
+				t.Errorf("expected unescaped q, got %q", got)
+			}
+			var decoded struct {
+				S []string `json:"s"`
+				Q string   `json:"q"`
+			}
+			if err := encoder.DecodeInto(values, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded.S, original) {
+				t.Errorf("expected %v, got %v", original, decoded.S)
+			}
+			if decoded.Q != `a b|c\d` {
+				t.Errorf("expected q=%s, got %q", `a b|c\d`, decoded.Q)
+			}
+		})
+	}
+}
+
+// TestWithRepeatedKeys verifies that repeated keys are collected into
+// slices only when configured.
+func TestWithRepeatedKeys(t *testing.T) {
+	values := url.Values{"tag": {"a", "b"}, "id": {"1"}}
+
+	got, err := NewURLEncoder().Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["tag"] != "a" {
+		t.Errorf("expected first value by default, got %v", got["tag"])
+	}
+
+	encoder := NewURLEncoder(WithRepeatedKeys(CollectAsSlice))
+	got, err = encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{"tag": []any{"a", "b"}, "id": "1"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	var target struct {
+		Tag []string `json:"tag"`
+		ID  []int    `json:"id"`
+	}
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(target.Tag, []string{"a", "b"}) ||
+		!reflect.DeepEqual(target.ID, []int{1}) {
+		t.Errorf("unexpected target: %+v", target)
+	}
+}
@@ -0,0 +1,139 @@
+package urlcodec
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortDirection is the direction of a sort field.
+type SortDirection int
+
+const (
+	// Ascending sorts from the smallest value, written as "name" or
+	// "+name".
+	Ascending SortDirection = iota
+	// Descending sorts from the largest value, written as "-name".
+	Descending
+)
+
+// SortField is a field to sort by and its direction.
+type SortField struct {
+	Field     string        // Field name, e.g. "created_at"
+	Direction SortDirection // Sort direction
+}
+
+// Sort is the value of a sort parameter such as "-created_at,name": the
+// fields to sort by in order of precedence. It implements
+// encoding.TextMarshaler and encoding.TextUnmarshaler, so that it can be
+// used as a field of structs that are encoded or decoded with DecodeInto.
+// Use Validate to check decoded fields against the sortable ones.
+type Sort []SortField
+
+// ParseSort parses a sort parameter such as "-created_at,name". A leading
+// "-" sorts a field in descending order and a leading "+" or none in
+// ascending order. Spaces around fields are ignored, so that a "+" decoded
+// as a space also works. Empty and repeated fields and fields outside of
+// allowed, unless it is empty, are rejected with an ErrInvalidSort.
+//
+// Parameters:
+//   - s: Sort parameter
+//   - allowed: Sortable fields, or none to allow all fields
+//
+// Returns:
+//   - Sort: Sort fields
+//   - error: Error
+func ParseSort(s string, allowed ...string) (Sort, error) {
+	var sort Sort
+	if strings.TrimSpace(s) == "" {
+		return sort, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		field := SortField{Field: strings.TrimSpace(part)}
+		switch {
+		case strings.HasPrefix(field.Field, "-"):
+			field.Field = field.Field[1:]
+			field.Direction = Descending
+		case strings.HasPrefix(field.Field, "+"):
+			field.Field = field.Field[1:]
+		}
+		if field.Field == "" {
+			return nil, fmt.Errorf("%w: empty field in %q", ErrInvalidSort, s)
+		}
+		sort = append(sort, field)
+	}
+	if err := sort.Validate(allowed...); err != nil {
+		return nil, err
+	}
+	return sort, nil
+}
+
+// Validate checks that no field is repeated and that all fields are in
+// allowed, unless it is empty.
+//
+// Parameters:
+//   - allowed: Sortable fields, or none to allow all fields
+//
+// Returns:
+//   - error: Error wrapping ErrInvalidSort naming the first invalid field
+func (s Sort) Validate(allowed ...string) error {
+	seen := make(map[string]bool, len(s))
+	for _, field := range s {
+		if seen[field.Field] {
+			return fmt.Errorf(
+				"%w: repeated field %q", ErrInvalidSort, field.Field,
+			)
+		}
+		seen[field.Field] = true
+		if len(allowed) > 0 && !slices.Contains(allowed, field.Field) {
+			return fmt.Errorf(
+				"%w: cannot sort by %q", ErrInvalidSort, field.Field,
+			)
+		}
+	}
+	return nil
+}
+
+// String returns the sort parameter, e.g. "-created_at,name".
+//
+// Returns:
+//   - string: Sort parameter
+func (s Sort) String() string {
+	var b strings.Builder
+	for i, field := range s {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if field.Direction == Descending {
+			b.WriteByte('-')
+		}
+		b.WriteString(field.Field)
+	}
+	return b.String()
+}
+
+// MarshalText returns the sort parameter.
+//
+// Returns:
+//   - []byte: Sort parameter
+//   - error: Always nil
+func (s Sort) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a sort parameter with ParseSort, allowing all
+// fields.
+//
+// Parameters:
+//   - text: Sort parameter
+//
+// Returns:
+//   - error: Error
+func (s *Sort) UnmarshalText(text []byte) error {
+	sort, err := ParseSort(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sort
+	return nil
+}
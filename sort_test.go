@@ -0,0 +1,68 @@
+package urlcodec
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestParseSort verifies that sort parameters are parsed in order with
+// their directions and written back.
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort("-created_at, +name ,id", "created_at", "name", "id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Sort{
+		{Field: "created_at", Direction: Descending},
+		{Field: "name", Direction: Ascending},
+		{Field: "id", Direction: Ascending},
+	}
+	if !reflect.DeepEqual(sort, expected) {
+		t.Errorf("expected %v, got %v", expected, sort)
+	}
+	if got := sort.String(); got != "-created_at,name,id" {
+		t.Errorf("expected -created_at,name,id, got %s", got)
+	}
+	if sort, err := ParseSort(""); err != nil || sort != nil {
+		t.Errorf("expected empty sort, got %v, %v", sort, err)
+	}
+
+	for _, s := range []string{"name,", "-", "name,-name", "secret"} {
+		if _, err := ParseSort(s, "name"); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("%q: expected ErrInvalidSort, got %v", s, err)
+		}
+	}
+}
+
+// TestSort_Binding verifies that Sort fields are decoded and encoded as
+// sort parameters.
+func TestSort_Binding(t *testing.T) {
+	type listRequest struct {
+		Sort Sort `json:"sort"`
+	}
+	values, err := url.ParseQuery("sort=-created_at,+name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoder := NewURLEncoder()
+	var req listRequest
+	if err := encoder.DecodeInto(values, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := req.Sort.Validate("created_at", "name"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := req.Sort.String(); got != "-created_at,name" {
+		t.Errorf("expected -created_at,name, got %s", got)
+	}
+
+	encoded, err := encoder.Encode(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := encoded.Get("sort"); got != "-created_at,name" {
+		t.Errorf("expected sort=-created_at,name, got %v", encoded)
+	}
+}
@@ -0,0 +1,666 @@
+package urlcodec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxSFInteger is the largest magnitude of an Integer in a structured
+// field.
+const maxSFInteger = 999_999_999_999_999
+
+// SFToken is a Token in an HTTP structured field, e.g. the "gzip" in
+// "a=gzip". Tokens are written without quotes, unlike strings.
+type SFToken string
+
+// SFItem is an item or inner list of an HTTP structured field together with
+// its parameters, e.g. "text/html;q=0.9". Value is a bare item, or a []any
+// of items for an inner list.
+type SFItem struct {
+	Value  any            // Bare item or inner list
+	Params map[string]any // Parameters by key
+}
+
+// EncodeSFDictionary encodes data as an HTTP structured field Dictionary
+// of RFC 8941, e.g. `a=1, b="x";p, c`. Members are written in sorted key
+// order. Values may be integers, floats (Decimals), strings, SFToken
+// values, byte slices (Byte Sequences), booleans, []any for inner lists and
+// SFItem values for members with parameters. A member that is true is
+// written as its key alone.
+//
+// Parameters:
+//   - data: Dictionary members
+//
+// Returns:
+//   - string: Field value
+//   - error: Error if a key or value cannot be represented
+func EncodeSFDictionary(data map[string]any) (string, error) {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeSFKey(&b, key); err != nil {
+			return "", err
+		}
+		value, params := sfMember(data[key])
+		if value != true {
+			b.WriteByte('=')
+			if err := writeSFMemberValue(&b, value); err != nil {
+				return "", err
+			}
+		}
+		if err := writeSFParams(&b, params); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// DecodeSFDictionary parses an HTTP structured field Dictionary of RFC
+// 8941. Integers are decoded as int64, Decimals as float64, Strings as
+// string, Tokens as SFToken, Byte Sequences as []byte, Booleans as bool
+// and inner lists as []any. Members and inner list items with parameters
+// are decoded as SFItem values. A key that appears twice takes the last
+// value.
+//
+// Parameters:
+//   - s: Field value
+//
+// Returns:
+//   - map[string]any: Dictionary members
+//   - error: Error if the field value is invalid
+func DecodeSFDictionary(s string) (map[string]any, error) {
+	p := &sfParser{s: s}
+	data := make(map[string]any)
+	err := p.parseMembers(func() error {
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		var value any = true
+		if p.peek() == '=' {
+			p.pos++
+			if value, err = p.parseMemberValue(); err != nil {
+				return err
+			}
+		} else if params, err := p.parseParams(); err != nil {
+			return err
+		} else if params != nil {
+			value = SFItem{Value: true, Params: params}
+		}
+		data[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// EncodeSFList encodes members as an HTTP structured field List of RFC
+// 8941, e.g. `"a", (b c);p, 1`. Members are encoded like the values of
+// EncodeSFDictionary.
+//
+// Parameters:
+//   - members: List members
+//
+// Returns:
+//   - string: Field value
+//   - error: Error if a member cannot be represented
+func EncodeSFList(members []any) (string, error) {
+	var b strings.Builder
+	for i, member := range members {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value, params := sfMember(member)
+		if err := writeSFMemberValue(&b, value); err != nil {
+			return "", err
+		}
+		if err := writeSFParams(&b, params); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// DecodeSFList parses an HTTP structured field List of RFC 8941. Members
+// are decoded like the values of DecodeSFDictionary.
+//
+// Parameters:
+//   - s: Field value
+//
+// Returns:
+//   - []any: List members
+//   - error: Error if the field value is invalid
+func DecodeSFList(s string) ([]any, error) {
+	p := &sfParser{s: s}
+	members := []any{}
+	err := p.parseMembers(func() error {
+		member, err := p.parseMemberValue()
+		if err != nil {
+			return err
+		}
+		members = append(members, member)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// EncodeSFItem encodes a value as an HTTP structured field Item of RFC
+// 8941, e.g. `"text";lang=en`. The value is a bare item, or an SFItem with
+// a bare item and parameters.
+//
+// Parameters:
+//   - value: Item
+//
+// Returns:
+//   - string: Field value
+//   - error: Error if the value cannot be represented
+func EncodeSFItem(value any) (string, error) {
+	var b strings.Builder
+	value, params := sfMember(value)
+	if err := writeSFBareItem(&b, value); err != nil {
+		return "", err
+	}
+	if err := writeSFParams(&b, params); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// DecodeSFItem parses an HTTP structured field Item of RFC 8941. The item
+// is decoded like the values of DecodeSFDictionary.
+//
+// Parameters:
+//   - s: Field value
+//
+// Returns:
+//   - any: Item
+//   - error: Error if the field value is invalid
+func DecodeSFItem(s string) (any, error) {
+	p := &sfParser{s: s}
+	p.skipSpaces()
+	value, err := p.parseBareItem()
+	if err != nil {
+		return nil, err
+	}
+	if value, err = p.withParams(value); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected character %q", p.s[p.pos])
+	}
+	return value, nil
+}
+
+// sfMember splits a member into its value and parameters.
+func sfMember(member any) (any, map[string]any) {
+	if item, ok := member.(SFItem); ok {
+		return item.Value, item.Params
+	}
+	return member, nil
+}
+
+// writeSFMemberValue writes an item or inner list without parameters.
+func writeSFMemberValue(b *strings.Builder, value any) error {
+	list, ok := value.([]any)
+	if !ok {
+		return writeSFBareItem(b, value)
+	}
+	b.WriteByte('(')
+	for i, member := range list {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		value, params := sfMember(member)
+		if err := writeSFBareItem(b, value); err != nil {
+			return err
+		}
+		if err := writeSFParams(b, params); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return nil
+}
+
+// writeSFParams writes parameters in sorted key order. A parameter that is
+// true is written as its key alone.
+func writeSFParams(b *strings.Builder, params map[string]any) error {
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		b.WriteByte(';')
+		if err := writeSFKey(b, key); err != nil {
+			return err
+		}
+		if value := params[key]; value != true {
+			b.WriteByte('=')
+			if err := writeSFBareItem(b, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeSFKey writes a dictionary or parameter key.
+func writeSFKey(b *strings.Builder, key string) error {
+	if !isSFKey(key) {
+		return fmt.Errorf("%w: invalid key %q", ErrInvalidStructuredField, key)
+	}
+	b.WriteString(key)
+	return nil
+}
+
+// writeSFBareItem writes an Integer, Decimal, String, Token, Byte Sequence
+// or Boolean.
+func writeSFBareItem(b *strings.Builder, value any) error {
+	switch v := value.(type) {
+	case int:
+		return writeSFInteger(b, int64(v))
+	case int8:
+		return writeSFInteger(b, int64(v))
+	case int16:
+		return writeSFInteger(b, int64(v))
+	case int32:
+		return writeSFInteger(b, int64(v))
+	case int64:
+		return writeSFInteger(b, v)
+	case uint:
+		return writeSFUnsigned(b, uint64(v))
+	case uint8:
+		return writeSFUnsigned(b, uint64(v))
+	case uint16:
+		return writeSFUnsigned(b, uint64(v))
+	case uint32:
+		return writeSFUnsigned(b, uint64(v))
+	case uint64:
+		return writeSFUnsigned(b, v)
+	case float32:
+		return writeSFDecimal(b, float64(v))
+	case float64:
+		return writeSFDecimal(b, v)
+	case string:
+		return writeSFString(b, v)
+	case SFToken:
+		if !isSFToken(string(v)) {
+			return fmt.Errorf(
+				"%w: invalid token %q", ErrInvalidStructuredField, v,
+			)
+		}
+		b.WriteString(string(v))
+	case []byte:
+		b.WriteByte(':')
+		b.WriteString(base64.StdEncoding.EncodeToString(v))
+		b.WriteByte(':')
+	case bool:
+		if v {
+			b.WriteString("?1")
+		} else {
+			b.WriteString("?0")
+		}
+	default:
+		return fmt.Errorf(
+			"%w: unsupported item type %T", ErrInvalidStructuredField, value,
+		)
+	}
+	return nil
+}
+
+// writeSFInteger writes an Integer.
+func writeSFInteger(b *strings.Builder, v int64) error {
+	if v > maxSFInteger || v < -maxSFInteger {
+		return fmt.Errorf(
+			"%w: integer %d out of range", ErrInvalidStructuredField, v,
+		)
+	}
+	b.WriteString(strconv.FormatInt(v, 10))
+	return nil
+}
+
+// writeSFUnsigned writes an unsigned integer as an Integer.
+func writeSFUnsigned(b *strings.Builder, v uint64) error {
+	if v > maxSFInteger {
+		return fmt.Errorf(
+			"%w: integer %d out of range", ErrInvalidStructuredField, v,
+		)
+	}
+	return writeSFInteger(b, int64(v))
+}
+
+// writeSFDecimal writes a Decimal rounded to three fractional digits.
+func writeSFDecimal(b *strings.Builder, v float64) error {
+	v = math.RoundToEven(v*1000) / 1000
+	if math.IsNaN(v) || math.Abs(v) >= 1e12 {
+		return fmt.Errorf(
+			"%w: decimal %v out of range", ErrInvalidStructuredField, v,
+		)
+	}
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	b.WriteString(s)
+	return nil
+}
+
+// writeSFString writes a String, escaping quotes and backslashes.
+func writeSFString(b *strings.Builder, s string) error {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < ' ' || c > '~' {
+			return fmt.Errorf(
+				"%w: invalid character %q in string %q",
+				ErrInvalidStructuredField, c, s,
+			)
+		}
+		if c == '"' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return nil
+}
+
+// isSFKey reports whether s is a valid dictionary or parameter key.
+func isSFKey(s string) bool {
+	if s == "" || !isLCAlpha(s[0]) && s[0] != '*' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isSFKeyChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSFToken reports whether s is a valid Token.
+func isSFToken(s string) bool {
+	if s == "" || !isAlpha(s[0]) && s[0] != '*' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isSFTokenChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSFKeyChar reports whether c may follow the first character of a key.
+func isSFKeyChar(c byte) bool {
+	return isLCAlpha(c) || isDigit(c) || strings.IndexByte("_-.*", c) >= 0
+}
+
+// isSFTokenChar reports whether c may follow the first character of a
+// Token.
+func isSFTokenChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) ||
+		strings.IndexByte("!#$%&'*+-.^_`|~:/", c) >= 0
+}
+
+// isLCAlpha reports whether c is a lowercase ASCII letter.
+func isLCAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// isAlpha reports whether c is an ASCII letter.
+func isAlpha(c byte) bool {
+	return isLCAlpha(c) || c >= 'A' && c <= 'Z'
+}
+
+// sfParser parses structured field values as described in section 4.2 of
+// RFC 8941.
+type sfParser struct {
+	s   string // Field value
+	pos int    // Position of the next character
+}
+
+// errorf returns an ErrInvalidStructuredField error at the current
+// position.
+func (p *sfParser) errorf(format string, args ...any) error {
+	return fmt.Errorf(
+		"%w: %s at offset %d in %q", ErrInvalidStructuredField,
+		fmt.Sprintf(format, args...), p.pos, p.s,
+	)
+}
+
+// peek returns the next character, or 0 at the end of the value.
+func (p *sfParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+// skipSpaces skips spaces.
+func (p *sfParser) skipSpaces() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+// skipOWS skips spaces and tabs.
+func (p *sfParser) skipOWS() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.pos++
+	}
+}
+
+// parseMembers parses the comma-separated members of a List or Dictionary
+// with parseMember, which parses a single member.
+func (p *sfParser) parseMembers(parseMember func() error) error {
+	p.skipSpaces()
+	for p.pos < len(p.s) {
+		if err := parseMember(); err != nil {
+			return err
+		}
+		p.skipOWS()
+		if p.pos == len(p.s) {
+			break
+		}
+		if p.peek() != ',' {
+			return p.errorf("expected comma, got %q", p.peek())
+		}
+		p.pos++
+		p.skipOWS()
+		if p.pos == len(p.s) {
+			return p.errorf("trailing comma")
+		}
+	}
+	return nil
+}
+
+// parseMemberValue parses an item or inner list with its parameters.
+func (p *sfParser) parseMemberValue() (any, error) {
+	if p.peek() != '(' {
+		value, err := p.parseBareItem()
+		if err != nil {
+			return nil, err
+		}
+		return p.withParams(value)
+	}
+	p.pos++
+	list := []any{}
+	for {
+		p.skipSpaces()
+		if p.peek() == ')' {
+			p.pos++
+			return p.withParams(list)
+		}
+		value, err := p.parseBareItem()
+		if err != nil {
+			return nil, err
+		}
+		if value, err = p.withParams(value); err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		if c := p.peek(); c != ' ' && c != ')' {
+			return nil, p.errorf("expected space or \")\" in inner list")
+		}
+	}
+}
+
+// withParams parses the parameters that follow a value and returns the
+// value as an SFItem if it has parameters.
+func (p *sfParser) withParams(value any) (any, error) {
+	params, err := p.parseParams()
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return value, nil
+	}
+	return SFItem{Value: value, Params: params}, nil
+}
+
+// parseParams parses parameters. It returns nil if there are none.
+func (p *sfParser) parseParams() (map[string]any, error) {
+	var params map[string]any
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSpaces()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var value any = true
+		if p.peek() == '=' {
+			p.pos++
+			if value, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+		if params == nil {
+			params = make(map[string]any)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// parseKey parses a dictionary or parameter key.
+func (p *sfParser) parseKey() (string, error) {
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		return "", p.errorf("invalid key")
+	}
+	start := p.pos
+	for p.pos++; p.pos < len(p.s) && isSFKeyChar(p.s[p.pos]); p.pos++ {
+	}
+	return p.s[start:p.pos], nil
+}
+
+// parseBareItem parses an Integer, Decimal, String, Token, Byte Sequence
+// or Boolean.
+func (p *sfParser) parseBareItem() (any, error) {
+	switch c := p.peek(); {
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || isAlpha(c):
+		start := p.pos
+		for p.pos++; p.pos < len(p.s) && isSFTokenChar(p.s[p.pos]); p.pos++ {
+		}
+		return SFToken(p.s[start:p.pos]), nil
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		p.pos++
+		switch p.peek() {
+		case '0':
+			p.pos++
+			return false, nil
+		case '1':
+			p.pos++
+			return true, nil
+		}
+		return nil, p.errorf("invalid boolean")
+	}
+	return nil, p.errorf("invalid item")
+}
+
+// parseNumber parses an Integer as int64 or a Decimal as float64.
+func (p *sfParser) parseNumber() (any, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	dot := -1
+	for ; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		if c == '.' && dot < 0 {
+			if p.pos-digits > 12 {
+				return nil, p.errorf("decimal too long")
+			}
+			dot = p.pos
+			continue
+		}
+		if !isDigit(c) {
+			break
+		}
+	}
+	switch {
+	case p.pos == digits || dot == digits:
+		return nil, p.errorf("invalid number")
+	case dot < 0 && p.pos-digits > 15:
+		return nil, p.errorf("integer too long")
+	case dot < 0:
+		return strconv.ParseInt(p.s[start:p.pos], 10, 64)
+	case p.pos-dot-1 < 1 || p.pos-dot-1 > 3:
+		return nil, p.errorf("invalid decimal fraction")
+	}
+	return strconv.ParseFloat(p.s[start:p.pos], 64)
+}
+
+// parseString parses a String.
+func (p *sfParser) parseString() (string, error) {
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			p.pos++
+			if c = p.peek(); c != '"' && c != '\\' {
+				return "", p.errorf("invalid escape in string")
+			}
+		case c < ' ' || c > '~':
+			return "", p.errorf("invalid character %q in string", c)
+		}
+		b.WriteByte(c)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseByteSequence parses a Byte Sequence.
+func (p *sfParser) parseByteSequence() ([]byte, error) {
+	p.pos++
+	end := strings.IndexByte(p.s[p.pos:], ':')
+	if end < 0 {
+		return nil, p.errorf("unterminated byte sequence")
+	}
+	data, err := base64.StdEncoding.DecodeString(p.s[p.pos : p.pos+end])
+	if err != nil {
+		return nil, p.errorf("invalid byte sequence")
+	}
+	p.pos += end + 1
+	return data, nil
+}
@@ -0,0 +1,108 @@
+package urlcodec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSFDictionary verifies that dictionaries with items, inner lists and
+// parameters round trip.
+func TestSFDictionary(t *testing.T) {
+	field := `a=1, b="x \"y\"";p=?0, c=(tok 2.5;q);l, d, ` +
+		`e=:aGk=:, f;z=-3`
+	data, err := DecodeSFDictionary(field)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"a": int64(1),
+		"b": SFItem{Value: `x "y"`, Params: map[string]any{"p": false}},
+		"c": SFItem{
+			Value: []any{
+				SFToken("tok"),
+				SFItem{Value: 2.5, Params: map[string]any{"q": true}},
+			},
+			Params: map[string]any{"l": true},
+		},
+		"d": true,
+		"e": []byte("hi"),
+		"f": SFItem{Value: true, Params: map[string]any{"z": int64(-3)}},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("expected %v, got %v", expected, data)
+	}
+
+	encoded, err := EncodeSFDictionary(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoded != field {
+		t.Errorf("expected %s, got %s", field, encoded)
+	}
+}
+
+// TestSFList verifies that lists and items are encoded and parsed.
+func TestSFList(t *testing.T) {
+	encoded, err := EncodeSFList([]any{
+		"a", []any{SFToken("b"), 3}, SFItem{Value: 1.0, Params: nil},
+		SFItem{Value: []any{}, Params: map[string]any{"n": uint8(2)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `"a", (b 3), 1.0, ();n=2`
+	if encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	members, err := DecodeSFList(" sugar,\ttea, (rum  coke) ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []any{
+		SFToken("sugar"), SFToken("tea"),
+		[]any{SFToken("rum"), SFToken("coke")},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("expected %v, got %v", want, members)
+	}
+
+	item, err := EncodeSFItem(SFItem{
+		Value: 0.1235, Params: map[string]any{"lang": SFToken("en")},
+	})
+	if err != nil || item != "0.124;lang=en" {
+		t.Errorf("expected 0.124;lang=en, got %s, %v", item, err)
+	}
+	value, err := DecodeSFItem("?1")
+	if err != nil || value != true {
+		t.Errorf("expected true, got %v, %v", value, err)
+	}
+}
+
+// TestSF_Invalid verifies that invalid field values and unrepresentable
+// values are rejected with ErrInvalidStructuredField.
+func TestSF_Invalid(t *testing.T) {
+	for _, field := range []string{
+		"a=1,", "A=1", "a=1 b=2", `a="x`, `a="\n"`, "a=1.2345",
+		"a=1234567890123456", "a=(1,2)", "a=:!!:", "a=?2",
+	} {
+		if _, err := DecodeSFDictionary(field); !errors.Is(
+			err, ErrInvalidStructuredField,
+		) {
+			t.Errorf("expected ErrInvalidStructuredField for %q, got %v",
+				field, err)
+		}
+	}
+	for _, value := range []any{
+		"é", SFToken("1a"), int64(1e15), 1e12, map[string]any{},
+		SFItem{Value: 1, Params: map[string]any{"Bad": 1}},
+	} {
+		if _, err := EncodeSFItem(value); !errors.Is(
+			err, ErrInvalidStructuredField,
+		) {
+			t.Errorf("expected ErrInvalidStructuredField for %v, got %v",
+				value, err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package urlcodec
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// templateExpr is an expression of a URI template, e.g. "{?page,size}".
+type templateExpr struct {
+	op   byte          // Operator, 0 for simple string expansion
+	vars []templateVar // Variables of the expression
+}
+
+// templateVar is a variable of a template expression.
+type templateVar struct {
+	name    string // Variable name
+	explode bool   // Variable has the explode modifier "*"
+}
+
+// MatchTemplate extracts the variables of an RFC 6570 URI template from a
+// URL, the inverse of expanding the template, e.g. "/users/{id}{?fields}"
+// and "/users/42?fields=name" give id=42 and fields=name. All operators of
+// level 4 templates are supported. Prefix modifiers are ignored, so a
+// variable gets the whole matched value. Exploded variables get one value
+// per list element, and the name=value pairs of exploded variables in "?",
+// "&" and ";" expressions that are not template variables are nested under
+// the first exploded variable. The values are decoded like Decode, so
+// variable names such as "user.name" give nested maps.
+//
+// Parameters:
+//   - template: URI template
+//   - rawURL: URL to match
+//
+// Returns:
+//   - map[string]any: Decoded variables
+//   - error: ErrTemplateMismatch if the URL does not match the template
+func (e URLEncoder) MatchTemplate(
+	template string, rawURL string,
+) (map[string]any, error) {
+	pattern, exprs, err := compileTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	match := pattern.FindStringSubmatch(rawURL)
+	if match == nil {
+		return nil, fmt.Errorf(
+			"%w: %q does not match %q", ErrTemplateMismatch, rawURL, template,
+		)
+	}
+	matched := url.Values{}
+	for i, expr := range exprs {
+		if err := e.matchExpr(matched, expr, match[i+1]); err != nil {
+			return nil, fmt.Errorf("invalid value in %q: %w", rawURL, err)
+		}
+	}
+	// Variables with several values are lists and are indexed, so that they
+	// decode to slices whatever the handling of repeated keys.
+	values := make(url.Values, len(matched))
+	for key, vals := range matched {
+		if len(vals) == 1 {
+			values[key] = vals
+			continue
+		}
+		for i, val := range vals {
+			values.Set(indexKey(key, i), val)
+		}
+	}
+	return e.decode(values, nil)
+}
+
+// compileTemplate parses a URI template into a regular expression with one
+// group per expression.
+func compileTemplate(template string) (*regexp.Regexp, []templateExpr, error) {
+	var pattern strings.Builder
+	var exprs []templateExpr
+	pattern.WriteString("^")
+	rest := template
+	for rest != "" {
+		literal, after, found := strings.Cut(rest, "{")
+		pattern.WriteString(regexp.QuoteMeta(literal))
+		if !found {
+			break
+		}
+		body, next, ok := strings.Cut(after, "}")
+		if !ok {
+			return nil, nil, fmt.Errorf("unclosed expression in %q", template)
+		}
+		expr, err := parseTemplateExpr(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"invalid template %q: %w", template, err,
+			)
+		}
+		pattern.WriteString(exprPattern(expr.op))
+		exprs = append(exprs, expr)
+		rest = next
+	}
+	pattern.WriteString("$")
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid template %q: %w", template, err)
+	}
+	return re, exprs, nil
+}
+
+// parseTemplateExpr parses the body of a template expression, e.g.
+// "?page,size" or "/path*".
+func parseTemplateExpr(body string) (templateExpr, error) {
+	var expr templateExpr
+	if body != "" && strings.IndexByte("+#./;?&", body[0]) >= 0 {
+		expr.op, body = body[0], body[1:]
+	}
+	for _, spec := range strings.Split(body, ",") {
+		name, explode := strings.CutSuffix(spec, "*")
+		name, _, _ = strings.Cut(name, ":")
+		if name == "" {
+			return expr, fmt.Errorf("empty variable in {%s}", body)
+		}
+		expr.vars = append(expr.vars, templateVar{name, explode})
+	}
+	return expr, nil
+}
+
+// exprPattern returns the regular expression group that matches the
+// expansion of an expression with an operator.
+func exprPattern(op byte) string {
+	switch op {
+	case '+':
+		return `([^?#]*?)`
+	case '#':
+		return `(?:#(.*?))?`
+	case '.':
+		return `((?:\.[^/?#.]*)*?)`
+	case '/':
+		return `((?:/[^/?#]*)*?)`
+	case ';':
+		return `((?:;[^/?#]*)*?)`
+	case '?':
+		return `(?:\?([^#]*?))?`
+	case '&':
+		return `(?:&([^#]*?))?`
+	}
+	return `([^/?#]*?)`
+}
+
+// matchExpr adds the values of the variables of an expression from the
+// text that its expansion matched.
+func (e *URLEncoder) matchExpr(
+	values url.Values, expr templateExpr, text string,
+) error {
+	switch expr.op {
+	case ';', '?', '&':
+		return e.matchNamed(values, expr, text)
+	case '.', '/':
+		if text == "" {
+			return nil
+		}
+		return matchPositional(values, expr, text[1:], expr.op)
+	}
+	if text == "" {
+		return nil
+	}
+	return matchPositional(values, expr, text, ',')
+}
+
+// matchPositional assigns the parts of a matched text to the variables in
+// order. An exploded variable takes the remaining parts as a list and the
+// last variable takes the remaining text.
+func matchPositional(
+	values url.Values, expr templateExpr, text string, sep byte,
+) error {
+	parts := strings.Split(text, string(sep))
+	for i, v := range expr.vars {
+		if i >= len(parts) {
+			break
+		}
+		var vals []string
+		switch {
+		case v.explode:
+			vals = parts[i:]
+		case i == len(expr.vars)-1:
+			vals = []string{strings.Join(parts[i:], string(sep))}
+		default:
+			vals = parts[i : i+1]
+		}
+		for _, val := range vals {
+			if err := addUnescaped(values, v.name, val); err != nil {
+				return err
+			}
+		}
+		if v.explode {
+			break
+		}
+	}
+	return nil
+}
+
+// matchNamed assigns the name=value pairs of a matched ";", "?" or "&"
+// expansion to the variables of the same name. Pairs of other names are
+// nested under the first exploded variable, if any.
+func (e *URLEncoder) matchNamed(
+	values url.Values, expr templateExpr, text string,
+) error {
+	if text == "" {
+		return nil
+	}
+	sep := "&"
+	if expr.op == ';' {
+		sep, text = ";", text[1:]
+	}
+	var assoc string
+	names := make(map[string]bool, len(expr.vars))
+	for _, v := range expr.vars {
+		names[v.name] = true
+		if v.explode && assoc == "" {
+			assoc = v.name
+		}
+	}
+	for _, pair := range strings.Split(text, sep) {
+		name, value, _ := strings.Cut(pair, "=")
+		name, err := url.PathUnescape(name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case names[name]:
+		case assoc != "":
+			name = e.joinKey(assoc, name)
+		default:
+			continue
+		}
+		if err := addUnescaped(values, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addUnescaped adds a percent-encoded value to a key.
+func addUnescaped(values url.Values, key string, value string) error {
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return err
+	}
+	values.Add(key, unescaped)
+	return nil
+}
@@ -0,0 +1,79 @@
+package urlcodec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestMatchTemplate verifies that the variables of URI templates are
+// extracted from URLs for each operator.
+func TestMatchTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		url      string
+		expected map[string]any
+	}{
+		{
+			"/users/{id}{?fields,page}", "/users/42?fields=name&page=2",
+			map[string]any{"id": "42", "fields": "name", "page": "2"},
+		},
+		{
+			"/users/{id}{?fields,page}", "/users/42",
+			map[string]any{"id": "42"},
+		},
+		{
+			"/search{?q,filter*}", "/search?q=go&user.name=Ada&lang=en",
+			map[string]any{"q": "go", "filter": map[string]any{
+				"user": map[string]any{"name": "Ada"}, "lang": "en",
+			}},
+		},
+		{
+			"/items{?ids*}", "/items?ids=1&ids=2",
+			map[string]any{"ids": []any{"1", "2"}},
+		},
+		{
+			"/files{/path*}", "/files/a/b%20c",
+			map[string]any{"path": []any{"a", "b c"}},
+		},
+		{
+			"/report{.format}", "/report.json",
+			map[string]any{"format": "json"},
+		},
+		{
+			"/map{;x,y}", "/map;x=1;y=2",
+			map[string]any{"x": "1", "y": "2"},
+		},
+		{
+			"{+base}/static{#section}", "https://example.com/app/static#top",
+			map[string]any{"base": "https://example.com/app", "section": "top"},
+		},
+		{
+			"/point/{x,y}", "/point/3,4",
+			map[string]any{"x": "3", "y": "4"},
+		},
+		{
+			"/users/{user.id}", "/users/7",
+			map[string]any{"user": map[string]any{"id": "7"}},
+		},
+	}
+	encoder := NewURLEncoder()
+	for _, tt := range tests {
+		got, err := encoder.MatchTemplate(tt.template, tt.url)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.template, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.template, tt.expected, got)
+		}
+	}
+
+	_, err := encoder.MatchTemplate("/users/{id}", "/groups/1")
+	if !errors.Is(err, ErrTemplateMismatch) {
+		t.Errorf("expected ErrTemplateMismatch, got %v", err)
+	}
+	if _, err := encoder.MatchTemplate("/users/{id", "/users/1"); err == nil {
+		t.Error("expected error for invalid template, got nil")
+	}
+}
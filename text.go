@@ -0,0 +1,58 @@
+package urlcodec
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// cleansText reports whether decoded keys or values are rewritten before
+// decoding, by Unicode normalization or trimming.
+func (e *URLEncoder) cleansText() bool {
+	return e.normalizer != nil || e.trimKeys || e.trimValues
+}
+
+// cleanKey normalizes and trims a decoded key as configured.
+func (e *URLEncoder) cleanKey(key string) string {
+	key = e.normalizeText(key)
+	if e.trimKeys {
+		key = strings.TrimSpace(key)
+	}
+	return key
+}
+
+// cleanValue normalizes and trims a decoded value as configured.
+func (e *URLEncoder) cleanValue(value string) string {
+	value = e.normalizeText(value)
+	if e.trimValues {
+		value = strings.TrimSpace(value)
+	}
+	return value
+}
+
+// cleanValues returns the values with cleaned keys and values. The values
+// of keys that give the same key are merged in sorted order of the original
+// keys.
+func (e *URLEncoder) cleanValues(values url.Values) url.Values {
+	cleaned := rewriteKeys(values, e.cleanKey)
+	for _, vals := range cleaned {
+		for i, value := range vals {
+			vals[i] = e.cleanValue(value)
+		}
+	}
+	return cleaned
+}
+
+// rewriteKeys returns a copy of m with keys rewritten by rewrite. The
+// values of keys that give the same key are merged in sorted key order.
+func rewriteKeys[S ~[]E, E any](
+	m map[string]S, rewrite func(string) string,
+) map[string]S {
+	rewritten := make(map[string]S, len(m))
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		newKey := rewrite(key)
+		rewritten[newKey] = append(rewritten[newKey], m[key]...)
+	}
+	return rewritten
+}
@@ -0,0 +1,46 @@
+package urlcodec
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTrimSpace verifies that values and, if enabled, keys are trimmed.
+func TestTrimSpace(t *testing.T) {
+	query := "name=+Ann%C2%A0&tags[0]=%09go+&+email+=x&note=+"
+	got, err := NewURLEncoder(WithTrimSpace()).DecodeString(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"name": "Ann", "tags": []any{"go"}, " email ": "x", "note": "",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	encoder := NewURLEncoder(
+		WithTrimSpace(), WithTrimKeySpace(), WithEmptyAsNull(),
+	)
+	got, err = encoder.DecodeString(query + "&email=y")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = map[string]any{
+		"name": "Ann", "tags": []any{"go"}, "email": "x", "note": nil,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	var target struct {
+		Name string `json:"name"`
+	}
+	values := map[string][]string{"name": {" Bob "}}
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Name != "Bob" {
+		t.Errorf("expected Bob, got %q", target.Name)
+	}
+}
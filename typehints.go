@@ -0,0 +1,71 @@
+package urlcodec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type hints appended to keys with WithTypeHints.
+const (
+	hintString = "string"
+	hintInt    = "int"
+	hintFloat  = "float"
+	hintBool   = "bool"
+	hintNull   = "null"
+)
+
+// hintKey appends a type hint to a key if type hints are enabled, e.g.
+// "age" with "int" gives "age:int".
+func (e *URLEncoder) hintKey(key string, hint string) string {
+	if !e.typeHints {
+		return key
+	}
+	return key + ":" + hint
+}
+
+// splitTypeHint removes the type hint after the last colon of a key if type
+// hints are enabled and returns the key and the hint. Keys without a known
+// hint are returned unchanged with an empty hint.
+func (e *URLEncoder) splitTypeHint(key string) (string, string) {
+	if !e.typeHints {
+		return key, ""
+	}
+	i := strings.LastIndexByte(key, ':')
+	if i < 0 {
+		return key, ""
+	}
+	switch hint := key[i+1:]; hint {
+	case hintString, hintInt, hintFloat, hintBool, hintNull:
+		return key[:i], hint
+	}
+	return key, ""
+}
+
+// parseTypeHint converts a value to the type given by its hint. Values
+// without a hint stay strings.
+func parseTypeHint(value string, hint string) (any, error) {
+	switch hint {
+	case hintInt:
+		n, err := strconv.ParseInt(value, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", value)
+		}
+		return int(n), nil
+	case hintFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", value)
+		}
+		return f, nil
+	case hintBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool %q", value)
+		}
+		return b, nil
+	case hintNull:
+		return nil, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,89 @@
+package urlcodec
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestWithTypeHints verifies that Encode followed by Decode restores the
+// types of map values.
+func TestWithTypeHints(t *testing.T) {
+	data := map[string]any{
+		"age":    30,
+		"score":  0.1 + 0.2,
+		"small":  1e-7,
+		"ok":     true,
+		"name":   "Ada",
+		"digits": "30",
+		"parent": nil,
+		"a:b":    "colon",
+		"list":   []any{1, "1", 1.5, false, nil},
+		"nested": map[string]any{"n": -2, "s": "x"},
+	}
+	encoder := NewURLEncoder(WithTypeHints())
+	values, err := encoder.Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, key := range []string{"age:int", "name:string", "parent:null"} {
+		if !values.Has(key) {
+			t.Errorf("expected key %q in %v", key, values)
+		}
+	}
+	decoded, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %v, got %v", data, decoded)
+	}
+
+	bracket := NewURLEncoder(WithTypeHints(), WithQSCompat(QSOptions{}))
+	values, err = bracket.Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err = bracket.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("qs: expected %v, got %v", data, decoded)
+	}
+}
+
+// TestWithTypeHints_DecodeInto verifies that hinted values are assigned to
+// struct fields and that invalid hinted values are rejected.
+func TestWithTypeHints_DecodeInto(t *testing.T) {
+	type Target struct {
+		Age   int     `json:"age"`
+		Score float64 `json:"score"`
+		Label string  `json:"label"`
+		Any   any     `json:"any"`
+		Ptr   *int    `json:"ptr"`
+		Any2  any     `json:"any2"`
+	}
+	encoder := NewURLEncoder(WithTypeHints())
+	values := url.Values{
+		"age:int":     {"30"},
+		"score:float": {"1.5"},
+		"label:int":   {"7"},
+		"any:bool":    {"true"},
+		"ptr:null":    {""},
+		"any2:null":   {""},
+	}
+	var target Target
+	if err := encoder.DecodeInto(values, &target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Target{Age: 30, Score: 1.5, Label: "7", Any: true}
+	if !reflect.DeepEqual(target, expected) {
+		t.Errorf("expected %+v, got %+v", expected, target)
+	}
+
+	values = url.Values{"age:int": {"old"}}
+	if err := encoder.DecodeInto(values, &target); err == nil {
+		t.Fatal("expected error for invalid hinted value, got nil")
+	}
+}
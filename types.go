@@ -0,0 +1,453 @@
+package urlcodec
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	bigIntType   = reflect.TypeFor[big.Int]()
+	bigFloatType = reflect.TypeFor[big.Float]()
+	numberType   = reflect.TypeFor[json.Number]()
+)
+
+// DurationFormat selects how time.Duration values are represented.
+type DurationFormat int
+
+const (
+	// DurationString represents durations as Go duration strings, e.g.
+	// "1h30m0s".
+	DurationString DurationFormat = iota
+	// DurationSeconds represents durations as decimal seconds, e.g. "5400"
+	// or "1.5".
+	DurationSeconds
+)
+
+// BytesFormat selects how []byte and [N]byte values are represented.
+type BytesFormat int
+
+const (
+	// BytesBase64 represents bytes as padded URL-safe base64.
+	BytesBase64 BytesFormat = iota
+	// BytesHex represents bytes as lowercase hex.
+	BytesHex
+)
+
+// TimeEpoch selects whether time.Time values are represented as Unix
+// timestamps instead of layout strings.
+type TimeEpoch int
+
+const (
+	// NoEpoch represents times with the configured layout.
+	NoEpoch TimeEpoch = iota
+	// EpochSeconds represents times as Unix seconds, e.g. "1700000000".
+	EpochSeconds
+	// EpochMillis represents times as Unix milliseconds, e.g.
+	// "1700000000000".
+	EpochMillis
+)
+
+// BoolFormat selects how bool values are represented when encoding.
+type BoolFormat int
+
+const (
+	// BoolTrueFalse represents bools as "true" and "false".
+	BoolTrueFalse BoolFormat = iota
+	// BoolOneZero represents bools as "1" and "0".
+	BoolOneZero
+	// BoolYesNo represents bools as "yes" and "no".
+	BoolYesNo
+	// BoolOnOff represents bools as "on" and "off", like HTML checkboxes.
+	BoolOnOff
+)
+
+// tokens returns the representations of true and false in the format.
+func (f BoolFormat) tokens() (string, string) {
+	switch f {
+	case BoolOneZero:
+		return "1", "0"
+	case BoolYesNo:
+		return "yes", "no"
+	case BoolOnOff:
+		return "on", "off"
+	default:
+		return "true", "false"
+	}
+}
+
+// formatBool returns the representation of a bool in the configured format.
+// Type hints always use "true" and "false".
+func (e *URLEncoder) formatBool(b bool) string {
+	if e.typeHints {
+		return strconv.FormatBool(b)
+	}
+	t, f := e.boolFormat.tokens()
+	if b {
+		return t
+	}
+	return f
+}
+
+// parseBool parses a bool accepted by strconv.ParseBool, the tokens of the
+// configured format or the configured truthy and falsey tokens. Tokens are
+// compared case-insensitively. It returns false if s is not a bool.
+func (e *URLEncoder) parseBool(s string) (bool, bool) {
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b, true
+	}
+	t, f := e.boolFormat.tokens()
+	switch {
+	case strings.EqualFold(s, t) || containsFold(e.truthy, s):
+		return true, true
+	case strings.EqualFold(s, f) || containsFold(e.falsey, s):
+		return false, true
+	}
+	return false, false
+}
+
+// containsFold reports whether tokens contains s, ignoring case.
+func containsFold(tokens []string, s string) bool {
+	for _, token := range tokens {
+		if strings.EqualFold(token, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeKnownType encodes values of types that have a dedicated encoding.
+// It returns false if the type of the value has no dedicated encoding.
+func (e *URLEncoder) encodeKnownType(
+	values *url.Values, fieldTag string, v reflect.Value,
+) (bool, error) {
+	if !v.IsValid() || !v.CanInterface() {
+		return false, nil
+	}
+	switch v.Type() {
+	case orderedMapType:
+		return true, e.encodeOrderedMap(values, fieldTag, v)
+	case timeType:
+		return true, e.encodeTime(values, fieldTag, v)
+	case durationType:
+		return true, e.encodeDuration(values, fieldTag, v)
+	case bigIntType:
+		return true, e.encodeBigInt(values, fieldTag, v)
+	case bigFloatType:
+		return true, e.encodeBigFloat(values, fieldTag, v)
+	case numberType:
+		return true, e.encodeNumber(values, fieldTag, v)
+	case rawValueType:
+		return true, e.encodeRawValue(values, fieldTag, v)
+	}
+	return false, nil
+}
+
+// assignKnownType assigns decoded data to values of types that have a
+// dedicated encoding. It returns false if the type of the value has no
+// dedicated encoding.
+func (e *URLEncoder) assignKnownType(
+	dst reflect.Value, src any, path string,
+) (bool, error) {
+	switch dst.Type() {
+	case timeType:
+		return true, e.assignTime(dst, src, path)
+	case durationType:
+		return true, e.assignDuration(dst, src, path)
+	case bigIntType:
+		return true, e.assignBigInt(dst, src, path)
+	case bigFloatType:
+		return true, e.assignBigFloat(dst, src, path)
+	case numberType:
+		return true, e.assignNumber(dst, src, path)
+	}
+	return false, nil
+}
+
+// encodeTime encodes a time.Time using the configured layout or epoch.
+func (e *URLEncoder) encodeTime(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	t, ok := v.Interface().(time.Time)
+	if !ok {
+		return fmt.Errorf("expected time.Time, got %s", v.Type())
+	}
+	switch e.timeEpoch {
+	case EpochSeconds:
+		s := strconv.FormatInt(t.Unix(), 10)
+		return e.setValue(values, fieldTag, s)
+	case EpochMillis:
+		s := strconv.FormatInt(t.UnixMilli(), 10)
+		return e.setValue(values, fieldTag, s)
+	default:
+		return e.setValue(values, fieldTag, t.Format(e.timeFormat))
+	}
+}
+
+// assignTime parses a decoded string into a time.Time using the configured
+// layout or epoch. Epoch values are decoded in UTC.
+func (e *URLEncoder) assignTime(
+	dst reflect.Value, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	var t time.Time
+	if e.timeEpoch == NoEpoch {
+		var err error
+		t, err = time.Parse(e.timeFormat, s)
+		if err != nil {
+			return fmt.Errorf("invalid time at %q: %q", path, s)
+		}
+	} else {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid time at %q: %q", path, s)
+		}
+		if e.timeEpoch == EpochMillis {
+			t = time.UnixMilli(n).UTC()
+		} else {
+			t = time.Unix(n, 0).UTC()
+		}
+	}
+	dst.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// withTimeEpoch returns the encoder if it uses the epoch, or a copy of it
+// that does.
+func (e *URLEncoder) withTimeEpoch(epoch TimeEpoch) *URLEncoder {
+	if e.timeEpoch == epoch {
+		return e
+	}
+	c := *e
+	c.timeEpoch = epoch
+	return &c
+}
+
+// encodeDuration encodes a time.Duration using the configured format.
+func (e *URLEncoder) encodeDuration(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	d := time.Duration(v.Int())
+	switch e.durationFormat {
+	case DurationSeconds:
+		s := strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+		return e.setValue(values, fieldTag, s)
+	default:
+		return e.setValue(values, fieldTag, d.String())
+	}
+}
+
+// assignDuration parses a decoded string into a time.Duration using the
+// configured format.
+func (e *URLEncoder) assignDuration(
+	dst reflect.Value, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	var d time.Duration
+	switch e.durationFormat {
+	case DurationSeconds:
+		secs, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid duration at %q: %q", path, s)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	default:
+		var err error
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration at %q: %q", path, s)
+		}
+	}
+	dst.SetInt(int64(d))
+	return nil
+}
+
+// encodeBigInt encodes a big.Int in decimal.
+func (e *URLEncoder) encodeBigInt(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	x, ok := v.Interface().(big.Int)
+	if !ok {
+		return fmt.Errorf("expected big.Int, got %s", v.Type())
+	}
+	return e.setValue(values, fieldTag, x.String())
+}
+
+// encodeBigFloat encodes a big.Float with the shortest decimal that
+// represents it exactly at its precision.
+func (e *URLEncoder) encodeBigFloat(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	x, ok := v.Interface().(big.Float)
+	if !ok {
+		return fmt.Errorf("expected big.Float, got %s", v.Type())
+	}
+	return e.setValue(values, fieldTag, x.Text('g', -1))
+}
+
+// assignBigInt parses a decoded decimal string into a big.Int.
+func (e *URLEncoder) assignBigInt(
+	dst reflect.Value, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid big integer at %q: %q", path, s)
+	}
+	dst.Set(reflect.ValueOf(x).Elem())
+	return nil
+}
+
+// assignBigFloat parses a decoded decimal string into a big.Float. If the
+// target has no precision set, the precision is derived from the number of
+// characters in the string so that no decimal digits are lost.
+func (e *URLEncoder) assignBigFloat(
+	dst reflect.Value, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	prec := dst.Addr().Interface().(*big.Float).Prec()
+	if prec == 0 {
+		prec = max(64, uint(math.Ceil(float64(len(s))*math.Log2(10))))
+	}
+	x, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return fmt.Errorf("invalid big float at %q: %q", path, s)
+	}
+	dst.Set(reflect.ValueOf(x).Elem())
+	return nil
+}
+
+// encodeNumber encodes a json.Number as its literal text.
+func (e *URLEncoder) encodeNumber(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	return e.setValue(values, fieldTag, v.String())
+}
+
+// assignNumber assigns a decoded string to a json.Number if it is a valid
+// JSON number literal.
+func (e *URLEncoder) assignNumber(
+	dst reflect.Value, src any, path string,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	if !isNumberLiteral(s) {
+		return fmt.Errorf("invalid number at %q: %q", path, s)
+	}
+	dst.SetString(s)
+	return nil
+}
+
+// isNumberLiteral reports whether s is a valid JSON number literal.
+func isNumberLiteral(s string) bool {
+	if s == "" || (s[0] != '-' && !isDigit(s[0])) || !isDigit(s[len(s)-1]) {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// isDigit reports whether c is an ASCII decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isBytes reports whether a value is a []byte or [N]byte that does not
+// implement encoding.TextMarshaler.
+func isBytes(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+	if v.Type().Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	_, ok := asMarshaler[encoding.TextMarshaler](v)
+	return !ok
+}
+
+// isBytesType reports whether a type is a []byte or [N]byte that does not
+// implement encoding.TextUnmarshaler.
+func isBytesType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return false
+	}
+	if t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(
+		reflect.TypeFor[encoding.TextUnmarshaler](),
+	)
+}
+
+// encodeBytes encodes a []byte or [N]byte using the given format.
+func (e *URLEncoder) encodeBytes(
+	values *url.Values, fieldTag string, v reflect.Value, format BytesFormat,
+) error {
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	switch format {
+	case BytesHex:
+		return e.setValue(values, fieldTag, hex.EncodeToString(b))
+	default:
+		s := base64.URLEncoding.EncodeToString(b)
+		return e.setValue(values, fieldTag, s)
+	}
+}
+
+// assignBytes decodes a string into a []byte or [N]byte using the given
+// format.
+func (e *URLEncoder) assignBytes(
+	dst reflect.Value, src any, path string, format BytesFormat,
+) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("expected value at %q, got %T", path, src)
+	}
+	var b []byte
+	var err error
+	switch format {
+	case BytesHex:
+		b, err = hex.DecodeString(s)
+	default:
+		b, err = base64.URLEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid bytes at %q: %w", path, err)
+	}
+	if dst.Kind() == reflect.Array {
+		if len(b) != dst.Len() {
+			return fmt.Errorf(
+				"invalid bytes at %q: got %d bytes, array length is %d",
+				path, len(b), dst.Len(),
+			)
+		}
+		reflect.Copy(dst, reflect.ValueOf(b))
+		return nil
+	}
+	dst.SetBytes(b)
+	return nil
+}
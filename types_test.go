@@ -0,0 +1,335 @@
+package urlcodec
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEncode_Time verifies that time.Time values are encoded as RFC 3339 by
+// default and with a custom layout when configured.
+func TestEncode_Time(t *testing.T) {
+	type Event struct {
+		At  time.Time  `json:"at"`
+		Ptr *time.Time `json:"ptr"`
+	}
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	input := Event{At: at, Ptr: &at}
+
+	values, err := NewURLEncoder().Encode(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("at"); got != "2024-05-06T07:08:09Z" {
+		t.Errorf("expected RFC 3339 time, got %q", got)
+	}
+	if got := values.Get("ptr"); got != "2024-05-06T07:08:09Z" {
+		t.Errorf("expected RFC 3339 time for pointer, got %q", got)
+	}
+
+	encoder := NewURLEncoder(WithTimeFormat(time.DateOnly))
+	values, err = encoder.Encode(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("at"); got != "2024-05-06" {
+		t.Errorf("expected date-only time, got %q", got)
+	}
+}
+
+// TestDecodeInto_Time verifies that time.Time fields are parsed using the
+// configured layout.
+func TestDecodeInto_Time(t *testing.T) {
+	type Event struct {
+		At time.Time `json:"at"`
+	}
+	values := url.Values{}
+	values.Set("at", "2024-05-06")
+
+	var event Event
+	encoder := NewURLEncoder(WithTimeFormat(time.DateOnly))
+	if err := encoder.DecodeInto(values, &event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	if !event.At.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, event.At)
+	}
+	if err := NewURLEncoder().DecodeInto(values, &event); err == nil {
+		t.Fatal("expected error for non-RFC 3339 time, got nil")
+	}
+}
+
+// TestEncodeDecode_Duration verifies that time.Duration values round-trip
+// as duration strings by default and as seconds when configured.
+func TestEncodeDecode_Duration(t *testing.T) {
+	type Job struct {
+		Timeout time.Duration `json:"timeout"`
+	}
+	tests := []struct {
+		name     string
+		encoder  *URLEncoder
+		expected string
+	}{
+		{"string", NewURLEncoder(), "1h30m0s"},
+		{
+			"seconds",
+			NewURLEncoder(WithDurationFormat(DurationSeconds)),
+			"5400",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := Job{Timeout: 90 * time.Minute}
+			values, err := tt.encoder.Encode(job)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := values.Get("timeout"); got != tt.expected {
+				t.Errorf("expected timeout=%s, got %q", tt.expected, got)
+			}
+			var decoded Job
+			if err := tt.encoder.DecodeInto(values, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decoded != job {
+				t.Errorf("expected %v, got %v", job, decoded)
+			}
+		})
+	}
+}
+
+// TestEncodeDecode_Bytes verifies that bytes use base64 by default, hex when
+// configured, and that the tag options override the encoder format.
+func TestEncodeDecode_Bytes(t *testing.T) {
+	type Blob struct {
+		Data     []byte  `json:"data"`
+		Checksum [4]byte `json:"checksum,hex"`
+	}
+	blob := Blob{
+		Data:     []byte{0xfb, 0xff},
+		Checksum: [4]byte{0xde, 0xad, 0xbe, 0xef},
+	}
+	tests := []struct {
+		name     string
+		encoder  *URLEncoder
+		expected string
+	}{
+		{"base64", NewURLEncoder(), "-_8="},
+		{"hex", NewURLEncoder(WithBytesFormat(BytesHex)), "fbff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := tt.encoder.Encode(blob)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := values.Get("data"); got != tt.expected {
+				t.Errorf("expected data=%s, got %q", tt.expected, got)
+			}
+			if got := values.Get("checksum"); got != "deadbeef" {
+				t.Errorf("expected checksum=deadbeef, got %q", got)
+			}
+			var decoded Blob
+			if err := tt.encoder.DecodeInto(values, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(decoded.Data) != string(blob.Data) ||
+				decoded.Checksum != blob.Checksum {
+				t.Errorf("expected %v, got %v", blob, decoded)
+			}
+		})
+	}
+}
+
+// TestDecodeInto_BytesArrayLength verifies that decoded bytes must match the
+// length of a byte array.
+func TestDecodeInto_BytesArrayLength(t *testing.T) {
+	type Blob struct {
+		Checksum [4]byte `json:"checksum,hex"`
+	}
+	values := url.Values{}
+	values.Set("checksum", "dead")
+	var blob Blob
+	if err := NewURLEncoder().DecodeInto(values, &blob); err == nil {
+		t.Fatal("expected error for short byte array, got nil")
+	}
+}
+
+// TestEncodeDecode_Big verifies that big.Int and big.Float values round-trip
+// without losing precision.
+func TestEncodeDecode_Big(t *testing.T) {
+	type Amount struct {
+		Wei   big.Int    `json:"wei"`
+		Rate  *big.Float `json:"rate"`
+		Total *big.Int   `json:"total"`
+	}
+	wei, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	rate, _, _ := big.ParseFloat(
+		"3.14159265358979323846264338327950288", 10, 200, big.ToNearestEven,
+	)
+	amount := Amount{Wei: *wei, Rate: rate, Total: big.NewInt(-5)}
+
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(amount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("wei"); got != wei.String() {
+		t.Errorf("expected wei=%s, got %q", wei, got)
+	}
+	if got := values.Get("total"); got != "-5" {
+		t.Errorf("expected total=-5, got %q", got)
+	}
+
+	var decoded Amount
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Wei.Cmp(wei) != 0 {
+		t.Errorf("expected wei=%s, got %s", wei, &decoded.Wei)
+	}
+	if decoded.Total == nil || decoded.Total.Int64() != -5 {
+		t.Errorf("expected total=-5, got %v", decoded.Total)
+	}
+	if decoded.Rate == nil ||
+		decoded.Rate.Text('g', 30) != rate.Text('g', 30) {
+		t.Errorf("expected rate=%s, got %v", rate.Text('g', 30), decoded.Rate)
+	}
+}
+
+// TestEncodeDecode_JSONNumber verifies that json.Number values are encoded as
+// their literal text and validated on decode.
+func TestEncodeDecode_JSONNumber(t *testing.T) {
+	type Payload struct {
+		Amount json.Number `json:"amount"`
+	}
+	var data map[string]any
+	dec := json.NewDecoder(strings.NewReader(
+		`{"amount": 12345678901234567890.125, "nested": {"n": -1e3}}`,
+	))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("amount"); got != "12345678901234567890.125" {
+		t.Errorf("expected literal amount, got %q", got)
+	}
+	if got := values.Get("nested.n"); got != "-1e3" {
+		t.Errorf("expected nested.n=-1e3, got %q", got)
+	}
+
+	var payload Payload
+	if err := encoder.DecodeInto(values, &payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Amount != "12345678901234567890.125" {
+		t.Errorf("expected literal amount, got %q", payload.Amount)
+	}
+
+	values.Set("amount", "12abc")
+	if err := encoder.DecodeInto(values, &payload); err == nil {
+		t.Fatal("expected error for invalid number, got nil")
+	}
+}
+
+// TestEncodeDecode_BoolFormat verifies that bools are written in the
+// configured format and that DecodeInto accepts its tokens and extra ones.
+func TestEncodeDecode_BoolFormat(t *testing.T) {
+	type Flags struct {
+		On  bool `json:"on"`
+		Off bool `json:"off"`
+	}
+	tests := []struct {
+		name    string
+		format  BoolFormat
+		on, off string
+	}{
+		{"true false", BoolTrueFalse, "true", "false"},
+		{"one zero", BoolOneZero, "1", "0"},
+		{"yes no", BoolYesNo, "yes", "no"},
+		{"on off", BoolOnOff, "on", "off"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewURLEncoder(WithBoolFormat(tt.format))
+			flags := Flags{On: true}
+			values, err := encoder.Encode(flags)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if values.Get("on") != tt.on || values.Get("off") != tt.off {
+				t.Errorf("expected on=%s&off=%s, got %s",
+					tt.on, tt.off, values.Encode())
+			}
+			var decoded Flags
+			if err := encoder.DecodeInto(values, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decoded != flags {
+				t.Errorf("expected %v, got %v", flags, decoded)
+			}
+		})
+	}
+
+	values := url.Values{"on": {"Y"}, "off": {"n"}}
+	var decoded Flags
+	encoder := NewURLEncoder(WithBoolTokens([]string{"y"}, []string{"n"}))
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.On || decoded.Off {
+		t.Errorf("expected on and not off, got %v", decoded)
+	}
+	if err := NewURLEncoder().DecodeInto(values, &decoded); err == nil {
+		t.Fatal("expected error for unknown bool token, got nil")
+	}
+}
+
+// TestEncodeDecode_TimeEpoch verifies that times round-trip as Unix seconds
+// or milliseconds with WithTimeEpoch and the "unix" and "unixmilli" tags.
+func TestEncodeDecode_TimeEpoch(t *testing.T) {
+	type Event struct {
+		At      time.Time  `json:"at"`
+		Seen    time.Time  `json:"seen,unix"`
+		Clicked *time.Time `json:"clicked,unixmilli"`
+	}
+	at := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	clicked := at.Add(1500 * time.Millisecond)
+	event := Event{At: at, Seen: at, Clicked: &clicked}
+	encoder := NewURLEncoder(WithTimeEpoch(EpochMillis))
+	values, err := encoder.Encode(event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"at":      {"1700000000000"},
+		"seen":    {"1700000000"},
+		"clicked": {"1700000001500"},
+	}
+	if values.Encode() != expected.Encode() {
+		t.Errorf("expected %s, got %s", expected.Encode(), values.Encode())
+	}
+	var decoded Event
+	if err := encoder.DecodeInto(values, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.At.Equal(at) || !decoded.Seen.Equal(at) ||
+		decoded.Clicked == nil || !decoded.Clicked.Equal(clicked) {
+		t.Errorf("expected %v, got %v", event, decoded)
+	}
+
+	values.Set("seen", at.Format(time.RFC3339))
+	if err := encoder.DecodeInto(values, &decoded); err == nil {
+		t.Fatal("expected error for non-epoch time, got nil")
+	}
+}
@@ -0,0 +1,29 @@
+package urlcodec
+
+import "unicode/utf8"
+
+// UnicodeNormalizer normalizes Unicode text to one normalization form, such
+// as norm.NFC of golang.org/x/text/unicode/norm.
+type UnicodeNormalizer interface {
+	// String returns s in the normalization form.
+	String(s string) string
+}
+
+// normalizeText normalizes a decoded key or value if a normalizer is set.
+// ASCII text is already in every normalization form and is returned as is.
+func (e *URLEncoder) normalizeText(s string) string {
+	if e.normalizer == nil || isASCII(s) {
+		return s
+	}
+	return e.normalizer.String(s)
+}
+
+// isASCII reports whether s has only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package urlcodec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// composer is a UnicodeNormalizer that composes "e" followed by a combining
+// acute accent, standing in for norm.NFC.
+type composer struct{}
+
+// String composes the accented characters of s.
+func (composer) String(s string) string {
+	return strings.ReplaceAll(s, "e\u0301", "\u00e9")
+}
+
+// TestUnicodeNormalization verifies that differently composed keys collapse
+// to one key and that values are normalized.
+func TestUnicodeNormalization(t *testing.T) {
+	query := "caf%C3%A9.name=a&cafe%CC%81.size=b&tags[0]=e%CC%81"
+	encoder := NewURLEncoder(WithUnicodeNormalization(composer{}))
+	got, err := encoder.DecodeString(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"caf\u00e9": map[string]any{"name": "a", "size": "b"},
+		"tags":      []any{"\u00e9"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	ordered, err := encoder.DecodeOrdered("cafe%CC%81=1&a=2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := ordered.Keys()
+	if !reflect.DeepEqual(keys, []string{"caf\u00e9", "a"}) {
+		t.Errorf("expected keys [caf\u00e9 a], got %v", keys)
+	}
+
+	got, err = NewURLEncoder().DecodeString(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected 3 keys without normalization, got %v", got)
+	}
+}
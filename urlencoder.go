@@ -2,31 +2,94 @@ package urlcodec
 
 import (
 	"fmt"
+	"maps"
+	"mime/multipart"
 	"net/url"
 	"reflect"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	maxRecursionDepth = 10   // Maximum allowed depth for nested structures
+	maxRecursionDepth = 10   // Default maximum depth for nested structures
 	maxSliceSize      = 1000 // Maximum allowed size for slices
-
-	// Matches a string with a word followed by "[" and a number in decimal
-	// (base 10) and "]" e.g. "mySlice[0]" matches as "mySlice" and "0"
-	sliceRegexp = `(\w+)\[(\d+)\]`
 )
 
 // URLEncoder encodes and decodes URL values.
-type URLEncoder struct{}
+type URLEncoder struct {
+	maxDepth       int            // Maximum depth for nested keys, 0 for none
+	maxDecoded     int            // Approximate decoded bytes, 0 for none
+	maxKeys        int            // Maximum number of decoded keys, 0 for none
+	maxKeyLength   int            // Maximum length of a key, 0 for none
+	maxSegments    int            // Maximum segments of a key, 0 for none
+	maxValueLength int            // Maximum length of a value, 0 for none
+	doubleEncoding DoubleEncoding // Handling of twice-encoded values
+	maxInflated    int            // Maximum decompressed bytes, 0 for 1 MiB
+	tagName        string         // Struct tag used for field names
+	timeFormat     string         // Layout used for time.Time values
+	timeEpoch      TimeEpoch      // Unix timestamps used for time.Time
+	durationFormat DurationFormat // Format used for time.Duration values
+	bytesFormat    BytesFormat    // Format used for []byte and [N]byte
+	boolFormat     BoolFormat     // Format used for encoded bools
+	truthy         []string       // Extra tokens decoded as true
+	falsey         []string       // Extra tokens decoded as false
+	notation       Notation       // Notation used for nested keys
+	sliceStyle     SliceStyle     // Style used for slices and arrays
+	escapeScalars  bool           // Escape slice delimiters in scalars too
+	repeatedKeys   RepeatedKeys   // Decoding of keys with several values
+	qs             *QSOptions     // npm qs compatibility, nil if disabled
+	allowUntagged  bool           // Name untagged fields by their Go names
+	strictKeys     bool           // Reject keys that match no struct field
+	allowedKeys    [][]string     // Segments of allowed key patterns
+	deniedKeys     [][]string     // Segments of denied key patterns
+	filteredKeys   FilteredKeys   // Handling of excluded keys
+	inferTypes     bool           // Decode numbers and bools to Go types
+	typeHints      bool           // Write and read type hints in keys
+	delimiterKeys  DelimiterKeys  // Handling of delimiters in map keys
+	collectErrors  bool           // Report all decoding errors, not the first
+	trimKeys       bool           // Trim spaces around decoded keys
+	trimValues     bool           // Trim spaces around decoded values
+	omitZero       bool           // Omit zero fields and map values
+	sortMapKeys    bool           // Encode map entries in key order
+	parallelism    int            // Goroutines for large top-level maps
+	maxQueryLength int            // Maximum encoded length, 0 for none
+	prefix         string         // Key all keys are nested under, or empty
+	query          queryFormat    // Separators and escaping of query strings
+	order          *keyOrder      // Records encoded keys, nil if disabled
+	raw            *rawValues     // Records raw values, nil if disabled
+	missing        *[]string      // Records missing required fields
+	nullSentinel   *string        // Value written for nil, nil if omitted
+	emptyAsNull    bool           // Decode empty values as nil
+	precedence     Precedence     // Source that wins in DecodeRequest
+
+	encoders         map[reflect.Type]EncoderFunc // Custom encoders by type
+	keyTransformer   KeyTransformer               // Rewrites encoded key names
+	valueTransformer ValueTransformer             // Rewrites encoded values
+	conflicts        ConflictStrategy             // Resolves conflicting keys
+	decodeHook       DecodeHook                   // Converts decoded strings
+	normalizer       UnicodeNormalizer            // Normalizes decoded text
+	flush            func(url.Values) error       // Takes top-level values
+}
 
 // NewURLEncoder returns a new URLEncoder.
 //
+// Parameters:
+//   - opts: Options to configure the encoder
+//
 // Returns:
 //   - *URLEncoder: The new URLEncoder.
-func NewURLEncoder() *URLEncoder {
-	return &URLEncoder{}
+func NewURLEncoder(opts ...Option) *URLEncoder {
+	e := &URLEncoder{
+		maxDepth:   maxRecursionDepth,
+		tagName:    "json",
+		timeFormat: time.RFC3339,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // Encode encodes URL data and supports the following recursive URL syntax:
@@ -35,7 +98,10 @@ func NewURLEncoder() *URLEncoder {
 // someSlice[0]=value
 // someMap.key=value
 //
-// It will return an error if a "json" tag is not found for a struct field.
+// The data can be a map with string keys or a struct (or a pointer to
+// either). Struct fields become top-level keys named by their tags ("json"
+// unless configured with WithTagName). It will return an error if the tag is
+// not found for a struct field.
 //
 // Parameters:
 //   - data: Data to encode
@@ -43,18 +109,39 @@ func NewURLEncoder() *URLEncoder {
 // Returns:
 //   - url.Values: URL values
 //   - error: Error
-func (e URLEncoder) Encode(data map[string]any) (url.Values, error) {
+func (e URLEncoder) Encode(data any) (url.Values, error) {
 	values := url.Values{}
-	for key, value := range data {
-		err := encodeURL(&values, key, reflect.ValueOf(value))
-		if err != nil {
-			return nil, err
-		}
+	if err := e.encodeURL(&values, reflect.ValueOf(data)); err != nil {
+		return nil, err
+	}
+	if err := e.checkQueryLength(values); err != nil {
+		return nil, err
 	}
-
 	return values, nil
 }
 
+// EncodeInto encodes data like Encode but writes the values into the given
+// url.Values, so that callers can reuse one map across calls, e.g. after
+// clearing it with clear(values). Keys written by data replace existing
+// values of the same keys and other keys are kept. On error, values may
+// hold part of the encoded data.
+//
+// Parameters:
+//   - values: URL values to write into
+//   - data: Data to encode
+//
+// Returns:
+//   - error: Error
+func (e URLEncoder) EncodeInto(values url.Values, data any) error {
+	if values == nil {
+		return fmt.Errorf("cannot encode into nil url.Values")
+	}
+	if err := e.encodeURL(&values, reflect.ValueOf(data)); err != nil {
+		return err
+	}
+	return e.checkQueryLength(values)
+}
+
 // Decode decodes URL values and supports the following recursive URL syntax:
 // someKey=value
 // someStruct.field=value
@@ -68,107 +155,364 @@ func (e URLEncoder) Encode(data map[string]any) (url.Values, error) {
 //   - map[string]any: Decoded data
 //   - error: Error
 func (e URLEncoder) Decode(values url.Values) (map[string]any, error) {
-	return decodeURL(values)
+	return e.decode(values, nil)
+}
+
+// decode decodes URL values and uploaded files, splits delimited slice
+// values and infers value types if enabled.
+func (e *URLEncoder) decode(
+	values url.Values, files map[string][]*multipart.FileHeader,
+) (map[string]any, error) {
+	data, err := e.decodeURL(values, files)
+	if err != nil {
+		return nil, err
+	}
+	if sep, ok := e.sliceDelimiter(); ok {
+		e.splitDelimitedValues(data, sep)
+	}
+	if e.inferTypes {
+		inferTypes(data)
+	}
+	return data, nil
 }
 
-// decodeURL decodes an URL.
-func decodeURL(values url.Values) (map[string]any, error) {
+// decodeURL decodes an URL. Uploaded files are placed at their keys after
+// the values.
+func (e *URLEncoder) decodeURL(
+	values url.Values, files map[string][]*multipart.FileHeader,
+) (map[string]any, error) {
+	// The number of keys is checked before any work that grows with it.
+	budget := e.newDecodeBudget()
+	if err := budget.checkKeys(len(values)); err != nil {
+		return nil, err
+	}
+	if e.cleansText() {
+		values = e.cleanValues(values)
+		files = rewriteKeys(files, e.cleanKey)
+	}
+	if e.prefix != "" {
+		values = stripKeyPrefix(values, e.prefix)
+		files = stripKeyPrefix(files, e.prefix)
+	}
+	var err error
+	if values, err = filterKeys(e, values); err != nil {
+		return nil, err
+	}
+	if files, err = filterKeys(e, files); err != nil {
+		return nil, err
+	}
+	if e.qs != nil {
+		return e.decodeQS(values, files)
+	}
 	urlData := make(map[string]any)
 	depth := 0
-	for key, value := range values {
-		var err error
-		depth, err = setNestedMapValue(urlData, key, value[0], depth)
+	errs := errorCollector{collect: e.collectErrors}
+	// Keys are decoded in a stable order so that conflicts are resolved
+	// the same way every time.
+	setValue := func(key string, value string, hint string) error {
+		typed, err := e.decodedValue(value, hint)
 		if err != nil {
+			return fmt.Errorf("invalid value of %q: %w", key, err)
+		}
+		depth, err = e.setNestedMapValue(urlData, key, typed, depth)
+		return err
+	}
+	for _, key := range slices.SortedFunc(maps.Keys(values), compareKeys) {
+		value := values[key]
+		key, hint := e.splitTypeHint(key)
+		key = e.normalizeKey(key)
+		expanded, ok := expandUnindexedKey(key, value)
+		if !ok {
+			expanded, ok = e.expandRepeatedKey(key, value)
+		}
+		if !ok {
+			// Most keys have a single value and are set directly.
+			if err := budget.charge(key, value[0]); err != nil {
+				return nil, err
+			}
+			if err := setValue(key, value[0], hint); errs.add(err) {
+				return nil, err
+			}
+			continue
+		}
+		sorted := slices.SortedFunc(maps.Keys(expanded), compareKeys)
+		for _, key := range sorted {
+			if err := budget.charge(key, expanded[key][0]); err != nil {
+				return nil, err
+			}
+			err := setValue(key, expanded[key][0], hint)
+			if errs.add(err) {
+				return nil, err
+			}
+		}
+	}
+	for _, key := range slices.SortedFunc(maps.Keys(files), compareKeys) {
+		var err error
+		depth, err = e.setNestedMapValue(
+			urlData, e.normalizeKey(key), fileValue(files[key]), depth,
+		)
+		if errs.add(err) {
 			return nil, err
 		}
 	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
 	convertMinSlicesToRegularSlices(urlData)
 	return urlData, nil
 }
 
+// decodedValue converts a value to the type given by its type hint and
+// returns nil for null sentinels. Values longer than the maximum value
+// length are rejected and twice-encoded values are handled as configured.
+func (e *URLEncoder) decodedValue(value string, hint string) (any, error) {
+	if e.maxValueLength > 0 && len(value) > e.maxValueLength {
+		return nil, fmt.Errorf(
+			"%w of %d", ErrMaxValueLength, e.maxValueLength,
+		)
+	}
+	value, err := e.checkDoubleEncoding(value)
+	if err != nil {
+		return nil, err
+	}
+	if hint == "" && e.isNull(value) {
+		return nil, nil
+	}
+	return parseTypeHint(value, hint)
+}
+
+// isNull reports whether a value is the null sentinel, or empty when empty
+// values are null.
+func (e *URLEncoder) isNull(value string) bool {
+	return e.nullSentinel != nil && value == *e.nullSentinel ||
+		e.emptyAsNull && value == ""
+}
+
 // convertMinSlicesToRegularSlices converts all MinSlice instances in the map to
 // regular slices recursively.
 func convertMinSlicesToRegularSlices(data map[string]any) {
 	for key, value := range data {
-		switch v := value.(type) {
-		case *minSlice:
-			data[key] = v.toSlice()
-		case map[string]any:
-			convertMinSlicesToRegularSlices(v)
+		data[key] = convertMinSlices(value)
+	}
+}
+
+// convertMinSlices converts a MinSlice value to a regular slice and
+// recurses into maps and slice elements.
+func convertMinSlices(value any) any {
+	switch v := value.(type) {
+	case *minSlice:
+		slice := v.toSlice()
+		for i, elem := range slice {
+			slice[i] = convertMinSlices(elem)
 		}
+		return slice
+	case map[string]any:
+		convertMinSlicesToRegularSlices(v)
 	}
+	return value
 }
 
-// encodeURL encodes an URL.
-func encodeURL(values *url.Values, fieldTag string, v reflect.Value) error {
-	return encodeValue(values, fieldTag, v)
+// encodeURL encodes the top-level data. Pointers and interfaces are
+// dereferenced until a map or struct is found, unless the data implements
+// URLValuesMarshaler.
+func (e *URLEncoder) encodeURL(values *url.Values, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	// Top-level entries are nested under the prefix, if one is set.
+	root := e.prefix
+	if m, ok := asMarshaler[URLValuesMarshaler](v); ok {
+		return e.encodeValuesMarshaler(values, root, m)
+	}
+	if v.IsValid() && v.Type() == orderedMapType {
+		return e.encodeOrderedMap(values, root, v)
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		return e.encodeMap(values, root, v)
+	case reflect.Struct:
+		return e.encodeStruct(values, root, v)
+	case reflect.Invalid:
+		return nil
+	default:
+		return fmt.Errorf(
+			"top-level data must be a map or struct: %w",
+			ErrUnsupportedType{Type: v.Type()},
+		)
+	}
 }
 
 // encodeValue encodes a value.
-func encodeValue(values *url.Values, fieldTag string, v reflect.Value) error {
+func (e *URLEncoder) encodeValue(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	if ok, err := e.encodeRegistered(values, fieldTag, v); ok {
+		return err
+	}
+	if ok, err := e.encodeKnownType(values, fieldTag, v); ok {
+		return err
+	}
+	if ok, err := e.encodeMarshaler(values, fieldTag, v); ok {
+		return err
+	}
 	switch v.Kind() {
 	case reflect.Ptr, reflect.Interface:
-		return encodePointer(values, fieldTag, v)
+		return e.encodePointer(values, fieldTag, v)
 	case reflect.String:
-		return encodeString(values, fieldTag, v)
+		return e.encodeString(values, fieldTag, v)
 	case reflect.Int, reflect.Int32, reflect.Int64:
-		return encodeInt(values, fieldTag, v)
+		return e.encodeInt(values, fieldTag, v)
 	case reflect.Float32, reflect.Float64:
-		return encodeFloat(values, fieldTag, v)
+		return e.encodeFloat(values, fieldTag, v)
 	case reflect.Bool:
-		return encodeBool(values, fieldTag, v)
-	case reflect.Slice:
-		return encodeSlice(values, fieldTag, v)
+		return e.encodeBool(values, fieldTag, v)
+	case reflect.Slice, reflect.Array:
+		if isBytes(v) {
+			return e.encodeBytes(values, fieldTag, v, e.bytesFormat)
+		}
+		return e.encodeSlice(values, fieldTag, v)
 	case reflect.Map:
-		return encodeMap(values, fieldTag, v)
+		return e.encodeMap(values, fieldTag, v)
 	case reflect.Struct:
-		return encodeStruct(values, fieldTag, v)
+		return e.encodeStruct(values, fieldTag, v)
 	default:
-		return fmt.Errorf(
-			"value type not supported by URL encoding: %s",
-			v.Kind(),
-		)
+		return ErrUnsupportedType{Type: v.Type(), Path: fieldTag}
 	}
 }
 
-// encodePointer encodes a pointer.
-func encodePointer(values *url.Values, fieldTag string, v reflect.Value) error {
+// encodePointer encodes a pointer. Nil pointers are omitted, or written as
+// null values with type hints or a null sentinel.
+func (e *URLEncoder) encodePointer(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
 	if !v.IsNil() {
-		return encodeValue(values, fieldTag, v.Elem())
+		return e.encodeValue(values, fieldTag, v.Elem())
+	}
+	if e.typeHints {
+		return e.setTypedValue(values, fieldTag, "", hintNull)
+	}
+	if e.nullSentinel != nil {
+		return e.setValue(values, fieldTag, *e.nullSentinel)
 	}
 	return nil
 }
 
 // encodeString encodes a string.
-func encodeString(values *url.Values, fieldTag string, v reflect.Value) error {
-	values.Set(fieldTag, v.String())
-	return nil
+func (e *URLEncoder) encodeString(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	return e.setValue(values, fieldTag, v.String())
 }
 
 // encodeInt encodes an int.
-func encodeInt(values *url.Values, fieldTag string, v reflect.Value) error {
-	values.Set(fieldTag, fmt.Sprintf("%d", v.Int()))
-	return nil
+func (e *URLEncoder) encodeInt(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	return e.setTypedValue(
+		values, fieldTag, fmt.Sprintf("%d", v.Int()), hintInt,
+	)
 }
 
 // encodeFloat encodes a float.
-func encodeFloat(values *url.Values, fieldTag string, v reflect.Value) error {
-	values.Set(fieldTag, fmt.Sprintf("%f", v.Float()))
-	return nil
+func (e *URLEncoder) encodeFloat(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	if e.typeHints {
+		// Floats are written exactly so that they decode to equal values.
+		s := strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+		return e.setTypedValue(values, fieldTag, s, hintFloat)
+	}
+	return e.setValue(values, fieldTag, fmt.Sprintf("%f", v.Float()))
 }
 
 // encodeBool encodes a bool.
-func encodeBool(values *url.Values, fieldTag string, v reflect.Value) error {
-	values.Set(fieldTag, strconv.FormatBool(v.Bool()))
+func (e *URLEncoder) encodeBool(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	return e.setTypedValue(
+		values, fieldTag, e.formatBool(v.Bool()), hintBool,
+	)
+}
+
+// setValue sets the string value of a key, applying the value transformer
+// if one is configured.
+func (e *URLEncoder) setValue(
+	values *url.Values, fieldTag string, value string,
+) error {
+	return e.setTypedValue(values, fieldTag, value, hintString)
+}
+
+// setTypedValue sets the value of a key, applying the value transformer if
+// one is configured and appending the type hint to the key if type hints
+// are enabled.
+func (e *URLEncoder) setTypedValue(
+	values *url.Values, fieldTag string, value string, hint string,
+) error {
+	value, err := e.transformValue(fieldTag, value)
+	if err != nil {
+		return err
+	}
+	if e.escapeScalars {
+		value = e.escapeDelimiter(value)
+	}
+	key := e.hintKey(fieldTag, hint)
+	values.Set(key, value)
+	e.order.add(key)
 	return nil
 }
 
-// encodeSlice encodes a slice by encoding each element.
-func encodeSlice(values *url.Values, fieldTag string, v reflect.Value) error {
+// addValue adds a string value to a key, applying the value transformer if
+// one is configured.
+func (e *URLEncoder) addValue(
+	values *url.Values, fieldTag string, value string,
+) error {
+	value, err := e.transformValue(fieldTag, value)
+	if err != nil {
+		return err
+	}
+	if e.escapeScalars {
+		value = e.escapeDelimiter(value)
+	}
+	key := e.hintKey(fieldTag, hintString)
+	values.Add(key, value)
+	e.order.add(key)
+	return nil
+}
+
+// transformValue applies the value transformer if one is configured.
+func (e *URLEncoder) transformValue(
+	fieldTag string, value string,
+) (string, error) {
+	if e.valueTransformer == nil {
+		return value, nil
+	}
+	value, err := e.valueTransformer(fieldTag, value)
+	if err != nil {
+		return "", fmt.Errorf("cannot transform value of %q: %w", fieldTag, err)
+	}
+	return value, nil
+}
+
+// encodeSlice encodes a slice or an array by encoding each element.
+func (e *URLEncoder) encodeSlice(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	switch e.sliceStyle {
+	case UnindexedSlices:
+		return e.encodeUnindexedSlice(values, fieldTag, v, fieldTag+"[]")
+	case RepeatedKeySlices:
+		return e.encodeUnindexedSlice(values, fieldTag, v, fieldTag)
+	}
+	if sep, ok := e.sliceDelimiter(); ok {
+		return e.encodeDelimitedSlice(values, fieldTag, v, sep)
+	}
 	for j := 0; j < v.Len(); j++ {
 		sliceElem := v.Index(j)
-		newFieldTag := fmt.Sprintf("%s[%d]", fieldTag, j)
-		if err := encodeValue(values, newFieldTag, sliceElem); err != nil {
+		newFieldTag := indexKey(fieldTag, j)
+		if err := e.encodeValue(values, newFieldTag, sliceElem); err != nil {
 			return err
 		}
 	}
@@ -176,32 +520,83 @@ func encodeSlice(values *url.Values, fieldTag string, v reflect.Value) error {
 }
 
 // encodeMap encodes a map.
-func encodeMap(values *url.Values, fieldTag string, v reflect.Value) error {
-	// Only support maps with string keys.
-	if v.Type().Key().Kind() != reflect.String {
+func (e *URLEncoder) encodeMap(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	if !canEncodeMapKey(v.Type().Key()) {
 		return fmt.Errorf(
-			"map keys must be strings, got %s", v.Type().Key().Kind(),
+			"map keys must be strings or integers or implement "+
+				"encoding.TextMarshaler or fmt.Stringer: %w",
+			ErrUnsupportedType{Type: v.Type(), Path: fieldTag},
 		)
 	}
+	keys := make([]mapKey, 0, v.Len())
 	for _, key := range v.MapKeys() {
-		keyStr := key.String()
-		newFieldTag := keyStr
-		if fieldTag != "" {
-			newFieldTag = fieldTag + "." + keyStr
+		name, err := encodeMapKey(key)
+		if err != nil {
+			return fmt.Errorf("cannot encode key of %q: %w", fieldTag, err)
 		}
-		if err := encodeValue(
-			values, newFieldTag, v.MapIndex(key),
+		keys = append(keys, mapKey{value: key, name: name})
+	}
+	parallel := e.isParallel(fieldTag, len(keys))
+	if e.sortMapKeys || parallel {
+		slices.SortFunc(keys, func(a, b mapKey) int {
+			return compareKeys(a.name, b.name)
+		})
+	}
+	if parallel {
+		return e.encodeMapParallel(values, fieldTag, v, keys)
+	}
+	return e.encodeMapEntries(values, fieldTag, v, keys)
+}
+
+// mapKey is a key of a map with its encoded name.
+type mapKey struct {
+	value reflect.Value // Key of the map
+	name  string        // Encoded name of the key
+}
+
+// encodeMapEntries encodes the entries of a map in the order of the keys.
+func (e *URLEncoder) encodeMapEntries(
+	values *url.Values, fieldTag string, v reflect.Value, keys []mapKey,
+) error {
+	for _, key := range keys {
+		if err := e.encodeMapEntry(
+			values, fieldTag, v.MapIndex(key.value), key.name,
 		); err != nil {
 			return err
 		}
+		if err := e.flushTopLevel(values, fieldTag); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// encodeMapEntry encodes the value of a map entry under its key name.
+func (e *URLEncoder) encodeMapEntry(
+	values *url.Values, fieldTag string, value reflect.Value, name string,
+) error {
+	if e.omitZero && isZeroValue(value) {
+		return nil
+	}
+	newFieldTag, err := e.mapChildKey(fieldTag, name)
+	if err != nil {
+		return err
+	}
+	return e.encodeValue(values, newFieldTag, value)
+}
+
 // encodeStruct encodes a struct.
-func encodeStruct(values *url.Values, fieldTag string, v reflect.Value) error {
-	for i := 0; i < v.NumField(); i++ {
-		if err := encodeStructField(values, fieldTag, v, i); err != nil {
+func (e *URLEncoder) encodeStruct(
+	values *url.Values, fieldTag string, v reflect.Value,
+) error {
+	for _, plan := range e.structFields(v.Type()) {
+		err := e.encodeStructField(values, fieldTag, v, plan)
+		if err != nil {
+			return err
+		}
+		if err := e.flushTopLevel(values, fieldTag); err != nil {
 			return err
 		}
 	}
@@ -209,121 +604,328 @@ func encodeStruct(values *url.Values, fieldTag string, v reflect.Value) error {
 }
 
 // encodeStructField encodes a struct field.
-func encodeStructField(
-	values *url.Values, fieldTag string, v reflect.Value, i int,
+func (e *URLEncoder) encodeStructField(
+	values *url.Values, fieldTag string, v reflect.Value, plan fieldPlan,
 ) error {
-	field := v.Field(i)
-	fieldType := v.Type().Field(i)
+	field := v.Field(plan.index)
+	fieldType, spec, ok := plan.field, plan.spec, plan.ok
+	if spec.skip {
+		return nil
+	}
 
 	if fieldType.Anonymous {
-		if err := encodeValue(values, fieldTag, field); err != nil {
+		if err := e.encodeValue(values, fieldTag, field); err != nil {
 			return err
 		}
 		return nil
 	}
 
-	newFieldTag := fieldType.Tag.Get("json")
-	if newFieldTag == "-" || newFieldTag == "" {
+	if !ok {
 		return fmt.Errorf(
-			"cannot encode field %q because it has no json tag", fieldType.Name,
+			"cannot encode field %q because it has no %s tag",
+			e.joinKey(fieldTag, fieldType.Name), e.tagName,
 		)
 	}
+	if spec.omitEmpty && isEmptyValue(field) ||
+		e.omitZero && isZeroValue(field) {
+		return nil
+	}
 
-	if fieldTag != "" {
-		newFieldTag = fieldTag + "." + newFieldTag
+	newFieldTag := e.childKey(fieldTag, spec.name)
+	if isBytes(field) {
+		// Registered encoders and marshalers take precedence over the
+		// bytes format of the tag, like they do in encodeValue.
+		if ok, err := e.encodeRegistered(values, newFieldTag, field); ok {
+			return err
+		}
+		if ok, err := e.encodeMarshaler(values, newFieldTag, field); ok {
+			return err
+		}
+		return e.encodeBytes(values, newFieldTag, field, spec.bytesFormat)
 	}
-	if err := encodeValue(values, newFieldTag, field); err != nil {
+	fe := e.withTimeEpoch(spec.timeEpoch)
+	if err := fe.encodeValue(values, newFieldTag, field); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// childKey returns the key of the named child of the value at fieldTag,
+// applying the key transformer if one is configured.
+func (e *URLEncoder) childKey(fieldTag string, name string) string {
+	return e.joinKey(fieldTag, e.transformKey(fieldTag, name))
+}
+
+// mapChildKey returns the key of a map entry. It applies the key
+// transformer and handles delimiters in the map key as configured with
+// WithDelimiterKeys.
+func (e *URLEncoder) mapChildKey(
+	fieldTag string, name string,
+) (string, error) {
+	name = e.transformKey(fieldTag, name)
+	escaped, err := e.escapeMapKey(name)
+	if err != nil {
+		return "", fmt.Errorf(
+			"cannot encode %q: %w", e.joinKey(fieldTag, name), err,
+		)
+	}
+	return e.joinKey(fieldTag, escaped), nil
+}
+
+// transformKey applies the key transformer to a name if one is configured.
+func (e *URLEncoder) transformKey(fieldTag string, name string) string {
+	if e.keyTransformer == nil {
+		return name
+	}
+	return e.keyTransformer(keySegments(fieldTag), name)
+}
+
+// keySegments splits an encoded key into its segments, e.g. "a.b[0].c" or
+// "a[b][0][c]" gives "a", "b", "0" and "c".
+func keySegments(key string) []string {
+	if key == "" {
+		return nil
+	}
+	var segments []string
+	for _, part := range strings.Split(key, ".") {
+		name, rest, found := strings.Cut(part, "[")
+		segments = append(segments, name)
+		for found {
+			var index string
+			index, rest, _ = strings.Cut(rest, "]")
+			segments = append(segments, index)
+			_, rest, found = strings.Cut(rest, "[")
+		}
+	}
+	return segments
+}
+
+// fieldSpec describes a struct field as parsed from its tag.
+type fieldSpec struct {
+	name         string      // Key name of the field
+	skip         bool        // Field is tagged "-" and must be ignored
+	omitEmpty    bool        // Field is omitted from encoding when empty
+	bytesFormat  BytesFormat // Format used if the field holds bytes
+	timeEpoch    TimeEpoch   // Epoch used for times held by the field
+	required     bool        // Field must be present when decoding
+	defaultValue *string     // Value decoded when the field is missing
+}
+
+// parseField parses the configured tag of a struct field. The tag has the
+// form "name,opt1,opt2". A tag of "-" skips the field and an empty name
+// defaults to the Go field name. The "hex" and "base64" options override
+// the encoder's bytes format, "unix" and "unixmilli" its time epoch,
+// "required" requires the field when decoding and "default:value" sets the
+// value decoded when the field is missing. It returns false if the field
+// has no tag, unless untagged fields are allowed, in which case the Go field
+// name is used.
+func (e *URLEncoder) parseField(
+	fieldType reflect.StructField,
+) (fieldSpec, bool) {
+	tag, ok := fieldType.Tag.Lookup(e.tagName)
+	if !ok || tag == "" {
+		if e.allowUntagged {
+			return fieldSpec{
+				name:        fieldType.Name,
+				bytesFormat: e.bytesFormat,
+				timeEpoch:   e.timeEpoch,
+			}, true
+		}
+		return fieldSpec{}, false
+	}
+	if tag == "-" {
+		return fieldSpec{skip: true}, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = fieldType.Name
+	}
+	spec := fieldSpec{
+		name: name, bytesFormat: e.bytesFormat, timeEpoch: e.timeEpoch,
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "omitempty":
+			spec.omitEmpty = true
+		case "hex":
+			spec.bytesFormat = BytesHex
+		case "base64":
+			spec.bytesFormat = BytesBase64
+		case "unix":
+			spec.timeEpoch = EpochSeconds
+		case "unixmilli":
+			spec.timeEpoch = EpochMillis
+		case "required":
+			spec.required = true
+		default:
+			if value, ok := strings.CutPrefix(opt, "default:"); ok {
+				spec.defaultValue = &value
+			}
+		}
+	}
+	return spec, true
+}
+
+// isEmptyValue reports whether a value is empty in the sense of the
+// "omitempty" tag option: false, 0, a nil pointer or interface, or an empty
+// string, slice, map or array.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// isZeroValue reports whether a value is omitted with WithOmitZero: an
+// empty value as for "omitempty", a non-nil interface holding one, or a
+// value whose IsZero method reports true, such as a zero time.Time.
+func isZeroValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return isZeroValue(v.Elem())
+	}
+	if z, ok := asMarshaler[interface{ IsZero() bool }](v); ok {
+		return z.IsZero()
+	}
+	return isEmptyValue(v)
+}
+
 // setNestedMapValue sets the value of a nested map.
-func setNestedMapValue(
+func (e *URLEncoder) setNestedMapValue(
 	current map[string]any, key string, value any, depth int,
 ) (int, error) {
 	// Handle empty key explicitly.
 	if key == "" {
 		if _, exists := current[""]; exists {
-			return depth, fmt.Errorf("conflicting key: empty key already set")
+			return depth, ErrConflictingKey{}
 		}
 		current[""] = value
 		return depth, nil
 	}
 
-	parts := strings.Split(key, ".")
-	if len(parts) > maxRecursionDepth {
+	if e.maxDepth > 0 && strings.Count(key, ".") >= e.maxDepth {
 		return depth, fmt.Errorf(
-			"exceeded maximum recursion depth of %d", maxRecursionDepth,
+			"%w of %d at %q", ErrMaxDepthExceeded, e.maxDepth, key,
 		)
 	}
 
-	for i, part := range parts {
+	// The parts are walked in a single pass without splitting the key.
+	rest := key
+	for {
 		// Increase depth per level.
 		depth++
-		if i == len(parts)-1 {
-			return depth, setFinalValue(current, part, value)
+		part, after, found := strings.Cut(rest, ".")
+		if !found {
+			err := e.setFinalValue(current, part, value)
+			return depth, withKey(err, key)
 		}
 		var err error
-		current, err = getIntermediateValue(current, part)
+		current, err = e.getIntermediateValue(current, part)
 		if err != nil {
-			return depth, err
+			return depth, withKey(err, key)
+		}
+		if current == nil {
+			// The key was dropped by the conflict strategy.
+			return depth, nil
 		}
+		rest = after
 	}
-	return depth, nil
 }
 
 // setFinalValue sets the value of the final key.
-func setFinalValue(current map[string]any, part string, value any) error {
-	reg := regexp.MustCompile(sliceRegexp)
-	// If part appears to be a slice but doesn't match valid format, error.
-	if strings.Contains(part, "[") && strings.Contains(part, "]") {
-		if sliceIndex := reg.FindStringSubmatch(part); sliceIndex == nil {
-			return fmt.Errorf("invalid slice index: %q", part)
-		}
+func (e *URLEncoder) setFinalValue(
+	current map[string]any, part string, value any,
+) error {
+	name, indices, ok, err := parseIndexedPart(part)
+	if err != nil {
+		return err
 	}
-	if sliceIndex := reg.FindStringSubmatch(part); sliceIndex != nil {
-		return setSliceValue(current, sliceIndex, value)
+	if ok {
+		return e.setSliceValue(current, name, indices, value)
 	}
-	if _, exists := current[part]; exists {
-		return fmt.Errorf("conflicting key: %q already set", part)
+	part = e.unescapeMapKey(part)
+	if existing, exists := current[part]; exists {
+		merged, err := e.mergeConflict(existing, value)
+		if err != nil {
+			return err
+		}
+		current[part] = merged
+		return nil
 	}
 	current[part] = value
 	return nil
 }
 
-// setSliceValue sets the value of a slice element.
-func setSliceValue(
-	current map[string]any, sliceIndex []string, value any,
+// setSliceValue sets the value of a slice element. More than one index
+// addresses an element of nested slices.
+func (e *URLEncoder) setSliceValue(
+	current map[string]any, sliceName string, indices []int, value any,
 ) error {
-	sliceName, idx, err := parseSliceIndex(sliceIndex)
-	if err != nil {
+	sliceName = e.unescapeMapKey(sliceName)
+	if obj, ok := e.objectForSlice(current, sliceName); ok {
+		return e.setFinalValue(obj, indexedPart(indices), value)
+	}
+	slice, err := e.getOrCreateSlice(current, sliceName)
+	if err != nil || slice == nil {
 		return err
 	}
-	slice, err := getOrCreateSlice(current, sliceName)
-	if err != nil {
+	last := len(indices) - 1
+	for _, idx := range indices[:last] {
+		slice, err = e.getOrCreateElementSlice(slice, idx)
+		if err != nil || slice == nil {
+			return err
+		}
+	}
+	if existing, exists := slice.get(indices[last]); exists {
+		value, err = e.mergeConflict(existing, value)
+		if err != nil {
+			return err
+		}
+	} else if err := checkSliceSize(slice); err != nil {
 		return err
 	}
-	slice.set(idx, value)
-	current[sliceName] = slice // Use MinSlice to handle slice elements safely
+	slice.set(indices[last], value)
 	return nil
 }
 
-// getIntermediateValue gets the intermediate value of a nested key. It uses
-// regexp to check if the key is a slice index.
-func getIntermediateValue(
+// getIntermediateValue gets the intermediate value of a nested key. It
+// returns a nil map if the key is dropped by the conflict strategy.
+func (e *URLEncoder) getIntermediateValue(
 	current map[string]any, part string,
 ) (map[string]any, error) {
-	reg := regexp.MustCompile(sliceRegexp)
-	if sliceIndex := reg.FindStringSubmatch(part); sliceIndex != nil {
-		return createMapIntoSlice(sliceIndex, current)
+	name, indices, ok, err := parseIndexedPart(part)
+	if err != nil {
+		return nil, err
 	}
+	if ok {
+		return e.createMapIntoSlice(current, name, indices)
+	}
+	part = e.unescapeMapKey(part)
 	// Create a map with the part name if it doesn't exist
 	if _, ok := current[part]; !ok {
 		current[part] = make(map[string]any)
 	}
-	return getMap(current, part)
+	m, err := getMap(current, part)
+	if err != nil {
+		m, err = e.resolveObject(current[part], err)
+		if m != nil {
+			current[part] = m
+		}
+	}
+	return m, err
 }
 
 // getMap returns a map from the current map.
@@ -334,79 +936,165 @@ func getMap(current map[string]any, part string) (map[string]any, error) {
 	}
 	cast, ok := retMap.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("expected map[string]any, got %T", retMap)
+		return nil, ErrConflictingKey{Key: part}
 	}
 	return cast, nil
 }
 
-// createMapIntoSlice creates a map inside a slice and returns it.
-func createMapIntoSlice(
-	sliceIndex []string, current map[string]any,
+// createMapIntoSlice creates a map inside a slice and returns it. More than
+// one index addresses an element of nested slices.
+func (e *URLEncoder) createMapIntoSlice(
+	current map[string]any, sliceName string, indices []int,
 ) (map[string]any, error) {
-	sliceName, idx, err := parseSliceIndex(sliceIndex)
-	if err != nil {
-		return nil, err
+	sliceName = e.unescapeMapKey(sliceName)
+	if obj, ok := e.objectForSlice(current, sliceName); ok {
+		return e.getIntermediateValue(obj, indexedPart(indices))
 	}
-	slice, err := getOrCreateSlice(current, sliceName)
-	if err != nil {
+	slice, err := e.getOrCreateSlice(current, sliceName)
+	if err != nil || slice == nil {
 		return nil, err
 	}
+	last := len(indices) - 1
+	for _, idx := range indices[:last] {
+		slice, err = e.getOrCreateElementSlice(slice, idx)
+		if err != nil || slice == nil {
+			return nil, err
+		}
+	}
+	idx := indices[last]
 	// Ensure the element at idx is a map and initialize if necessary
 	elem, exists := slice.get(idx)
 	if !exists {
+		if err := checkSliceSize(slice); err != nil {
+			return nil, err
+		}
 		elem = make(map[string]any)
 		slice.set(idx, elem)
 	}
 	// Ensure elem is a map
 	castedElem, ok := elem.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("expected map[string]any, got %T", elem)
+		castedElem, err = e.resolveObject(
+			elem, ErrConflictingKey{Key: sliceName},
+		)
+		if castedElem == nil {
+			return nil, err
+		}
+		slice.set(idx, castedElem)
 	}
-	current[sliceName] = slice
 	return castedElem, nil
 }
 
-// parseSliceIndex returns the slice name and index from a slice index string.
-func parseSliceIndex(sliceIndex []string) (string, int, error) {
-	if len(sliceIndex) != 3 {
-		return "", 0, fmt.Errorf("invalid slice index: %v", sliceIndex)
+// parseIndexedPart splits a key part such as "list[0]" or "grid[1][2]" into
+// its name and indices. It returns false if the part has no indices. A part
+// with both "[" and "]" that is not a name followed by decimal indices
+// returns an ErrInvalidIndex, while a part with only one of them is a plain
+// name.
+func parseIndexedPart(part string) (string, []int, bool, error) {
+	open := strings.IndexByte(part, '[')
+	if open < 0 || strings.IndexByte(part, ']') < 0 {
+		return "", nil, false, nil
 	}
-	// For example, "mySlice[0]" gives sliceName "mySlice" and index "0".
-	sliceName, index := sliceIndex[1], sliceIndex[2]
-	idx, err := strconv.Atoi(index)
-	if err != nil {
-		return "", 0, fmt.Errorf("invalid index: %s", index)
+	name, rest := part[:open], part[open:]
+	if name == "" || strings.IndexByte(name, ']') >= 0 {
+		return "", nil, false, ErrInvalidIndex{Key: part}
 	}
-	if idx < 0 {
-		return "", 0, fmt.Errorf("invalid negative index: %d", idx)
+	var indices []int
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 || !isIndex(rest[1:end]) {
+			return "", nil, false, ErrInvalidIndex{Key: part}
+		}
+		idx, err := strconv.Atoi(rest[1:end])
+		if err != nil {
+			return "", nil, false, ErrInvalidIndex{Key: part}
+		}
+		indices = append(indices, idx)
+		rest = rest[end+1:]
 	}
-	return sliceName, idx, nil
+	return name, indices, true, nil
+}
+
+// indexedPart formats indices as a key part whose name is the first index,
+// e.g. 0, 1 gives "0[1]", to address them in an object keyed by index.
+func indexedPart(indices []int) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(indices[0]))
+	for _, idx := range indices[1:] {
+		b.WriteString("[" + strconv.Itoa(idx) + "]")
+	}
+	return b.String()
 }
 
 // getOrCreateSlice returns a slice or creates a new one if it doesn't exist.
-func getOrCreateSlice(
+// It returns a nil slice if the key is dropped by the conflict strategy.
+func (e *URLEncoder) getOrCreateSlice(
 	current map[string]any,
 	sliceName string,
 ) (*minSlice, error) {
-	if _, ok := current[sliceName]; !ok {
-		current[sliceName] = newMinSlice()
+	existing, exists := current[sliceName]
+	slice, err := e.asSlice(existing, exists, sliceName)
+	if slice != nil {
+		current[sliceName] = slice
+	}
+	return slice, err
+}
+
+// getOrCreateElementSlice returns the slice at an index of a slice or
+// creates a new one if the element doesn't exist. It returns a nil slice if
+// the element is dropped by the conflict strategy.
+func (e *URLEncoder) getOrCreateElementSlice(
+	slice *minSlice, idx int,
+) (*minSlice, error) {
+	existing, exists := slice.get(idx)
+	if !exists {
+		if err := checkSliceSize(slice); err != nil {
+			return nil, err
+		}
 	}
-	minSlice, ok := current[sliceName].(*minSlice)
+	elem, err := e.asSlice(existing, exists, strconv.Itoa(idx))
+	if elem != nil {
+		slice.set(idx, elem)
+	}
+	return elem, err
+}
+
+// asSlice returns an existing value as a slice, or a new slice if the value
+// doesn't exist. Other values are resolved by the conflict strategy: a nil
+// slice drops the new key.
+func (e *URLEncoder) asSlice(
+	existing any, exists bool, name string,
+) (*minSlice, error) {
+	if !exists {
+		return newMinSlice(), nil
+	}
+	slice, ok := existing.(*minSlice)
 	if !ok {
-		return nil, fmt.Errorf("expected *minSlice, got %T", current[sliceName])
+		switch e.conflicts {
+		case FirstWins:
+			return nil, nil
+		case LastWins:
+			slice = newMinSlice()
+		default:
+			return nil, ErrConflictingKey{Key: name}
+		}
 	}
-	if len(minSlice.elements) >= maxSliceSize {
-		return nil, fmt.Errorf(
-			"exceeded maximum slice size of %d",
-			maxSliceSize,
-		)
+	return slice, nil
+}
+
+// checkSliceSize returns an ErrMaxSliceSize if a new element can't be added
+// to a slice. Existing elements can always be replaced.
+func checkSliceSize(slice *minSlice) error {
+	if len(slice.elements) >= maxSliceSize {
+		return fmt.Errorf("%w of %d", ErrMaxSliceSize, maxSliceSize)
 	}
-	return minSlice, nil
+	return nil
 }
 
 // minSlice keeps track of slice elements with minimal length
 type minSlice struct {
 	elements map[int]any
+	length   int // Index following the highest index set
 }
 
 // newMinSlice returns a new MinSlice
@@ -417,6 +1105,7 @@ func newMinSlice() *minSlice {
 // set sets the value at the given index
 func (s *minSlice) set(index int, value any) {
 	s.elements[index] = value
+	s.length = max(s.length, index+1)
 }
 
 // get returns the value at the given index
@@ -425,11 +1114,21 @@ func (s *minSlice) get(index int) (any, bool) {
 	return value, exists
 }
 
-// toSlice converts the MinSlice to a regular slice
+// next returns the index following the highest index set
+func (s *minSlice) next() int {
+	return s.length
+}
+
+// toSlice converts the MinSlice to a regular slice in index order
 func (s *minSlice) toSlice() []any {
+	indices := make([]int, 0, len(s.elements))
+	for index := range s.elements {
+		indices = append(indices, index)
+	}
+	slices.Sort(indices)
 	slice := make([]any, 0, len(s.elements))
-	for _, value := range s.elements {
-		slice = append(slice, value)
+	for _, index := range indices {
+		slice = append(slice, s.elements[index])
 	}
 	return slice
 }
@@ -1,6 +1,7 @@
 package urlcodec
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -101,6 +102,25 @@ func TestEncode_Slice(t *testing.T) {
 	}
 }
 
+// TestEncode_Array verifies that a fixed-size array is encoded into indexed
+// keys like a slice.
+func TestEncode_Array(t *testing.T) {
+	encoder := NewURLEncoder()
+	input := map[string]any{
+		"point": [2]float64{1.5, 2},
+	}
+	values, err := encoder.Encode(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := values.Get("point[0]"); got != "1.500000" {
+		t.Errorf("expected point[0]=1.500000, got %q", got)
+	}
+	if got := values.Get("point[1]"); got != "2.000000" {
+		t.Errorf("expected point[1]=2.000000, got %q", got)
+	}
+}
+
 // TestEncode_Map verifies that a map is encoded with keys joined by dots.
 func TestEncode_Map(t *testing.T) {
 	encoder := NewURLEncoder()
@@ -138,18 +158,44 @@ func TestEncode_MissingJSONTag(t *testing.T) {
 	}
 }
 
-// TestEncode_MapNonStringKey verifies that a map with non-string keys is
-// rejected.
+// TestEncode_TagOptions verifies that tag options are parsed: omitempty skips
+// zero values, "-" skips the field, and an empty name uses the field name.
+func TestEncode_TagOptions(t *testing.T) {
+	type Tagged struct {
+		Name     string `json:"name,omitempty"`
+		Empty    string `json:"empty,omitempty"`
+		Zero     int    `json:"zero,omitempty"`
+		Skipped  string `json:"-"`
+		Untitled string `json:",omitempty"`
+	}
+	encoder := NewURLEncoder()
+	values, err := encoder.Encode(Tagged{
+		Name: "Ada", Skipped: "secret", Untitled: "x",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"name":     {"Ada"},
+		"Untitled": {"x"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+// TestEncode_MapNonStringKey verifies that a map with keys that are neither
+// strings, integers nor text is rejected.
 func TestEncode_MapNonStringKey(t *testing.T) {
 	encoder := NewURLEncoder()
 	input := map[string]any{
-		"badMap": map[int]string{
+		"badMap": map[float64]string{
 			1: "one",
 		},
 	}
 	_, err := encoder.Encode(input)
 	if err == nil {
-		t.Fatal("expected error for map with non-string keys, got nil")
+		t.Fatal("expected error for map with float keys, got nil")
 	}
 }
 
@@ -284,6 +330,80 @@ func TestEncode_NestedStructures(t *testing.T) {
 	}
 }
 
+// TestEncode_TopLevelStruct verifies that a struct can be passed to Encode
+// directly and its fields become top-level keys.
+func TestEncode_TopLevelStruct(t *testing.T) {
+	type Filter struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	type Request struct {
+		Query  string `json:"q"`
+		Filter Filter `json:"filter"`
+	}
+	encoder := NewURLEncoder()
+	req := Request{
+		Query:  "go",
+		Filter: Filter{Name: "Ada", Tags: []string{"x"}},
+	}
+	for _, input := range []any{req, &req} {
+		values, err := encoder.Encode(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := values.Get("q"); got != "go" {
+			t.Errorf("expected q=go, got %q", got)
+		}
+		if got := values.Get("filter.name"); got != "Ada" {
+			t.Errorf("expected filter.name=Ada, got %q", got)
+		}
+		if got := values.Get("filter.tags[0]"); got != "x" {
+			t.Errorf("expected filter.tags[0]=x, got %q", got)
+		}
+	}
+}
+
+// TestEncode_TopLevelScalar verifies that non-map, non-struct top-level
+// data is rejected.
+func TestEncode_TopLevelScalar(t *testing.T) {
+	encoder := NewURLEncoder()
+	if _, err := encoder.Encode("scalar"); err == nil {
+		t.Fatal("expected error for scalar top-level data, got nil")
+	}
+}
+
+// TestEncodeInto verifies that EncodeInto writes into reused URL values,
+// replacing keys written by the data and keeping other keys.
+func TestEncodeInto(t *testing.T) {
+	encoder := NewURLEncoder(WithSliceStyle(UnindexedSlices))
+	values := url.Values{"keep": {"1"}, "name": {"old"}}
+	data := map[string]any{"name": "Ada", "tags": []string{"a", "b"}}
+	if err := encoder.EncodeInto(values, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := url.Values{
+		"keep":   {"1"},
+		"name":   {"Ada"},
+		"tags[]": {"a", "b"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	clear(values)
+	if err := encoder.EncodeInto(values, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delete(expected, "keep")
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	if err := encoder.EncodeInto(nil, data); err == nil {
+		t.Fatal("expected error for nil values, got nil")
+	}
+}
+
 // TestEncodeDecode_Cycle encodes a complex structure then decodes it back,
 // verifying that the original structure is preserved.
 func TestEncodeDecode_Cycle(t *testing.T) {
@@ -499,6 +619,59 @@ func TestDecode_InvalidSliceIndex(t *testing.T) {
 	}
 }
 
+// TestDecode_MultiDimensionalSlice verifies that keys with several indices
+// decode into nested slices, including objects inside them, and that
+// [][]T values round-trip.
+func TestDecode_MultiDimensionalSlice(t *testing.T) {
+	encoder := NewURLEncoder()
+	values := url.Values{
+		"grid[0][0]":     {"a"},
+		"grid[0][1]":     {"b"},
+		"grid[1][0]":     {"c"},
+		"cells[0][1].id": {"x"},
+		"my-list[0]":     {"y"},
+	}
+	got, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"grid":    []any{[]any{"a", "b"}, []any{"c"}},
+		"cells":   []any{[]any{map[string]any{"id": "x"}}},
+		"my-list": []any{"y"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	type Board struct {
+		Grid [][]string `json:"grid"`
+	}
+	board := Board{Grid: [][]string{{"a", "b"}, {"c"}}}
+	encoded, err := encoder.Encode(board)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Board
+	if err := encoder.DecodeInto(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, board) {
+		t.Errorf("expected %v, got %v", board, decoded)
+	}
+
+	for _, key := range []string{
+		"grid[0]x", "grid[0][a]", "grid[]]", "[0]", "grid[0][-1]",
+		"grid[0][0]]", "a]b[0]",
+	} {
+		values := url.Values{key: {"v"}}
+		var invalid ErrInvalidIndex
+		if _, err := encoder.Decode(values); !errors.As(err, &invalid) {
+			t.Errorf("expected ErrInvalidIndex for %q, got %v", key, err)
+		}
+	}
+}
+
 // TestDecode_ConflictingKeys tests that a conflict between a simple key and a
 // nested key (e.g. "person" as a string and "person.name") causes an error.
 func TestDecode_ConflictingKeys(t *testing.T) {
@@ -703,3 +876,27 @@ func TestDecode_ExceedMaxSliceSize(t *testing.T) {
 		t.Fatal("expected error due to exceeding max slice size, got nil")
 	}
 }
+
+// TestDecode_FullSliceNestedFields checks that the fields of the elements of
+// a slice at its maximum size can still be set.
+func TestDecode_FullSliceNestedFields(t *testing.T) {
+	encoder := NewURLEncoder()
+	values := url.Values{}
+	for i := range maxSliceSize {
+		prefix := "users[" + strconv.Itoa(i) + "]."
+		values.Set(prefix+"name", "n")
+		values.Set(prefix+"role", "r")
+	}
+	data, err := encoder.Decode(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users := data["users"].([]any)
+	if len(users) != maxSliceSize {
+		t.Fatalf("expected %d users, got %d", maxSliceSize, len(users))
+	}
+	last := users[maxSliceSize-1].(map[string]any)
+	if last["name"] != "n" || last["role"] != "r" {
+		t.Errorf("unexpected last user: %v", last)
+	}
+}
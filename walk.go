@@ -0,0 +1,73 @@
+package urlcodec
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SkipChildren can be returned by a WalkFunc called for an object or slice
+// to skip its children.
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc is called by Walk for each value with its path, e.g.
+// "user.emails[1]". Returning an error stops the walk and Walk returns the
+// error, except for SkipChildren.
+type WalkFunc func(path string, value any) error
+
+// Walk calls fn for every value in decoded data in a defined order: object
+// keys in the numerically-aware order of EncodeToString, OrderedMap keys in
+// insertion order and slice elements by index. Objects and slices are
+// visited before their children. The function may replace the value at the
+// current path with SetPath, e.g. to redact it, since keys are collected
+// before an object's children are visited.
+//
+// Parameters:
+//   - data: Decoded data
+//   - fn: Function called for each value
+//
+// Returns:
+//   - error: Error returned by fn
+func Walk(data map[string]any, fn WalkFunc) error {
+	for _, key := range slices.SortedFunc(maps.Keys(data), compareKeys) {
+		if err := walkValue(key, data[key], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkValue calls fn for a value and then for its children.
+func walkValue(path string, value any, fn WalkFunc) error {
+	err := fn(path, value)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range slices.SortedFunc(maps.Keys(v), compareKeys) {
+			if err := walkValue(path+"."+key, v[key], fn); err != nil {
+				return err
+			}
+		}
+	case *OrderedMap:
+		for _, key := range v.Keys() {
+			child, _ := v.Get(key)
+			if err := walkValue(path+"."+key, child, fn); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, elem := range v {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if err := walkValue(elemPath, elem, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
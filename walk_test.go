@@ -0,0 +1,53 @@
+package urlcodec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWalk verifies that Walk visits values in a defined order, skips
+// children on request and lets the function redact values.
+func TestWalk(t *testing.T) {
+	data, err := NewURLEncoder().DecodeString(
+		"user.password=x&user.name=Ada&tags[1]=b&tags[0]=a&meta.k=v&id=1",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var paths []string
+	err = Walk(data, func(path string, value any) error {
+		paths = append(paths, path)
+		if path == "meta" {
+			return SkipChildren
+		}
+		if strings.HasSuffix(path, ".password") {
+			return SetPath(data, path, "***")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"id", "meta", "tags", "tags[0]", "tags[1]",
+		"user", "user.name", "user.password",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+	if got, _ := GetPath(data, "user.password"); got != "***" {
+		t.Errorf("expected redacted password, got %v", got)
+	}
+
+	stop := errors.New("stop")
+	paths = nil
+	err = Walk(data, func(path string, value any) error {
+		paths = append(paths, path)
+		return stop
+	})
+	if !errors.Is(err, stop) || len(paths) != 1 {
+		t.Errorf("expected walk to stop at the first value, got %v", err)
+	}
+}